@@ -0,0 +1,270 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/OGKevin/go-wavy/internal/poll"
+	"github.com/OGKevin/go-wavy/wavy"
+	"github.com/OGKevin/go-wavy/wavy/listenbrainz"
+	"github.com/OGKevin/go-wavy/wavy/store"
+	"github.com/hashicorp/go-hclog"
+)
+
+const (
+	defaultMaxQueue   = 5000
+	defaultMinBackoff = 5 * time.Second
+	defaultMaxBackoff = 10 * time.Minute
+)
+
+// bridge tails the wavy history of its users and submits new listens to ListenBrainz.
+//
+// Listens which were submitted successfully are appended to the store, whose checkpoint marks
+// where the next poll continues, users without checkpoint start with the listens after start.
+// Listens which failed to submit stay in a per user retry queue,
+// so they are not lost when they scroll out of the recent history before ListenBrainz is reachable again.
+type bridge struct {
+	wavy   wavy.Client
+	lb     listenbrainz.Client
+	store  store.HistoryStore
+	users  []wavy.UserURI
+	logger hclog.Logger
+
+	maxQueue   int
+	minBackoff time.Duration
+	maxBackoff time.Duration
+	start      time.Time
+	now        func() time.Time
+
+	queues     map[string]*retryQueue
+	playingNow map[string]string
+	// submitted holds the newest listen submitted per user, in case storing the checkpoint failed.
+	submitted map[string]cursor
+}
+
+// cursor marks a listen by its date and PlayID. Listens are ordered by date and then PlayID, as the store orders
+// them, so listens sharing the date of the last submitted listen are not skipped.
+type cursor struct {
+	date   time.Time
+	playID string
+}
+
+func cursorOf(item wavy.Item) cursor {
+	return cursor{date: item.Date, playID: item.PlayID}
+}
+
+// after reports whether c comes after o.
+func (c cursor) after(o cursor) bool {
+	if !c.date.Equal(o.date) {
+		return c.date.After(o.date)
+	}
+	return c.playID > o.playID
+}
+
+type retryQueue struct {
+	items       []wavy.Item
+	queued      map[string]struct{}
+	attempts    int
+	nextAttempt time.Time
+}
+
+func newBridge(c wavy.Client, lb listenbrainz.Client, s store.HistoryStore, users []wavy.UserURI, logger hclog.Logger) *bridge {
+	return &bridge{
+		wavy:       c,
+		lb:         lb,
+		store:      s,
+		users:      users,
+		logger:     logger.Named("bridge"),
+		maxQueue:   defaultMaxQueue,
+		minBackoff: defaultMinBackoff,
+		maxBackoff: defaultMaxBackoff,
+		start:      time.Now(),
+		now:        time.Now,
+		queues:     make(map[string]*retryQueue),
+		playingNow: make(map[string]string),
+		submitted:  make(map[string]cursor),
+	}
+}
+
+// run syncs all users every interval until ctx is done.
+func (b *bridge) run(ctx context.Context, interval time.Duration) {
+	poll.Every(ctx, interval, b.sync)
+}
+
+// sync does a single pass over all users. Failures are logged and retried on the next pass.
+func (b *bridge) sync(ctx context.Context) {
+	for _, uri := range b.users {
+		logger := b.logger.With("user", uri.String())
+
+		if err := b.syncListens(ctx, uri); err != nil {
+			logger.Error("failed to sync listens", "error", err)
+		}
+		if err := b.syncPlayingNow(ctx, uri); err != nil {
+			logger.Warn("failed to sync playing now", "error", err)
+		}
+	}
+}
+
+func (b *bridge) queue(uri wavy.UserURI) *retryQueue {
+	q, ok := b.queues[uri.String()]
+	if !ok {
+		q = &retryQueue{queued: make(map[string]struct{})}
+		b.queues[uri.String()] = q
+	}
+	return q
+}
+
+// syncListens queues the listens newer than the checkpoint and submits the queue when it is due.
+func (b *bridge) syncListens(ctx context.Context, uri wavy.UserURI) error {
+	recent, err := b.wavy.UserService().HistroyService(uri).GetRecent(ctx)
+	if err != nil {
+		return err
+	}
+
+	since := cursor{date: b.start}
+	checkpoint, err := b.store.Checkpoint(ctx, uri)
+	switch {
+	case errors.Is(err, store.ErrNoCheckpoint):
+	case err != nil:
+		return err
+	default:
+		since = cursor{date: checkpoint.Date, playID: checkpoint.PlayID}
+	}
+	if submitted, ok := b.submitted[uri.String()]; ok && submitted.after(since) {
+		since = submitted
+	}
+
+	q := b.queue(uri)
+	for _, item := range recent.Items {
+		if !cursorOf(item).after(since) {
+			continue
+		}
+		if _, ok := q.queued[item.PlayID]; ok {
+			continue
+		}
+		q.queued[item.PlayID] = struct{}{}
+		q.items = append(q.items, item)
+	}
+	sort.Slice(q.items, func(i, j int) bool {
+		return cursorOf(q.items[j]).after(cursorOf(q.items[i]))
+	})
+
+	if dropped := len(q.items) - b.maxQueue; dropped > 0 {
+		b.logger.Warn("retry queue is full, dropping oldest listens", "user", uri.String(), "dropped", dropped)
+		for _, item := range q.items[:dropped] {
+			delete(q.queued, item.PlayID)
+		}
+		q.items = q.items[dropped:]
+	}
+
+	if len(q.items) == 0 || b.now().Before(q.nextAttempt) {
+		return nil
+	}
+
+	for len(q.items) > 0 {
+		batch := q.items
+		if len(batch) > listenbrainz.MaxListensPerSubmission {
+			batch = batch[:listenbrainz.MaxListensPerSubmission]
+		}
+
+		if err := b.submit(ctx, batch); err != nil {
+			q.attempts++
+			q.nextAttempt = b.now().Add(b.backoff(q.attempts, err))
+			return fmt.Errorf("failed to submit %d listens, retrying at %s: %w", len(batch), q.nextAttempt.Format(time.RFC3339), err)
+		}
+
+		if _, err := b.store.Append(ctx, uri, batch...); err != nil {
+			// The listens made it to ListenBrainz, so carry on and only lose the checkpoint when restarting.
+			b.logger.Error("failed to store checkpoint", "user", uri.String(), "error", err)
+		}
+		b.submitted[uri.String()] = cursorOf(batch[len(batch)-1])
+
+		for _, item := range batch {
+			delete(q.queued, item.PlayID)
+		}
+		q.items = q.items[len(batch):]
+		q.attempts = 0
+		q.nextAttempt = time.Time{}
+
+		b.logger.Info("submitted listens", "user", uri.String(), "count", len(batch))
+	}
+
+	return nil
+}
+
+func (b *bridge) submit(ctx context.Context, items []wavy.Item) error {
+	listens := make([]listenbrainz.Listen, 0, len(items))
+	for _, item := range items {
+		listens = append(listens, listenbrainz.FromItem(item))
+	}
+
+	listenType := listenbrainz.ListenTypeImport
+	if len(listens) == 1 {
+		listenType = listenbrainz.ListenTypeSingle
+	}
+
+	return b.lb.SubmitListens(ctx, listenbrainz.NewSubmission(listenType, listens...))
+}
+
+// backoff doubles the wait for every failed attempt, honouring the rate limit reset reported by ListenBrainz.
+func (b *bridge) backoff(attempts int, err error) time.Duration {
+	wait := b.minBackoff
+	for i := 1; i < attempts && wait < b.maxBackoff; i++ {
+		wait *= 2
+	}
+	if wait > b.maxBackoff {
+		wait = b.maxBackoff
+	}
+
+	var apiErr *listenbrainz.ApiError
+	if errors.As(err, &apiErr) && apiErr.RetryAfter > wait {
+		wait = apiErr.RetryAfter
+	}
+
+	return wait
+}
+
+// syncPlayingNow submits what the user is listening to, once per song.
+// Playing now listens are not stored nor retried since they are outdated by the next poll anyway.
+func (b *bridge) syncPlayingNow(ctx context.Context, uri wavy.UserURI) error {
+	current, err := b.wavy.UserService().HistroyService(uri).GetCurrent(ctx)
+	if err != nil {
+		return err
+	}
+	if current.Item.Song.Name == "" {
+		delete(b.playingNow, uri.String())
+		return nil
+	}
+
+	key := current.Item.Song.SourceURL + "|" + current.Item.Song.Name
+	if b.playingNow[uri.String()] == key {
+		return nil
+	}
+
+	listen := listenbrainz.FromCurrent(current.Item)
+	if err := b.lb.SubmitListens(ctx, listenbrainz.NewSubmission(listenbrainz.ListenTypePlayingNow, listen)); err != nil {
+		return err
+	}
+	b.playingNow[uri.String()] = key
+
+	return nil
+}
+
+// dryRunClient logs submissions instead of sending them.
+type dryRunClient struct {
+	logger hclog.Logger
+}
+
+func (d *dryRunClient) SubmitListens(ctx context.Context, submission listenbrainz.Submission) error {
+	raw, err := json.Marshal(submission)
+	if err != nil {
+		return err
+	}
+
+	d.logger.Info("dry run, not submitting", "type", submission.ListenType, "count", len(submission.Payload), "submission", string(raw))
+	return nil
+}
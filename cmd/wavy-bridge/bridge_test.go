@@ -0,0 +1,204 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/OGKevin/go-wavy/wavy"
+	"github.com/OGKevin/go-wavy/wavy/listenbrainz"
+	"github.com/OGKevin/go-wavy/wavy/store"
+	"github.com/OGKevin/go-wavy/wavy/wavytest"
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var user = wavy.UserURI{Username: "OGKevin"}
+
+// listenBrainzServer is a stand-in for a ListenBrainz compatible api which records the submissions it receives.
+type listenBrainzServer struct {
+	*httptest.Server
+
+	mu          sync.Mutex
+	submissions []listenbrainz.Submission
+	// fail makes the next n submissions fail with a 503.
+	fail int
+}
+
+func newListenBrainzServer(t *testing.T) *listenBrainzServer {
+	s := &listenBrainzServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		if s.fail > 0 {
+			s.fail--
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte(`{"code": 503, "error": "down for maintenance"}`))
+			return
+		}
+
+		var submission listenbrainz.Submission
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&submission))
+		s.submissions = append(s.submissions, submission)
+		w.Write([]byte(`{"status": "ok"}`))
+	}))
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+func (s *listenBrainzServer) listens(listenType listenbrainz.ListenType) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var names []string
+	for _, submission := range s.submissions {
+		if submission.ListenType != listenType {
+			continue
+		}
+		for _, listen := range submission.Payload {
+			names = append(names, listen.TrackMetadata.TrackName)
+		}
+	}
+	return names
+}
+
+func item(playID string, minutes int) wavy.Item {
+	return wavy.Item{
+		CurrentPlayingItem: wavy.CurrentPlayingItem{
+			Song:    wavy.Song{Source: "spotify", Name: playID},
+			Artists: []wavy.Artists{{Source: "spotify", Name: "Artist"}},
+		},
+		Date:   time.Date(2021, 3, 1, 12, minutes, 0, 0, time.UTC),
+		PlayID: playID,
+	}
+}
+
+func setup(t *testing.T) (*bridge, *wavytest.Client, *listenBrainzServer) {
+	c := wavytest.NewClient()
+	c.Current[user.String()] = &wavy.GetCurrentResponse{}
+	c.Recent[user.String()] = &wavy.GetRecentResponse{}
+
+	server := newListenBrainzServer(t)
+	lb := listenbrainz.NewClient(server.Client(), hclog.NewNullLogger(), server.URL, "token")
+
+	b := newBridge(c, lb, store.NewMemoryStore(), []wavy.UserURI{user}, hclog.NewNullLogger())
+	b.start = item("", 0).Date
+	return b, c, server
+}
+
+func Test_bridge_sync(t *testing.T) {
+	b, c, server := setup(t)
+	ctx := context.Background()
+
+	// The api returns the most recent listen first.
+	c.Recent[user.String()].Items = []wavy.Item{item("b", 2), item("a", 1)}
+	b.sync(ctx)
+	assert.Equal(t, []string{"a", "b"}, server.listens(listenbrainz.ListenTypeImport))
+
+	// Listens before the checkpoint are not submitted again.
+	c.Recent[user.String()].Items = []wavy.Item{item("c", 3), item("b", 2), item("a", 1)}
+	b.sync(ctx)
+	b.sync(ctx)
+	assert.Equal(t, []string{"c"}, server.listens(listenbrainz.ListenTypeSingle))
+
+	checkpoint, err := b.store.Checkpoint(ctx, user)
+	require.NoError(t, err)
+	assert.Equal(t, "c", checkpoint.PlayID)
+}
+
+func Test_bridge_sync_sameDate(t *testing.T) {
+	b, c, server := setup(t)
+	ctx := context.Background()
+
+	c.Recent[user.String()].Items = []wavy.Item{item("b", 1)}
+	b.sync(ctx)
+
+	// A listen sharing the date of the checkpoint is submitted, as long as it sorts after it.
+	c.Recent[user.String()].Items = []wavy.Item{item("c", 1), item("b", 1), item("a", 1)}
+	b.sync(ctx)
+	assert.Equal(t, []string{"b", "c"}, server.listens(listenbrainz.ListenTypeSingle))
+}
+
+func Test_bridge_sync_start(t *testing.T) {
+	b, c, server := setup(t)
+	ctx := context.Background()
+
+	// Without checkpoint, the listens before the start are not submitted.
+	b.start = item("", 2).Date
+	c.Recent[user.String()].Items = []wavy.Item{item("c", 3), item("b", 2), item("a", 1)}
+	b.sync(ctx)
+	assert.Equal(t, []string{"b", "c"}, server.listens(listenbrainz.ListenTypeImport))
+
+	// The checkpoint takes precedence over the start.
+	b.start = time.Time{}
+	b.sync(ctx)
+	assert.Equal(t, []string{"b", "c"}, server.listens(listenbrainz.ListenTypeImport))
+}
+
+func Test_bridge_sync_retry(t *testing.T) {
+	b, c, server := setup(t)
+	ctx := context.Background()
+
+	now := time.Date(2021, 3, 1, 13, 0, 0, 0, time.UTC)
+	b.now = func() time.Time { return now }
+
+	server.fail = 1
+	c.Recent[user.String()].Items = []wavy.Item{item("a", 1)}
+	b.sync(ctx)
+	assert.Empty(t, server.listens(listenbrainz.ListenTypeSingle))
+
+	// The failed listen scrolled out of the recent history, but is still queued.
+	// It is not retried before the backoff passed.
+	c.Recent[user.String()].Items = []wavy.Item{item("b", 2)}
+	b.sync(ctx)
+	assert.Empty(t, server.listens(listenbrainz.ListenTypeImport))
+
+	now = now.Add(defaultMinBackoff)
+	b.sync(ctx)
+	assert.Equal(t, []string{"a", "b"}, server.listens(listenbrainz.ListenTypeImport))
+
+	_, err := b.store.Checkpoint(ctx, user)
+	require.NoError(t, err)
+}
+
+func Test_bridge_sync_playingNow(t *testing.T) {
+	b, c, server := setup(t)
+	ctx := context.Background()
+
+	c.Current[user.String()].Item = item("now", 0).CurrentPlayingItem
+	b.sync(ctx)
+	b.sync(ctx)
+	assert.Equal(t, []string{"now"}, server.listens(listenbrainz.ListenTypePlayingNow))
+
+	c.Current[user.String()].Item = item("next", 0).CurrentPlayingItem
+	b.sync(ctx)
+	assert.Equal(t, []string{"now", "next"}, server.listens(listenbrainz.ListenTypePlayingNow))
+}
+
+func Test_bridge_sync_dryRun(t *testing.T) {
+	_, c, server := setup(t)
+	c.Recent[user.String()].Items = []wavy.Item{item("a", 1)}
+	c.Current[user.String()].Item = item("now", 0).CurrentPlayingItem
+
+	b := newBridge(c, &dryRunClient{logger: hclog.NewNullLogger()}, store.NewMemoryStore(), []wavy.UserURI{user}, hclog.NewNullLogger())
+	b.start = time.Time{}
+	b.sync(context.Background())
+
+	assert.Empty(t, server.submissions)
+}
+
+func Test_bridge_backoff(t *testing.T) {
+	b := newBridge(nil, nil, nil, nil, hclog.NewNullLogger())
+
+	assert.Equal(t, defaultMinBackoff, b.backoff(1, assert.AnError))
+	assert.Equal(t, 4*defaultMinBackoff, b.backoff(3, assert.AnError))
+	assert.Equal(t, defaultMaxBackoff, b.backoff(100, assert.AnError))
+	assert.Equal(t, time.Hour, b.backoff(1, &listenbrainz.ApiError{Code: 429, RetryAfter: time.Hour}))
+}
@@ -0,0 +1,111 @@
+// Command wavy-bridge tails the wavy.fm history of the configured users and submits their listens,
+// and what they are playing now, to a ListenBrainz compatible api.
+//
+// wavy.fm credentials are resolved by wavy.LoadConfig, from the environment, e.g. WAVY_CLIENT_ID and
+// WAVY_CLIENT_SECRET, or a profile of ~/.config/wavy/config.toml selected with -profile.
+// The ListenBrainz token is read from the LISTENBRAINZ_TOKEN environment variable.
+//
+// Users without state in the state directory start with the listens played after the bridge started, or within
+// -backfill before that.
+//
+//	wavy-bridge -users wavyfm:user:username:OGKevin -state ./state
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/OGKevin/go-wavy/wavy"
+	"github.com/OGKevin/go-wavy/wavy/listenbrainz"
+	"github.com/OGKevin/go-wavy/wavy/store"
+	"github.com/hashicorp/go-hclog"
+)
+
+func main() {
+	var (
+		users     = flag.String("users", "", "comma separated wavy.fm user uris to bridge, e.g. wavyfm:user:username:OGKevin")
+		lbURL     = flag.String("listenbrainz-url", listenbrainz.DefaultBaseURL, "base url of the ListenBrainz compatible api")
+		stateDir  = flag.String("state", "wavy-bridge-state", "directory in which the submitted listens are checkpointed")
+		interval  = flag.Duration("interval", time.Minute, "how often the history of every user is polled")
+		dryRun    = flag.Bool("dry-run", false, "log submissions instead of sending them, without touching the state directory")
+		logLevel  = flag.String("log-level", "info", "log level: trace, debug, info, warn or error")
+		maxQueued = flag.Int("max-queue", defaultMaxQueue, "maximum amount of listens kept per user while ListenBrainz is unreachable")
+		profile   = flag.String("profile", "", "profile of the wavy config file, defaults to $WAVY_PROFILE or default")
+		backfill  = flag.Duration("backfill", 0, "how far back the listens of users without state are submitted, 0 starts from now")
+	)
+	flag.Parse()
+
+	logger := hclog.New(&hclog.LoggerOptions{
+		Name:  "wavy-bridge",
+		Level: hclog.LevelFromString(*logLevel),
+	})
+
+	if err := run(logger, *users, *lbURL, *stateDir, *profile, *interval, *backfill, *dryRun, *maxQueued); err != nil {
+		logger.Error("exiting", "error", err)
+		os.Exit(1)
+	}
+}
+
+func run(logger hclog.Logger, users, lbURL, stateDir, profile string, interval, backfill time.Duration, dryRun bool, maxQueue int) error {
+	uris, err := wavy.ParseUserURIs(users)
+	if err != nil {
+		return err
+	}
+	if len(uris) == 0 {
+		return fmt.Errorf("no users configured, set -users")
+	}
+
+	cfg, err := wavy.LoadConfig(wavy.FromProfile(profile))
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-signals
+		logger.Info("shutting down")
+		cancel()
+	}()
+
+	var (
+		lb listenbrainz.Client
+		s  store.HistoryStore
+	)
+	if dryRun {
+		lb = &dryRunClient{logger: logger.Named("dry-run")}
+		s = store.NewMemoryStore()
+	} else {
+		token := os.Getenv("LISTENBRAINZ_TOKEN")
+		if token == "" {
+			return fmt.Errorf("LISTENBRAINZ_TOKEN is not set")
+		}
+		lb = listenbrainz.NewClient(nil, logger, lbURL, token)
+
+		s, err = store.NewFileStore(stateDir)
+		if err != nil {
+			return err
+		}
+	}
+	defer s.Close()
+
+	c, err := wavy.NewClientFromConfig(ctx, wavy.NewHCLogLogger(logger), cfg)
+	if err != nil {
+		return err
+	}
+
+	b := newBridge(c, lb, s, uris, logger)
+	b.maxQueue = maxQueue
+	b.start = b.start.Add(-backfill)
+	b.run(ctx, interval)
+
+	return nil
+}
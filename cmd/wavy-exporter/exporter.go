@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/OGKevin/go-wavy/wavy"
+	"github.com/hashicorp/go-hclog"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	totalListensDesc = prometheus.NewDesc("wavy_total_listens", "Total amount of listens recorded on wavy.fm.", nil, nil)
+	totalUsersDesc   = prometheus.NewDesc("wavy_total_users", "Total amount of registered users on wavy.fm.", nil, nil)
+	leaderboardDesc  = prometheus.NewDesc("wavy_leaderboard_listens", "Listen count of the users on the listens leaderboard.", []string{"user_id"}, nil)
+	rankDesc         = prometheus.NewDesc("wavy_leaderboard_rank", "Rank of the users on the listens leaderboard.", []string{"user_id"}, nil)
+	leaderInfoDesc   = prometheus.NewDesc("wavy_leaderboard_user_info", "Username of the users on the listens leaderboard, always 1.", []string{"user_id", "username"}, nil)
+	userListensDesc  = prometheus.NewDesc("wavy_user_total_listens", "Total amount of listens of a configured user.", []string{"user"}, nil)
+	userArtistsDesc  = prometheus.NewDesc("wavy_user_total_artists", "Total amount of artists listened to by a configured user.", []string{"user"}, nil)
+	successDesc      = prometheus.NewDesc("wavy_exporter_scrape_success", "Whether the last request to the endpoint succeeded.", []string{"endpoint", "user"}, nil)
+	ageDesc          = prometheus.NewDesc("wavy_exporter_cache_age_seconds", "Age of the cached response of the endpoint.", []string{"endpoint", "user"}, nil)
+)
+
+// result is a cached response of a single endpoint, or the error requesting it.
+type result struct {
+	fetched time.Time
+	value   interface{}
+	err     error
+}
+
+// exporter collects the wavy.fm metrics on scrape.
+// Responses are cached for ttl, failures included, so frequent or concurrent scrapes do not hammer wavy.fm.
+type exporter struct {
+	c      wavy.Client
+	users  []wavy.UserURI
+	ttl    time.Duration
+	logger hclog.Logger
+	now    func() time.Time
+
+	mu     sync.Mutex
+	cache  map[string]result
+	errors *prometheus.CounterVec
+}
+
+func newExporter(c wavy.Client, users []wavy.UserURI, ttl time.Duration, logger hclog.Logger) *exporter {
+	return &exporter{
+		c:      c,
+		users:  users,
+		ttl:    ttl,
+		logger: logger.Named("exporter"),
+		now:    time.Now,
+		cache:  make(map[string]result),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "wavy_exporter_scrape_errors_total",
+			Help: "Total amount of failed requests per endpoint.",
+		}, []string{"endpoint", "user"}),
+	}
+}
+
+func (e *exporter) Describe(ch chan<- *prometheus.Desc) {
+	ch <- totalListensDesc
+	ch <- totalUsersDesc
+	ch <- leaderboardDesc
+	ch <- rankDesc
+	ch <- leaderInfoDesc
+	ch <- userListensDesc
+	ch <- userArtistsDesc
+	ch <- successDesc
+	ch <- ageDesc
+	e.errors.Describe(ch)
+}
+
+func (e *exporter) Collect(ch chan<- prometheus.Metric) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if v, ok := e.fetch(ctx, ch, "total-listens", "", func(ctx context.Context) (interface{}, error) {
+		res, err := e.c.MetricsService().GetTotalListens(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return res.TotalListens, nil
+	}); ok {
+		ch <- prometheus.MustNewConstMetric(totalListensDesc, prometheus.GaugeValue, float64(v.(int64)))
+	}
+
+	if v, ok := e.fetch(ctx, ch, "total-users", "", func(ctx context.Context) (interface{}, error) {
+		res, err := e.c.MetricsService().GetTotalUsers(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return res.TotalUsers, nil
+	}); ok {
+		ch <- prometheus.MustNewConstMetric(totalUsersDesc, prometheus.GaugeValue, float64(v.(int64)))
+	}
+
+	if v, ok := e.fetch(ctx, ch, "user-listens-leaderboard", "", func(ctx context.Context) (interface{}, error) {
+		return e.c.MetricsService().GetUserListensLeaderboard(ctx)
+	}); ok {
+		for _, entry := range v.(*wavy.UserListensLeaderboardResponse).Entries {
+			ch <- prometheus.MustNewConstMetric(leaderboardDesc, prometheus.GaugeValue, float64(entry.Count), entry.UserID)
+			ch <- prometheus.MustNewConstMetric(rankDesc, prometheus.GaugeValue, float64(entry.Rank), entry.UserID)
+			ch <- prometheus.MustNewConstMetric(leaderInfoDesc, prometheus.GaugeValue, 1, entry.UserID, entry.Username)
+		}
+	}
+
+	for _, uri := range e.users {
+		uri := uri
+		if v, ok := e.fetch(ctx, ch, "history-stats", uri.String(), func(ctx context.Context) (interface{}, error) {
+			return e.c.UserService().HistroyService(uri).GetStats(ctx)
+		}); ok {
+			stats := v.(*wavy.GetHistroyStatsResponse)
+			ch <- prometheus.MustNewConstMetric(userListensDesc, prometheus.GaugeValue, float64(stats.TotalListens), uri.String())
+			ch <- prometheus.MustNewConstMetric(userArtistsDesc, prometheus.GaugeValue, float64(stats.TotalArtists), uri.String())
+		}
+	}
+
+	e.errors.Collect(ch)
+}
+
+// fetch returns the cached response of the endpoint, requesting it when the cache expired.
+// It reports the success and cache age of the endpoint, ok is false when the last request failed.
+func (e *exporter) fetch(ctx context.Context, ch chan<- prometheus.Metric, endpoint, user string, get func(ctx context.Context) (interface{}, error)) (interface{}, bool) {
+	key := endpoint + "|" + user
+
+	res, cached := e.cache[key]
+	if !cached || e.now().Sub(res.fetched) >= e.ttl {
+		value, err := get(ctx)
+		res = result{fetched: e.now(), value: value, err: err}
+		e.cache[key] = res
+
+		if err != nil {
+			e.logger.Warn("failed to scrape endpoint", "endpoint", endpoint, "user", user, "error", err)
+			e.errors.WithLabelValues(endpoint, user).Inc()
+		}
+	}
+
+	success := 1.0
+	if res.err != nil {
+		success = 0
+	}
+	ch <- prometheus.MustNewConstMetric(successDesc, prometheus.GaugeValue, success, endpoint, user)
+	ch <- prometheus.MustNewConstMetric(ageDesc, prometheus.GaugeValue, e.now().Sub(res.fetched).Seconds(), endpoint, user)
+
+	return res.value, res.err == nil
+}
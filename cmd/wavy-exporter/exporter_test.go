@@ -0,0 +1,96 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/OGKevin/go-wavy/wavy"
+	"github.com/OGKevin/go-wavy/wavy/wavytest"
+	"github.com/hashicorp/go-hclog"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+var user = wavy.UserURI{Username: "OGKevin"}
+
+func setup() (*exporter, *wavytest.Client, *time.Time) {
+	c := wavytest.NewClient()
+	c.TotalListens = 1000
+	c.TotalUsers = 10
+	c.Leaderboard = []wavy.LeaderboardEntry{{Count: 500, Username: "OGKevin", UserID: "1"}}
+	c.Stats[user.String()] = &wavy.GetHistroyStatsResponse{TotalListens: 500, TotalArtists: 50}
+
+	e := newExporter(c, []wavy.UserURI{user}, time.Minute, hclog.NewNullLogger())
+	now := time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC)
+	e.now = func() time.Time { return now }
+
+	return e, c, &now
+}
+
+func Test_exporter_Collect(t *testing.T) {
+	e, _, _ := setup()
+
+	expected := `
+# HELP wavy_leaderboard_listens Listen count of the users on the listens leaderboard.
+# TYPE wavy_leaderboard_listens gauge
+wavy_leaderboard_listens{user_id="1"} 500
+# HELP wavy_leaderboard_rank Rank of the users on the listens leaderboard.
+# TYPE wavy_leaderboard_rank gauge
+wavy_leaderboard_rank{user_id="1"} 1
+# HELP wavy_leaderboard_user_info Username of the users on the listens leaderboard, always 1.
+# TYPE wavy_leaderboard_user_info gauge
+wavy_leaderboard_user_info{user_id="1",username="OGKevin"} 1
+# HELP wavy_total_listens Total amount of listens recorded on wavy.fm.
+# TYPE wavy_total_listens gauge
+wavy_total_listens 1000
+# HELP wavy_total_users Total amount of registered users on wavy.fm.
+# TYPE wavy_total_users gauge
+wavy_total_users 10
+# HELP wavy_user_total_artists Total amount of artists listened to by a configured user.
+# TYPE wavy_user_total_artists gauge
+wavy_user_total_artists{user="wavyfm:user:username:OGKevin"} 50
+# HELP wavy_user_total_listens Total amount of listens of a configured user.
+# TYPE wavy_user_total_listens gauge
+wavy_user_total_listens{user="wavyfm:user:username:OGKevin"} 500
+`
+	assert.NoError(t, testutil.CollectAndCompare(e, strings.NewReader(expected),
+		"wavy_leaderboard_listens", "wavy_leaderboard_rank", "wavy_leaderboard_user_info", "wavy_total_listens", "wavy_total_users", "wavy_user_total_artists", "wavy_user_total_listens"))
+}
+
+func Test_exporter_Collect_cache(t *testing.T) {
+	e, c, now := setup()
+
+	testutil.CollectAndCount(e)
+	testutil.CollectAndCount(e)
+	assert.Equal(t, 1, c.Calls("GetTotalListens"))
+	assert.Equal(t, 1, c.Calls("GetStats"))
+
+	*now = now.Add(time.Minute)
+	testutil.CollectAndCount(e)
+	assert.Equal(t, 2, c.Calls("GetTotalListens"))
+}
+
+func Test_exporter_Collect_errors(t *testing.T) {
+	e, c, now := setup()
+	delete(c.Stats, user.String())
+
+	expected := `
+# HELP wavy_exporter_scrape_errors_total Total amount of failed requests per endpoint.
+# TYPE wavy_exporter_scrape_errors_total counter
+wavy_exporter_scrape_errors_total{endpoint="history-stats",user="wavyfm:user:username:OGKevin"} 1
+# HELP wavy_exporter_scrape_success Whether the last request to the endpoint succeeded.
+# TYPE wavy_exporter_scrape_success gauge
+wavy_exporter_scrape_success{endpoint="history-stats",user="wavyfm:user:username:OGKevin"} 0
+wavy_exporter_scrape_success{endpoint="total-listens",user=""} 1
+wavy_exporter_scrape_success{endpoint="total-users",user=""} 1
+wavy_exporter_scrape_success{endpoint="user-listens-leaderboard",user=""} 1
+`
+	assert.NoError(t, testutil.CollectAndCompare(e, strings.NewReader(expected), "wavy_exporter_scrape_errors_total", "wavy_exporter_scrape_success"))
+	assert.Equal(t, 0, testutil.CollectAndCount(e, "wavy_user_total_listens"))
+
+	// Failures are cached as well.
+	*now = now.Add(time.Second)
+	testutil.CollectAndCount(e)
+	assert.Equal(t, 1, c.Calls("GetStats"))
+}
@@ -0,0 +1,69 @@
+// Command wavy-exporter exposes wavy.fm metrics to Prometheus.
+//
+// Credentials are resolved by wavy.LoadConfig, from the environment, e.g. WAVY_CLIENT_ID and WAVY_CLIENT_SECRET,
+// or a profile of ~/.config/wavy/config.toml selected with -profile.
+//
+//	wavy-exporter -users wavyfm:user:username:OGKevin -cache-ttl 1m
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/OGKevin/go-wavy/wavy"
+	"github.com/hashicorp/go-hclog"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+func main() {
+	var (
+		listen   = flag.String("listen", ":9521", "address to serve /metrics on")
+		users    = flag.String("users", "", "comma separated wavy.fm user uris to export history stats for")
+		ttl      = flag.Duration("cache-ttl", time.Minute, "how long responses of wavy.fm are reused between scrapes")
+		logLevel = flag.String("log-level", "info", "log level: trace, debug, info, warn or error")
+		profile  = flag.String("profile", "", "profile of the wavy config file, defaults to $WAVY_PROFILE or default")
+	)
+	flag.Parse()
+
+	logger := hclog.New(&hclog.LoggerOptions{
+		Name:  "wavy-exporter",
+		Level: hclog.LevelFromString(*logLevel),
+	})
+
+	if err := run(logger, *listen, *users, *profile, *ttl); err != nil {
+		logger.Error("exiting", "error", err)
+		os.Exit(1)
+	}
+}
+
+func run(logger hclog.Logger, listen, users, profile string, ttl time.Duration) error {
+	uris, err := wavy.ParseUserURIs(users)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := wavy.LoadConfig(wavy.FromProfile(profile))
+	if err != nil {
+		return err
+	}
+	c, err := wavy.NewClientFromConfig(context.Background(), wavy.NewHCLogLogger(logger), cfg)
+	if err != nil {
+		return err
+	}
+
+	registry := prometheus.NewRegistry()
+	if err := registry.Register(newExporter(c, uris, ttl, logger)); err != nil {
+		return fmt.Errorf("failed to register exporter: %w", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+
+	logger.Info("listening", "address", listen)
+	return http.ListenAndServe(listen, mux)
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"math"
+	"sync"
+	"unicode/utf8"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+)
+
+// Badge colors, matching the named colors of shields.io.
+const (
+	colorLabel   = "#555"
+	colorSuccess = "#4c1"
+	colorInfo    = "#007ec6"
+	colorGrey    = "#9f9f9f"
+	colorError   = "#e05d44"
+)
+
+// maxBadgeText is the amount of characters after which the message of a badge is truncated.
+const maxBadgeText = 48
+
+// badge is a shields.io style flat badge: a label on the left, a message on a colored background on the right.
+type badge struct {
+	label   string
+	message string
+	color   string
+}
+
+var (
+	badgeFaceOnce sync.Once
+	badgeFace     font.Face
+	badgeFaceErr  error
+	// badgeFaceMu guards badgeFace, which is not safe for concurrent use.
+	badgeFaceMu sync.Mutex
+)
+
+// textWidth returns the width of s in pixels when rendered at the 11px font size of badges.
+func textWidth(s string) (float64, error) {
+	badgeFaceOnce.Do(func() {
+		f, err := opentype.Parse(goregular.TTF)
+		if err != nil {
+			badgeFaceErr = fmt.Errorf("failed to parse badge font: %w", err)
+			return
+		}
+		badgeFace, badgeFaceErr = opentype.NewFace(f, &opentype.FaceOptions{Size: 11, DPI: 72, Hinting: font.HintingNone})
+	})
+	if badgeFaceErr != nil {
+		return 0, badgeFaceErr
+	}
+
+	badgeFaceMu.Lock()
+	defer badgeFaceMu.Unlock()
+
+	w := font.MeasureString(badgeFace, s)
+	return float64(w) / 64, nil
+}
+
+// truncateText shortens s to at most n characters, ending in an ellipsis when it was truncated.
+func truncateText(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n-1]) + "…"
+}
+
+// WriteSVG writes the badge to w as svg.
+func (b badge) WriteSVG(w io.Writer) error {
+	message := truncateText(b.message, maxBadgeText)
+
+	labelText, err := textWidth(b.label)
+	if err != nil {
+		return err
+	}
+	messageText, err := textWidth(message)
+	if err != nil {
+		return err
+	}
+
+	const padding = 6
+	labelWidth := int(math.Ceil(labelText)) + 2*padding
+	messageWidth := int(math.Ceil(messageText)) + 2*padding
+	width := labelWidth + messageWidth
+
+	label, msg := html.EscapeString(b.label), html.EscapeString(message)
+
+	_, err = fmt.Fprintf(w, `<svg xmlns="http://www.w3.org/2000/svg" width="%[1]d" height="20" role="img" aria-label="%[2]s: %[3]s">
+<title>%[2]s: %[3]s</title>
+<linearGradient id="s" x2="0" y2="100%%"><stop offset="0" stop-color="#bbb" stop-opacity=".1"/><stop offset="1" stop-opacity=".1"/></linearGradient>
+<clipPath id="r"><rect width="%[1]d" height="20" rx="3" fill="#fff"/></clipPath>
+<g clip-path="url(#r)"><rect width="%[4]d" height="20" fill="%[6]s"/><rect x="%[4]d" width="%[5]d" height="20" fill="%[7]s"/><rect width="%[1]d" height="20" fill="url(#s)"/></g>
+<g fill="#fff" text-anchor="middle" font-family="Verdana,Geneva,DejaVu Sans,sans-serif" font-size="11">
+<text x="%[8]g" y="15" fill="#010101" fill-opacity=".3">%[2]s</text><text x="%[8]g" y="14">%[2]s</text>
+<text x="%[9]g" y="15" fill="#010101" fill-opacity=".3">%[3]s</text><text x="%[9]g" y="14">%[3]s</text>
+</g>
+</svg>
+`, width, label, msg, labelWidth, messageWidth, colorLabel, b.color, float64(labelWidth)/2, float64(labelWidth)+float64(messageWidth)/2)
+	return err
+}
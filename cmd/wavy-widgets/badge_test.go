@@ -0,0 +1,40 @@
+package main
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_badge_WriteSVG(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, badge{label: "now playing", message: `Simon & Garfunkel - "The Boxer"`, color: colorSuccess}.WriteSVG(&buf))
+
+	svg := buf.String()
+	assert.NoError(t, xml.Unmarshal(buf.Bytes(), new(interface{})), "badge is not well formed xml")
+	assert.Contains(t, svg, `aria-label="now playing: Simon &amp; Garfunkel - &#34;The Boxer&#34;"`)
+	assert.Contains(t, svg, `fill="#4c1"`)
+
+	var short, long bytes.Buffer
+	require.NoError(t, badge{label: "a", message: "b", color: colorInfo}.WriteSVG(&short))
+	require.NoError(t, badge{label: "a", message: strings.Repeat("b", 20), color: colorInfo}.WriteSVG(&long))
+	assert.NotEqual(t, badgeWidth(t, short.String()), badgeWidth(t, long.String()), "badge width does not follow its text")
+}
+
+func Test_truncateText(t *testing.T) {
+	assert.Equal(t, "short", truncateText("short", 5))
+	assert.Equal(t, "shor…", truncateText("shorter", 5))
+	assert.Equal(t, "ümla…", truncateText("ümlauts", 5))
+}
+
+func badgeWidth(t *testing.T, svg string) string {
+	var root struct {
+		Width string `xml:"width,attr"`
+	}
+	require.NoError(t, xml.Unmarshal([]byte(svg), &root))
+	return root.Width
+}
@@ -0,0 +1,88 @@
+// Command wavy-widgets serves embeddable badges and widgets showing what wavy.fm users are listening to, so they
+// can be shown on dashboards without handing out the credentials of the wavy.fm application.
+//
+// Credentials are resolved by wavy.LoadConfig, from the environment, e.g. WAVY_CLIENT_ID and WAVY_CLIENT_SECRET,
+// or a profile of ~/.config/wavy/config.toml selected with -profile.
+//
+//	wavy-widgets -users wavyfm:user:username:OGKevin -cache-ttl 1m
+//
+// Only the users passed to -users are served. -all-users serves every user instead, which lets anyone spend the rate
+// limit of the wavy.fm application.
+//
+// The following routes are served per user uri:
+//
+//	/badge/<uri>/now-playing.svg   shields style badge of the song the user is listening to
+//	/badge/<uri>/total-listens.svg shields style badge of the total listens of the user
+//	/widget/<uri>.json             profile, current listen and history stats as json
+//	/widget/<uri>.html             html widget of the current listen, themed with ?theme=light|dark|vapor
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/OGKevin/go-wavy/wavy"
+	"github.com/hashicorp/go-hclog"
+)
+
+func main() {
+	var (
+		listen    = flag.String("listen", ":8080", "address to serve the widgets on")
+		users     = flag.String("users", "", "comma separated wavy.fm user uris to serve widgets for")
+		allUsers  = flag.Bool("all-users", false, "serve widgets for every wavy.fm user instead of -users")
+		ttl       = flag.Duration("cache-ttl", time.Minute, "how long responses of wavy.fm are reused between requests")
+		rateLimit = flag.Float64("rate-limit", 2, "requests per second sent to wavy.fm, unless the config sets rate_limit")
+		logLevel  = flag.String("log-level", "info", "log level: trace, debug, info, warn or error")
+		profile   = flag.String("profile", "", "profile of the wavy config file, defaults to $WAVY_PROFILE or default")
+	)
+	flag.Parse()
+
+	logger := hclog.New(&hclog.LoggerOptions{
+		Name:  "wavy-widgets",
+		Level: hclog.LevelFromString(*logLevel),
+	})
+
+	if err := run(logger, *listen, *users, *allUsers, *profile, *ttl, *rateLimit); err != nil {
+		logger.Error("exiting", "error", err)
+		os.Exit(1)
+	}
+}
+
+func run(logger hclog.Logger, listen, users string, allUsers bool, profile string, ttl time.Duration, rateLimit float64) error {
+	uris, err := wavy.ParseUserURIs(users)
+	if err != nil {
+		return err
+	}
+	switch {
+	case len(uris) == 0 && !allUsers:
+		return fmt.Errorf("no users configured, set -users or -all-users")
+	case len(uris) > 0 && allUsers:
+		return fmt.Errorf("-users and -all-users are mutually exclusive")
+	}
+
+	cfg, err := wavy.LoadConfig(wavy.FromProfile(profile))
+	if err != nil {
+		return err
+	}
+	var opts []wavy.ClientOption
+	if cfg.RateLimit == 0 && rateLimit > 0 {
+		opts = append(opts, wavy.WithRateLimit(rateLimit, 1))
+	}
+	c, err := wavy.NewClientFromConfig(context.Background(), wavy.NewHCLogLogger(logger), cfg, opts...)
+	if err != nil {
+		return err
+	}
+
+	srv := &http.Server{
+		Addr:              listen,
+		Handler:           newServer(c, uris, ttl, logger),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	logger.Info("listening", "address", listen)
+	return srv.ListenAndServe()
+}
@@ -0,0 +1,465 @@
+package main
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"image/color"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/OGKevin/go-wavy/wavy"
+	"github.com/OGKevin/go-wavy/wavy/cards"
+	"github.com/hashicorp/go-hclog"
+)
+
+// fetchTimeout bounds a request to wavy.fm, independent of the request which triggered it, which may be shared by
+// concurrent requests for the same user.
+const fetchTimeout = 30 * time.Second
+
+// maxCacheEntries is the amount of cached responses after which the least recently used responses are dropped.
+const maxCacheEntries = 1024
+
+// entry is a cached response of a single endpoint, or the error requesting it. done is closed once it is fetched.
+type entry struct {
+	key     string
+	elem    *list.Element
+	done    chan struct{}
+	fetched time.Time
+	value   interface{}
+	err     error
+}
+
+// server serves the badges and widgets of wavy.fm users.
+// Responses of wavy.fm are cached for ttl, failures included, and concurrent requests for the same response share a
+// single request to wavy.fm. Errors are logged, but not returned to clients.
+type server struct {
+	c      wavy.Client
+	users  map[string]bool
+	ttl    time.Duration
+	logger hclog.Logger
+	now    func() time.Time
+
+	mu         sync.Mutex
+	cache      map[string]*entry
+	lru        *list.List
+	maxEntries int
+}
+
+// newServer returns a server for users, or for every user when users is empty.
+func newServer(c wavy.Client, users []wavy.UserURI, ttl time.Duration, logger hclog.Logger) *server {
+	s := &server{
+		c:      c,
+		ttl:    ttl,
+		logger: logger.Named("server"),
+		now:    time.Now,
+		cache:  make(map[string]*entry),
+		lru:    list.New(),
+
+		maxEntries: maxCacheEntries,
+	}
+	if len(users) > 0 {
+		s.users = make(map[string]bool, len(users))
+		for _, uri := range users {
+			s.users[uri.String()] = true
+		}
+	}
+
+	return s
+}
+
+func (s *server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	switch {
+	case strings.HasPrefix(r.URL.Path, "/badge/"):
+		rest := strings.TrimPrefix(r.URL.Path, "/badge/")
+		i := strings.LastIndex(rest, "/")
+		if i < 0 {
+			break
+		}
+		uri, ok := s.user(rest[:i])
+		if !ok {
+			break
+		}
+		switch rest[i+1:] {
+		case "now-playing.svg":
+			s.serveBadge(w, r, s.nowPlayingBadge(r.Context(), uri))
+			return
+		case "total-listens.svg":
+			s.serveBadge(w, r, s.totalListensBadge(r.Context(), uri))
+			return
+		}
+	case strings.HasPrefix(r.URL.Path, "/widget/"):
+		rest := strings.TrimPrefix(r.URL.Path, "/widget/")
+		switch {
+		case strings.HasSuffix(rest, ".json"):
+			if uri, ok := s.user(strings.TrimSuffix(rest, ".json")); ok {
+				s.serveJSON(w, r, uri)
+				return
+			}
+		case strings.HasSuffix(rest, ".html"):
+			if uri, ok := s.user(strings.TrimSuffix(rest, ".html")); ok {
+				s.serveHTML(w, r, uri)
+				return
+			}
+		}
+	}
+
+	http.NotFound(w, r)
+}
+
+// user parses raw as user uri, ok is false when it is invalid or not served.
+func (s *server) user(raw string) (wavy.UserURI, bool) {
+	uri, err := wavy.ParseUserURI(raw)
+	if err != nil {
+		return wavy.UserURI{}, false
+	}
+	if s.users != nil && !s.users[uri.String()] {
+		return wavy.UserURI{}, false
+	}
+	return *uri, true
+}
+
+// cacheControl lets browsers and proxies reuse responses for as long as the server caches them.
+func (s *server) cacheControl(w http.ResponseWriter) {
+	w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int(s.ttl.Seconds())))
+}
+
+// serveBadge writes b. Badges are served with status 200 when wavy.fm failed, as their message describes the
+// failure and image proxies drop images of other statuses.
+func (s *server) serveBadge(w http.ResponseWriter, r *http.Request, b badge) {
+	var buf bytes.Buffer
+	if err := b.WriteSVG(&buf); err != nil {
+		s.logger.Error("failed to render badge", "path", r.URL.Path, "error", err)
+		http.Error(w, "failed to render badge", http.StatusInternalServerError)
+		return
+	}
+
+	s.cacheControl(w)
+	w.Header().Set("Content-Type", "image/svg+xml; charset=utf-8")
+	w.Write(buf.Bytes())
+}
+
+func (s *server) nowPlayingBadge(ctx context.Context, uri wavy.UserURI) badge {
+	current, err := s.current(ctx, uri)
+	if err != nil {
+		return errorBadge("now playing", err)
+	}
+	if current == nil {
+		return badge{label: "now playing", message: "nothing", color: colorGrey}
+	}
+
+	message := current.Song
+	if len(current.Artists) > 0 {
+		message = strings.Join(current.Artists, ", ") + " - " + message
+	}
+	return badge{label: "now playing", message: message, color: colorSuccess}
+}
+
+func (s *server) totalListensBadge(ctx context.Context, uri wavy.UserURI) badge {
+	stats, err := s.stats(ctx, uri)
+	if err != nil {
+		return errorBadge("total listens", err)
+	}
+	return badge{label: "total listens", message: strconv.Itoa(stats.TotalListens), color: colorInfo}
+}
+
+// errorBadge describes err without leaking its details.
+func errorBadge(label string, err error) badge {
+	if status(err) == http.StatusNotFound {
+		return badge{label: label, message: "user not found", color: colorGrey}
+	}
+	return badge{label: label, message: "unavailable", color: colorError}
+}
+
+// status returns the http status describing err to clients.
+func status(err error) int {
+	var apiErr *wavy.ApiError
+	if errors.As(err, &apiErr) && apiErr.Status == http.StatusNotFound {
+		return http.StatusNotFound
+	}
+	return http.StatusBadGateway
+}
+
+// widget is the json widget of a user. NowPlaying is nil when the user is not listening to anything, Stats when
+// they are unavailable.
+type widget struct {
+	URI        string      `json:"uri"`
+	Username   string      `json:"username"`
+	URL        string      `json:"url"`
+	Avatar     string      `json:"avatar,omitempty"`
+	NowPlaying *nowPlaying `json:"now_playing"`
+	Stats      *stats      `json:"stats"`
+}
+
+type nowPlaying struct {
+	Song    string   `json:"song"`
+	SongURL string   `json:"song_url,omitempty"`
+	Artists []string `json:"artists"`
+	Album   string   `json:"album,omitempty"`
+	ArtURL  string   `json:"art_url,omitempty"`
+	Local   bool     `json:"local"`
+}
+
+type stats struct {
+	TotalListens int `json:"total_listens"`
+	TotalArtists int `json:"total_artists"`
+}
+
+// widget collects the widget of uri. Only a failure to fetch the profile fails the widget, failures of the current
+// listen and stats are logged and leave their field nil.
+func (s *server) widget(ctx context.Context, uri wavy.UserURI) (*widget, error) {
+	v, err := s.fetch(ctx, "profile", uri, func(ctx context.Context) (interface{}, error) {
+		return s.c.UserService().GetProfile(ctx, uri)
+	})
+	if err != nil {
+		return nil, err
+	}
+	profile := v.(*wavy.GetUserProfileResponse)
+
+	wgt := &widget{
+		URI:      profile.URI,
+		Username: profile.Username,
+		URL:      profile.Profile.URL,
+		Avatar:   profile.Profile.AvatarURL(wavy.SmallAvatarSize),
+	}
+	if wgt.URI == "" {
+		wgt.URI = uri.String()
+	}
+	wgt.NowPlaying, _ = s.current(ctx, uri)
+	wgt.Stats, _ = s.stats(ctx, uri)
+
+	return wgt, nil
+}
+
+// current returns what uri is listening to, nil when nothing.
+func (s *server) current(ctx context.Context, uri wavy.UserURI) (*nowPlaying, error) {
+	v, err := s.fetch(ctx, "current", uri, func(ctx context.Context) (interface{}, error) {
+		return s.c.UserService().HistroyService(uri).GetCurrent(ctx)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	item := v.(*wavy.GetCurrentResponse).Item
+	if item.Song.Name == "" {
+		return nil, nil
+	}
+
+	artists := make([]string, 0, len(item.Artists))
+	for _, artist := range item.Artists {
+		artists = append(artists, artist.Name)
+	}
+	return &nowPlaying{
+		Song:    item.Song.Name,
+		SongURL: item.Song.SourceURL,
+		Artists: artists,
+		Album:   item.Album.Name,
+		ArtURL:  item.Album.ArtURL,
+		Local:   item.Local,
+	}, nil
+}
+
+func (s *server) stats(ctx context.Context, uri wavy.UserURI) (*stats, error) {
+	v, err := s.fetch(ctx, "stats", uri, func(ctx context.Context) (interface{}, error) {
+		return s.c.UserService().HistroyService(uri).GetStats(ctx)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	res := v.(*wavy.GetHistroyStatsResponse)
+	return &stats{TotalListens: res.TotalListens, TotalArtists: res.TotalArtists}, nil
+}
+
+func (s *server) serveJSON(w http.ResponseWriter, r *http.Request, uri wavy.UserURI) {
+	wgt, err := s.widget(r.Context(), uri)
+	if err != nil {
+		code := status(err)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(code)
+		json.NewEncoder(w).Encode(map[string]string{"error": strings.ToLower(http.StatusText(code))})
+		return
+	}
+
+	s.cacheControl(w)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(wgt)
+}
+
+var widgetTemplate = template.Must(template.New("widget").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Widget.Username}} on wavy.fm</title>
+<style>
+body { margin: 0; font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; background: transparent; }
+.widget { display: flex; gap: 16px; align-items: center; padding: 16px; border-radius: 10px; border: 1px solid {{.Border}}; background: {{.Background}}; color: {{.Text}}; }
+.art { width: 64px; height: 64px; border-radius: 6px; object-fit: cover; background: {{.Border}}; flex: none; }
+.label { margin: 0; font-size: 12px; font-weight: bold; letter-spacing: .04em; text-transform: uppercase; color: {{.Accent}}; }
+.song { margin: 4px 0; font-size: 18px; font-weight: bold; color: {{.Title}}; }
+.song a { color: inherit; text-decoration: none; }
+.muted { margin: 0; font-size: 13px; color: {{.Muted}}; }
+</style>
+</head>
+<body>
+<div class="widget">
+{{- with .Widget.NowPlaying}}
+{{if .ArtURL}}<img class="art" src="{{.ArtURL}}" alt="">{{else}}<div class="art"></div>{{end}}
+<div>
+<p class="label">Now playing · <a href="{{$.Widget.URL}}" target="_blank" rel="noopener" style="color: inherit">{{$.Widget.Username}}</a></p>
+<p class="song">{{if .SongURL}}<a href="{{.SongURL}}" target="_blank" rel="noopener">{{.Song}}</a>{{else}}{{.Song}}{{end}}</p>
+<p class="muted">{{range $i, $a := .Artists}}{{if $i}}, {{end}}{{$a}}{{end}}{{if .Album}} · {{.Album}}{{end}}</p>
+</div>
+{{- else}}
+{{if .Widget.Avatar}}<img class="art" src="{{.Widget.Avatar}}" alt="">{{else}}<div class="art"></div>{{end}}
+<div>
+<p class="label">Not playing</p>
+<p class="song"><a href="{{.Widget.URL}}" target="_blank" rel="noopener">{{.Widget.Username}}</a></p>
+{{with .Widget.Stats}}<p class="muted">{{.TotalListens}} listens · {{.TotalArtists}} artists</p>{{end}}
+</div>
+{{- end}}
+</div>
+</body>
+</html>
+`))
+
+// widgetPage is the data of widgetTemplate, the colors of the theme formatted as css.
+type widgetPage struct {
+	Widget                                         *widget
+	Background, Border, Title, Text, Muted, Accent string
+}
+
+func css(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+func (s *server) serveHTML(w http.ResponseWriter, r *http.Request, uri wavy.UserURI) {
+	theme, err := cards.ThemeByName(r.URL.Query().Get("theme"))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("unknown theme, expected one of %s", strings.Join(cards.ThemeNames(), ", ")), http.StatusBadRequest)
+		return
+	}
+
+	wgt, err := s.widget(r.Context(), uri)
+	if err != nil {
+		code := status(err)
+		http.Error(w, strings.ToLower(http.StatusText(code)), code)
+		return
+	}
+
+	page := widgetPage{
+		Widget:     wgt,
+		Background: css(theme.Background),
+		Border:     css(theme.Border),
+		Title:      css(theme.Title),
+		Text:       css(theme.Text),
+		Muted:      css(theme.Muted),
+		Accent:     css(theme.Accent),
+	}
+
+	var buf bytes.Buffer
+	if err := widgetTemplate.Execute(&buf, page); err != nil {
+		s.logger.Error("failed to render widget", "user", uri.String(), "error", err)
+		http.Error(w, "failed to render widget", http.StatusInternalServerError)
+		return
+	}
+
+	s.cacheControl(w)
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write(buf.Bytes())
+}
+
+// fetch returns the cached response of the endpoint for uri, requesting it when the cache expired.
+// Concurrent calls for the same response wait for a single request.
+func (s *server) fetch(ctx context.Context, endpoint string, uri wavy.UserURI, get func(ctx context.Context) (interface{}, error)) (interface{}, error) {
+	key := endpoint + "|" + uri.String()
+
+	s.mu.Lock()
+	e, ok := s.cache[key]
+	if !ok || (isDone(e) && s.now().Sub(e.fetched) >= s.ttl) {
+		if ok {
+			s.remove(e)
+		}
+		e = &entry{key: key, done: make(chan struct{})}
+		e.elem = s.lru.PushFront(e)
+		s.cache[key] = e
+		s.evict()
+		s.mu.Unlock()
+
+		s.load(e, endpoint, uri, get)
+	} else {
+		s.lru.MoveToFront(e.elem)
+		s.mu.Unlock()
+	}
+
+	select {
+	case <-e.done:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	return e.value, e.err
+}
+
+// load fills e with the response of get. e is done once load returns, also when get panics, so requests waiting for
+// e are not stuck.
+func (s *server) load(e *entry, endpoint string, uri wavy.UserURI, get func(ctx context.Context) (interface{}, error)) {
+	var (
+		value interface{}
+		err   error
+	)
+	defer func() {
+		if r := recover(); r != nil {
+			value, err = nil, fmt.Errorf("panic while requesting %s: %v", endpoint, r)
+		}
+		if err != nil {
+			s.logger.Warn("failed to request endpoint", "endpoint", endpoint, "user", uri.String(), "error", err)
+		}
+
+		s.mu.Lock()
+		e.fetched, e.value, e.err = s.now(), value, err
+		s.mu.Unlock()
+		close(e.done)
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), fetchTimeout)
+	defer cancel()
+	value, err = get(ctx)
+}
+
+// evict drops the least recently used responses while the cache holds more than s.maxEntries. Requests waiting
+// for a dropped response still get it. s.mu must be held.
+func (s *server) evict() {
+	for len(s.cache) > s.maxEntries {
+		s.remove(s.lru.Back().Value.(*entry))
+	}
+}
+
+// remove drops e from the cache. s.mu must be held.
+func (s *server) remove(e *entry) {
+	s.lru.Remove(e.elem)
+	delete(s.cache, e.key)
+}
+
+func isDone(e *entry) bool {
+	select {
+	case <-e.done:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,240 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/OGKevin/go-wavy/wavy"
+	"github.com/OGKevin/go-wavy/wavy/wavytest"
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var user = wavy.UserURI{Username: "OGKevin"}
+
+func setup(t *testing.T, users ...wavy.UserURI) (*httptest.Server, *server, *wavytest.Client, *time.Time) {
+	c := wavytest.NewClient()
+	c.Profiles[user.String()] = &wavy.GetUserProfileResponse{
+		URI:      user.String(),
+		Username: "OGKevin",
+		Profile:  wavy.Profile{URL: "https://wavy.fm/OGKevin", AvatarSmall: "https://cdn.wavy.fm/avatar-small.png"},
+	}
+	c.Stats[user.String()] = &wavy.GetHistroyStatsResponse{TotalListens: 120334, TotalArtists: 3120}
+	c.Current[user.String()] = &wavy.GetCurrentResponse{Item: wavy.CurrentPlayingItem{
+		Song:    wavy.Song{Name: "Never Gonna Give You Up", SourceURL: "https://open.spotify.com/track/1"},
+		Album:   wavy.Album{Name: "Whenever You Need Somebody", ArtURL: "https://cdn.example/art.jpg"},
+		Artists: []wavy.Artists{{Name: "Rick Astley"}},
+	}}
+
+	s := newServer(c, users, time.Minute, hclog.NewNullLogger())
+	now := time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+
+	srv := httptest.NewServer(s)
+	t.Cleanup(srv.Close)
+
+	return srv, s, c, &now
+}
+
+func get(t *testing.T, url string) (*http.Response, string) {
+	res, err := http.Get(url)
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	return res, string(body)
+}
+
+func Test_server_badges(t *testing.T) {
+	srv, _, c, _ := setup(t)
+
+	res, body := get(t, srv.URL+"/badge/wavyfm:user:username:OGKevin/now-playing.svg")
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, "image/svg+xml; charset=utf-8", res.Header.Get("Content-Type"))
+	assert.Equal(t, "public, max-age=60", res.Header.Get("Cache-Control"))
+	assert.Contains(t, body, "<title>now playing: Rick Astley - Never Gonna Give You Up</title>")
+	assert.Contains(t, body, colorSuccess)
+
+	_, body = get(t, srv.URL+"/badge/wavyfm:user:username:OGKevin/total-listens.svg")
+	assert.Contains(t, body, "<title>total listens: 120334</title>")
+
+	c.Current["wavyfm:user:username:idle"] = &wavy.GetCurrentResponse{}
+	_, body = get(t, srv.URL+"/badge/wavyfm:user:username:idle/now-playing.svg")
+	assert.Contains(t, body, "<title>now playing: nothing</title>")
+
+	res, body = get(t, srv.URL+"/badge/wavyfm:user:username:unknown/now-playing.svg")
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Contains(t, body, "<title>now playing: user not found</title>")
+}
+
+func Test_server_errors(t *testing.T) {
+	srv, _, c, _ := setup(t)
+	c.Err = errors.New("oauth2: cannot fetch token: client secret s3cr3t was rejected")
+
+	_, body := get(t, srv.URL+"/badge/wavyfm:user:username:OGKevin/total-listens.svg")
+	assert.Contains(t, body, "<title>total listens: unavailable</title>")
+	assert.NotContains(t, body, "s3cr3t")
+
+	res, body := get(t, srv.URL+"/widget/wavyfm:user:username:OGKevin.json")
+	assert.Equal(t, http.StatusBadGateway, res.StatusCode)
+	assert.JSONEq(t, `{"error": "bad gateway"}`, body)
+
+	res, body = get(t, srv.URL+"/widget/wavyfm:user:username:unknown.html")
+	assert.Equal(t, http.StatusBadGateway, res.StatusCode)
+	assert.NotContains(t, body, "s3cr3t")
+
+	c.Err = nil
+	res, _ = get(t, srv.URL+"/widget/wavyfm:user:username:missing.json")
+	assert.Equal(t, http.StatusNotFound, res.StatusCode)
+}
+
+func Test_server_widgetJSON(t *testing.T) {
+	srv, _, c, _ := setup(t)
+	delete(c.Stats, user.String())
+
+	res, body := get(t, srv.URL+"/widget/wavyfm:user:username:OGKevin.json")
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	assert.JSONEq(t, `{
+		"uri": "wavyfm:user:username:OGKevin",
+		"username": "OGKevin",
+		"url": "https://wavy.fm/OGKevin",
+		"avatar": "https://cdn.wavy.fm/avatar-small.png",
+		"now_playing": {
+			"song": "Never Gonna Give You Up",
+			"song_url": "https://open.spotify.com/track/1",
+			"artists": ["Rick Astley"],
+			"album": "Whenever You Need Somebody",
+			"art_url": "https://cdn.example/art.jpg",
+			"local": false
+		},
+		"stats": null
+	}`, body)
+}
+
+func Test_server_widgetHTML(t *testing.T) {
+	srv, _, c, now := setup(t)
+	c.Current[user.String()].Item.Song.Name = "<script>alert(1)</script>"
+
+	res, body := get(t, srv.URL+"/widget/wavyfm:user:username:OGKevin.html?theme=dark")
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, "text/html; charset=utf-8", res.Header.Get("Content-Type"))
+	assert.Contains(t, body, "background: #151515")
+	assert.Contains(t, body, "&lt;script&gt;alert(1)&lt;/script&gt;")
+	assert.Contains(t, body, `src="https://cdn.example/art.jpg"`)
+	assert.NotContains(t, body, "ZgotmplZ")
+
+	c.Current[user.String()] = &wavy.GetCurrentResponse{}
+	*now = now.Add(time.Minute)
+	_, body = get(t, srv.URL+"/widget/wavyfm:user:username:OGKevin.html")
+	assert.Contains(t, body, "Not playing")
+	assert.Contains(t, body, "120334 listens · 3120 artists")
+
+	res, _ = get(t, srv.URL+"/widget/wavyfm:user:username:OGKevin.html?theme=neon")
+	assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+}
+
+func Test_server_routes(t *testing.T) {
+	srv, _, _, _ := setup(t, user)
+
+	for _, path := range []string{
+		"/",
+		"/badge/wavyfm:user:username:OGKevin/unknown.svg",
+		"/badge/wavyfm:user:username/now-playing.svg",
+		"/widget/wavyfm:user:username:OGKevin.xml",
+		// Only the configured users are served.
+		"/widget/wavyfm:user:username:other.json",
+	} {
+		res, _ := get(t, srv.URL+path)
+		assert.Equal(t, http.StatusNotFound, res.StatusCode, path)
+	}
+
+	res, err := http.Post(srv.URL+"/widget/wavyfm:user:username:OGKevin.json", "application/json", strings.NewReader("{}"))
+	require.NoError(t, err)
+	res.Body.Close()
+	assert.Equal(t, http.StatusMethodNotAllowed, res.StatusCode)
+}
+
+func Test_server_cache(t *testing.T) {
+	srv, s, c, now := setup(t)
+	url := srv.URL + "/widget/wavyfm:user:username:OGKevin.json"
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			res, err := http.Get(url)
+			if err == nil {
+				res.Body.Close()
+			}
+		}()
+	}
+	wg.Wait()
+	get(t, srv.URL+"/badge/wavyfm:user:username:OGKevin/now-playing.svg")
+	assert.Equal(t, 1, c.Calls("GetProfile"))
+	assert.Equal(t, 1, c.Calls("GetCurrent"))
+	assert.Equal(t, 1, c.Calls("GetStats"))
+
+	// Failures are cached as well.
+	c.Err = errors.New("unavailable")
+	*now = now.Add(time.Minute)
+	get(t, url)
+	get(t, url)
+	assert.Equal(t, 2, c.Calls("GetProfile"))
+
+	s.mu.Lock()
+	assert.Len(t, s.cache, 3)
+	s.mu.Unlock()
+}
+
+func Test_server_fetchPanic(t *testing.T) {
+	_, s, _, _ := setup(t)
+
+	_, err := s.fetch(context.Background(), "profile", user, func(ctx context.Context) (interface{}, error) {
+		panic("boom")
+	})
+	assert.EqualError(t, err, "panic while requesting profile: boom")
+
+	// Requests for the same response are not stuck, they get the error until it expires.
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	_, err = s.fetch(ctx, "profile", user, func(ctx context.Context) (interface{}, error) {
+		return nil, nil
+	})
+	assert.EqualError(t, err, "panic while requesting profile: boom")
+}
+
+func Test_server_cacheLimit(t *testing.T) {
+	srv, s, c, _ := setup(t)
+	s.maxEntries = 2
+
+	other := wavy.UserURI{Username: "other"}
+	c.Stats[other.String()] = &wavy.GetHistroyStatsResponse{TotalListens: 1}
+
+	get(t, srv.URL+"/badge/wavyfm:user:username:OGKevin/total-listens.svg")
+	get(t, srv.URL+"/badge/wavyfm:user:username:OGKevin/now-playing.svg")
+	// Using the stats makes the current listen the least recently used response.
+	get(t, srv.URL+"/badge/wavyfm:user:username:OGKevin/total-listens.svg")
+	get(t, srv.URL+"/badge/wavyfm:user:username:other/total-listens.svg")
+
+	s.mu.Lock()
+	assert.Len(t, s.cache, 2)
+	assert.Equal(t, 2, s.lru.Len())
+	assert.Contains(t, s.cache, "stats|wavyfm:user:username:OGKevin")
+	assert.Contains(t, s.cache, "stats|wavyfm:user:username:other")
+	s.mu.Unlock()
+
+	get(t, srv.URL+"/badge/wavyfm:user:username:OGKevin/total-listens.svg")
+	get(t, srv.URL+"/badge/wavyfm:user:username:OGKevin/now-playing.svg")
+	assert.Equal(t, 2, c.Calls("GetStats"))
+	assert.Equal(t, 2, c.Calls("GetCurrent"))
+}
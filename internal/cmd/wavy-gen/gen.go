@@ -0,0 +1,497 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"strings"
+
+	"github.com/OGKevin/go-wavy/wavy/schema"
+)
+
+const refPrefix = "#/components/schemas/"
+
+// initialisms are the words of schema names written in upper case in Go names.
+var initialisms = map[string]bool{"id": true, "url": true, "uri": true}
+
+// generated holds the generated sources of a package.
+type generated struct {
+	code []byte
+	// test is only generated for package wavy.
+	test []byte
+}
+
+// generator generates the sources of a package from doc.
+type generator struct {
+	doc *schema.Document
+	pkg string
+	// source names the description in the header of generated files.
+	source string
+}
+
+func generate(doc *schema.Document, pkg, source string) (*generated, error) {
+	g := &generator{doc: doc, pkg: pkg, source: source}
+
+	switch pkg {
+	case "wavy":
+		code, err := g.wavy()
+		if err != nil {
+			return nil, err
+		}
+		test, err := g.wavyTest()
+		if err != nil {
+			return nil, err
+		}
+		return &generated{code: code, test: test}, nil
+	case "wavytest":
+		code, err := g.wavytest()
+		if err != nil {
+			return nil, err
+		}
+		return &generated{code: code}, nil
+	}
+	return nil, fmt.Errorf("unknown package %q", pkg)
+}
+
+func (g *generator) header(b *bytes.Buffer, imports ...string) {
+	fmt.Fprintf(b, "// Code generated by wavy-gen from %s. DO NOT EDIT.\n\n", g.source)
+	fmt.Fprintf(b, "package %s\n\n", g.pkg)
+	if len(imports) > 0 {
+		b.WriteString("import (\n")
+		for _, i := range imports {
+			// An empty import separates the standard library from other imports.
+			if i == "" {
+				b.WriteString("\n")
+				continue
+			}
+			fmt.Fprintf(b, "\t%q\n", i)
+		}
+		b.WriteString(")\n")
+	}
+}
+
+func (g *generator) format(b *bytes.Buffer) ([]byte, error) {
+	code, err := format.Source(b.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("failed to format generated %s code: %w\n%s", g.pkg, err, b.Bytes())
+	}
+	return code, nil
+}
+
+// operation is a GET operation of the description.
+type operation struct {
+	path string
+	*schema.Operation
+}
+
+func (g *generator) operations() []operation {
+	var ops []operation
+	for _, path := range g.doc.PathNames() {
+		if op := g.doc.Paths[path].Get; op != nil {
+			ops = append(ops, operation{path: path, Operation: op})
+		}
+	}
+	return ops
+}
+
+// wavy generates the models and request functions of package wavy.
+func (g *generator) wavy() ([]byte, error) {
+	var b bytes.Buffer
+	g.header(&b, "context", "net/http", "time")
+
+	for _, name := range g.doc.SchemaNames() {
+		if err := g.model(&b, name, g.doc.Components.Schemas[name]); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, op := range g.operations() {
+		if err := g.request(&b, op); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := g.services(&b); err != nil {
+		return nil, err
+	}
+
+	return g.format(&b)
+}
+
+// model generates the struct of the component schema name, unless it maps to an existing type.
+func (g *generator) model(b *bytes.Buffer, name string, s *schema.Schema) error {
+	if s.GoType[g.pkg] != "" || s.Type != "object" {
+		return nil
+	}
+
+	typeName := goTypeName(name, s)
+	fmt.Fprintf(b, "\n// %s is the %s schema of the wavy.fm api.\n", typeName, name)
+	if s.Description != "" {
+		fmt.Fprintf(b, "//\n// %s\n", s.Description)
+	}
+	fmt.Fprintf(b, "type %s struct {\n", typeName)
+
+	embedded := map[string]bool{}
+	if s.GoEmbed != "" {
+		embed, ok := g.doc.Components.Schemas[s.GoEmbed]
+		if !ok {
+			return fmt.Errorf("%s embeds unknown schema %q", name, s.GoEmbed)
+		}
+		for _, property := range embed.PropertyNames() {
+			embedded[property] = true
+		}
+		fmt.Fprintf(b, "\t%s\n", goTypeName(s.GoEmbed, embed))
+	}
+
+	for _, property := range s.PropertyNames() {
+		if embedded[property] {
+			continue
+		}
+
+		typ, err := g.goType(s.Properties[property])
+		if err != nil {
+			return fmt.Errorf("%s.%s: %w", name, property, err)
+		}
+		if description := s.Properties[property].Description; description != "" {
+			fmt.Fprintf(b, "\t// %s\n", description)
+		}
+		fmt.Fprintf(b, "\t%s %s `json:\"%s\"`\n", goName(property), typ, property)
+	}
+	b.WriteString("}\n")
+	return nil
+}
+
+// param is a path parameter of an operation.
+type param struct {
+	name   string
+	goType string
+	user   bool
+}
+
+func (g *generator) params(op operation) ([]param, error) {
+	var params []param
+	for _, p := range op.Parameters {
+		if p.In != "path" {
+			return nil, fmt.Errorf("%s: unsupported %s parameter %s", op.OperationID, p.In, p.Name)
+		}
+		typ := "string"
+		if p.Schema != nil && p.Schema.GoType[g.pkg] != "" {
+			typ = p.Schema.GoType[g.pkg]
+		}
+		if p.User && typ == "string" {
+			return nil, fmt.Errorf("%s: user parameter %s needs an x-go-type", op.OperationID, p.Name)
+		}
+		params = append(params, param{name: p.Name, goType: typ, user: p.User})
+	}
+	return params, nil
+}
+
+// value returns the expression converting the Go parameter to its string value.
+func (p param) value() string {
+	if p.goType == "string" {
+		return lowerGoName(p.name)
+	}
+	return lowerGoName(p.name) + ".String()"
+}
+
+// request generates the function requesting op in package wavy.
+func (g *generator) request(b *bytes.Buffer, op operation) error {
+	params, err := g.params(op)
+	if err != nil {
+		return err
+	}
+	res := op.ResponseSchema()
+	if res == nil {
+		return fmt.Errorf("%s: no JSON response", op.OperationID)
+	}
+	typ, err := g.goType(res)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op.OperationID, err)
+	}
+	if op.Name == "" {
+		return fmt.Errorf("%s: x-name is missing", op.OperationID)
+	}
+
+	args := []string{"ctx context.Context", "c *client", "logger Logger"}
+	format, values := op.path, []string{}
+	user := ""
+	for _, p := range params {
+		args = append(args, fmt.Sprintf("%s %s", lowerGoName(p.name), p.goType))
+		format = strings.Replace(format, "{"+p.name+"}", "%s", 1)
+		values = append(values, p.value())
+		if p.user {
+			user = p.value()
+		}
+	}
+	args = append(args, "o *callOptions")
+
+	fmt.Fprintf(b, "\n// %s requests GET %s.\n// %s\n", lowerFirst(op.OperationID), op.path, op.Summary)
+	fmt.Fprintf(b, "func %s(%s) (*%s, *http.Response, error) {\n", lowerFirst(op.OperationID), strings.Join(args, ", "), typ)
+	fmt.Fprintf(b, "\treturn getJSON[%s](ctx, c, logger, endpoint{\n", typ)
+	fmt.Fprintf(b, "\t\tname: %q,\n", op.Name)
+	if user != "" {
+		fmt.Fprintf(b, "\t\tuser: %s,\n", user)
+	}
+	fmt.Fprintf(b, "\t\troute: %q,\n", op.path)
+	if len(values) == 0 {
+		fmt.Fprintf(b, "\t\tpath: %q,\n", format)
+	} else {
+		fmt.Fprintf(b, "\t\tpath: pathf(%q, %s),\n", format, strings.Join(values, ", "))
+	}
+	b.WriteString("\t}, o)\n}\n")
+	return nil
+}
+
+// services generates the method set of every service interface of package wavy, one method per operation tagged
+// with the service. The interfaces of the services embed them.
+func (g *generator) services(b *bytes.Buffer) error {
+	tags := map[string]schema.Tag{}
+	methods := map[string][]string{}
+	for _, tag := range g.doc.Tags {
+		if tag.GoService != "" {
+			tags[tag.Name] = tag
+		}
+	}
+
+	for _, op := range g.operations() {
+		var service []schema.Tag
+		for _, name := range op.Tags {
+			if tag, ok := tags[name]; ok {
+				service = append(service, tag)
+			}
+		}
+		if len(service) != 1 {
+			return fmt.Errorf("%s: needs exactly one tag with x-go-service, got %d", op.OperationID, len(service))
+		}
+		if op.GoMethod == "" {
+			return fmt.Errorf("%s: x-go-method is missing", op.OperationID)
+		}
+
+		method, err := g.method(op, service[0])
+		if err != nil {
+			return err
+		}
+		methods[service[0].Name] = append(methods[service[0].Name], method)
+	}
+
+	for _, tag := range g.doc.Tags {
+		if tag.GoService == "" {
+			continue
+		}
+		fmt.Fprintf(b, "\n// %s is the method set of %s, generated from the operations tagged %s.\n", operationsInterface(tag), tag.GoService, tag.Name)
+		fmt.Fprintf(b, "type %s interface {\n", operationsInterface(tag))
+		for _, method := range methods[tag.Name] {
+			b.WriteString(method)
+		}
+		b.WriteString("}\n")
+	}
+	return nil
+}
+
+// method returns the method of the service interface of tag requesting op.
+func (g *generator) method(op operation, tag schema.Tag) (string, error) {
+	params, err := g.params(op)
+	if err != nil {
+		return "", err
+	}
+
+	typ := op.GoResponse
+	if typ == "" {
+		if typ, err = g.goType(op.ResponseSchema()); err != nil {
+			return "", fmt.Errorf("%s: %w", op.OperationID, err)
+		}
+	}
+
+	args := []string{"ctx context.Context"}
+	for _, p := range params {
+		if p.user && tag.BindUser {
+			continue
+		}
+		args = append(args, fmt.Sprintf("%s %s", lowerGoName(p.name), p.goType))
+	}
+	args = append(args, "opts ...CallOption")
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "\t// %s\n\t// %s", op.GoMethod, op.Summary)
+	if op.Description != "" {
+		fmt.Fprintf(&b, " %s", op.Description)
+	}
+	fmt.Fprintf(&b, "\n\t%s(%s) (*%s, error)\n", op.GoMethod, strings.Join(args, ", "), typ)
+	return b.String(), nil
+}
+
+// operationsInterface returns the name of the generated method set of the service of tag.
+func operationsInterface(tag schema.Tag) string {
+	return lowerFirst(tag.GoService) + "Operations"
+}
+
+// wavyTest generates the response model of every operation for the contract tests of package wavy.
+func (g *generator) wavyTest() ([]byte, error) {
+	var b bytes.Buffer
+	g.header(&b)
+
+	b.WriteString("\n// contractModels returns a new response model of each operation of the api description, keyed by operation id.\n")
+	b.WriteString("var contractModels = map[string]func() interface{}{\n")
+	for _, op := range g.operations() {
+		typ, err := g.goType(op.ResponseSchema())
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op.OperationID, err)
+		}
+		fmt.Fprintf(&b, "\t%q: func() interface{} { return new(%s) },\n", op.OperationID, typ)
+	}
+	b.WriteString("}\n")
+
+	return g.format(&b)
+}
+
+// wavytest generates the Operations interface and the routes of the fake api of package wavytest.
+func (g *generator) wavytest() ([]byte, error) {
+	var b bytes.Buffer
+	g.header(&b, "context", "net/http", "", "github.com/OGKevin/go-wavy/wavy")
+
+	var routes bytes.Buffer
+	b.WriteString("\n// Operations answers the operations of the wavy.fm api for NewHandler.\n")
+	b.WriteString("type Operations interface {\n")
+	for _, op := range g.operations() {
+		params, err := g.params(op)
+		if err != nil {
+			return nil, err
+		}
+		typ, err := g.goType(op.ResponseSchema())
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op.OperationID, err)
+		}
+		if g.isStruct(op.ResponseSchema()) {
+			typ = "*" + typ
+		}
+
+		args, values := []string{"ctx context.Context"}, []string{"r.Context()"}
+		fmt.Fprintf(&routes, "\t\t{\n\t\t\ttemplate: %q,\n", op.path)
+		routes.WriteString("\t\t\tserve: func(r *http.Request, params map[string]string) (interface{}, error) {\n")
+		for _, p := range params {
+			args = append(args, fmt.Sprintf("%s %s", lowerGoName(p.name), p.goType))
+			values = append(values, lowerGoName(p.name))
+			if p.user {
+				fmt.Fprintf(&routes, "\t\t\t\t%s, err := parseUserURI(params[%q])\n", lowerGoName(p.name), p.name)
+				routes.WriteString("\t\t\t\tif err != nil {\n\t\t\t\t\treturn nil, err\n\t\t\t\t}\n")
+			} else {
+				fmt.Fprintf(&routes, "\t\t\t\t%s := params[%q]\n", lowerGoName(p.name), p.name)
+			}
+		}
+		fmt.Fprintf(&routes, "\t\t\t\treturn ops.%s(%s)\n\t\t\t},\n\t\t},\n", upperFirst(op.OperationID), strings.Join(values, ", "))
+
+		fmt.Fprintf(&b, "\t// %s %s\n", upperFirst(op.OperationID), lowerFirst(op.Summary))
+		fmt.Fprintf(&b, "\t%s(%s) (%s, error)\n", upperFirst(op.OperationID), strings.Join(args, ", "), typ)
+	}
+	b.WriteString("}\n")
+
+	b.WriteString("\n// routes returns the routes of the api answered by ops.\n")
+	b.WriteString("func routes(ops Operations) []route {\n\treturn []route{\n")
+	b.Write(routes.Bytes())
+	b.WriteString("\t}\n}\n")
+
+	return g.format(&b)
+}
+
+// goType returns the Go type of s in the generated package.
+func (g *generator) goType(s *schema.Schema) (string, error) {
+	if s.Ref != "" {
+		name := strings.TrimPrefix(s.Ref, refPrefix)
+		component, ok := g.doc.Components.Schemas[name]
+		if !ok {
+			return "", fmt.Errorf("unknown reference %q", s.Ref)
+		}
+		if typ := component.GoType[g.pkg]; typ != "" {
+			return typ, nil
+		}
+		if component.Type == "array" {
+			item, err := g.goType(component.Items)
+			return "[]" + item, err
+		}
+		return g.qualify(goTypeName(name, component)), nil
+	}
+	if typ := s.GoType[g.pkg]; typ != "" {
+		return typ, nil
+	}
+
+	switch s.Type {
+	case "string":
+		if s.Format == "date-time" {
+			return "time.Time", nil
+		}
+		return "string", nil
+	case "integer":
+		if s.Format == "int64" {
+			return "int64", nil
+		}
+		return "int", nil
+	case "number":
+		return "float64", nil
+	case "boolean":
+		return "bool", nil
+	case "array":
+		if s.Items == nil {
+			return "", fmt.Errorf("array without items")
+		}
+		item, err := g.goType(s.Items)
+		return "[]" + item, err
+	}
+	return "", fmt.Errorf("unsupported schema of type %q, use a component", s.Type)
+}
+
+// isStruct reports whether s refers to a generated struct.
+func (g *generator) isStruct(s *schema.Schema) bool {
+	component, ok := g.doc.Components.Schemas[strings.TrimPrefix(s.Ref, refPrefix)]
+	return ok && s.Ref != "" && component.Type == "object" && component.GoType[g.pkg] == ""
+}
+
+// qualify refers to the type name of package wavy from the generated package.
+func (g *generator) qualify(name string) string {
+	if g.pkg == "wavy" {
+		return name
+	}
+	return "wavy." + name
+}
+
+func goTypeName(name string, s *schema.Schema) string {
+	if s.GoName != "" {
+		return s.GoName
+	}
+	return name
+}
+
+// goName converts a snake case schema name to an exported Go name, e.g. source_url to SourceURL.
+func goName(name string) string {
+	var b strings.Builder
+	for _, word := range strings.Split(name, "_") {
+		if initialisms[word] {
+			b.WriteString(strings.ToUpper(word))
+			continue
+		}
+		b.WriteString(upperFirst(word))
+	}
+	return b.String()
+}
+
+// lowerGoName converts a snake case schema name to an unexported Go name, e.g. user_uri to userURI.
+func lowerGoName(name string) string {
+	words := strings.SplitN(name, "_", 2)
+	if len(words) == 1 {
+		return words[0]
+	}
+	return words[0] + goName(words[1])
+}
+
+func upperFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+func lowerFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToLower(s[:1]) + s[1:]
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/OGKevin/go-wavy/wavy/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test_generate_upToDate fails when the checked in code was not regenerated after the description changed.
+func Test_generate_upToDate(t *testing.T) {
+	root := filepath.Join("..", "..", "..", "wavy")
+	b, err := ioutil.ReadFile(filepath.Join(root, "schema", "openapi-v1beta.json"))
+	require.NoError(t, err)
+	doc, err := schema.Parse(b)
+	require.NoError(t, err)
+
+	tests := []struct {
+		pkg  string
+		code string
+		test string
+	}{
+		{pkg: "wavy", code: filepath.Join(root, "api.gen.go"), test: filepath.Join(root, "api.gen_test.go")},
+		{pkg: "wavytest", code: filepath.Join(root, "wavytest", "handler.gen.go")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pkg, func(t *testing.T) {
+			files, err := generate(doc, tt.pkg, "openapi-v1beta.json")
+			require.NoError(t, err)
+
+			want, err := ioutil.ReadFile(tt.code)
+			require.NoError(t, err)
+			assert.Equal(t, string(want), string(files.code), "run go generate ./wavy/...")
+
+			if tt.test != "" {
+				want, err := ioutil.ReadFile(tt.test)
+				require.NoError(t, err)
+				assert.Equal(t, string(want), string(files.test), "run go generate ./wavy/...")
+			}
+		})
+	}
+}
+
+func Test_generate(t *testing.T) {
+	doc, err := schema.Parse([]byte(`{
+		"info": {"version": "v1beta"},
+		"tags": [{"name": "playlists", "x-go-service": "PlaylistService", "x-bind-user": true}],
+		"paths": {
+			"/users/{user_uri}/playlists/{playlist_id}": {"get": {
+				"operationId": "getPlaylist",
+				"x-name": "playlist",
+				"x-go-method": "GetPlaylist",
+				"summary": "Retrieves a playlist.",
+				"description": "Private playlists are not returned.",
+				"tags": ["playlists"],
+				"parameters": [
+					{"name": "user_uri", "in": "path", "x-user": true, "schema": {"type": "string", "x-go-type": {"wavy": "UserURI", "wavytest": "wavy.UserURI"}}},
+					{"name": "playlist_id", "in": "path", "schema": {"type": "string"}}
+				],
+				"responses": {"200": {"content": {"application/json": {"schema": {"$ref": "#/components/schemas/Playlist"}}}}}
+			}}
+		},
+		"components": {"schemas": {
+			"Playlist": {"type": "object", "description": "A playlist.", "properties": {
+				"cover_url": {"type": "string"},
+				"track_count": {"type": "integer", "format": "int64", "description": "Amount of tracks."},
+				"updated_at": {"type": "string", "format": "date-time"}
+			}}
+		}}
+	}`))
+	require.NoError(t, err)
+
+	files, err := generate(doc, "wavy", "test.json")
+	require.NoError(t, err)
+	code := string(files.code)
+	assert.Contains(t, code, "// Code generated by wavy-gen from test.json. DO NOT EDIT.")
+	assert.Contains(t, code, "type Playlist struct {\n\tCoverURL string `json:\"cover_url\"`\n\t// Amount of tracks.\n")
+	assert.Contains(t, code, "UpdatedAt  time.Time `json:\"updated_at\"`")
+	assert.Contains(t, code, "func getPlaylist(ctx context.Context, c *client, logger Logger, userURI UserURI, playlistID string, o *callOptions) (*Playlist, *http.Response, error)")
+	assert.Contains(t, code, `path:  pathf("/users/%s/playlists/%s", userURI.String(), playlistID),`)
+	assert.Contains(t, code, `route: "/users/{user_uri}/playlists/{playlist_id}",`)
+	assert.Contains(t, code, "type playlistServiceOperations interface {\n\t// GetPlaylist\n\t// Retrieves a playlist. Private playlists are not returned.\n")
+	assert.Contains(t, code, "GetPlaylist(ctx context.Context, playlistID string, opts ...CallOption) (*Playlist, error)")
+	assert.Contains(t, string(files.test), `"getPlaylist": func() interface{} { return new(Playlist) },`)
+
+	files, err = generate(doc, "wavytest", "test.json")
+	require.NoError(t, err)
+	assert.Contains(t, string(files.code), "GetPlaylist(ctx context.Context, userURI wavy.UserURI, playlistID string) (*wavy.Playlist, error)")
+
+	_, err = generate(doc, "other", "test.json")
+	assert.Error(t, err)
+}
+
+func Test_generate_errors(t *testing.T) {
+	tests := []struct {
+		name string
+		doc  string
+		err  string
+	}{
+		{
+			name: "missing name",
+			doc:  `{"paths": {"/a": {"get": {"operationId": "getA", "responses": {"200": {"content": {"application/json": {"schema": {"type": "integer"}}}}}}}}}`,
+			err:  "x-name is missing",
+		},
+		{
+			name: "inline object",
+			doc:  `{"paths": {"/a": {"get": {"operationId": "getA", "x-name": "a", "responses": {"200": {"content": {"application/json": {"schema": {"type": "object"}}}}}}}}}`,
+			err:  "use a component",
+		},
+		{
+			name: "query parameter",
+			doc:  `{"paths": {"/a": {"get": {"operationId": "getA", "x-name": "a", "parameters": [{"name": "q", "in": "query"}]}}}}`,
+			err:  "unsupported query parameter",
+		},
+		{
+			name: "missing service",
+			doc:  `{"paths": {"/a": {"get": {"operationId": "getA", "x-name": "a", "x-go-method": "GetA", "responses": {"200": {"content": {"application/json": {"schema": {"type": "integer"}}}}}}}}}`,
+			err:  "needs exactly one tag with x-go-service",
+		},
+		{
+			name: "missing method",
+			doc:  `{"tags": [{"name": "a", "x-go-service": "AService"}], "paths": {"/a": {"get": {"operationId": "getA", "x-name": "a", "tags": ["a"], "responses": {"200": {"content": {"application/json": {"schema": {"type": "integer"}}}}}}}}}`,
+			err:  "x-go-method is missing",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := schema.Parse([]byte(tt.doc))
+			require.NoError(t, err)
+
+			_, err = generate(doc, "wavy", "test.json")
+			require.Error(t, err)
+			assert.True(t, strings.Contains(err.Error(), tt.err), "got %v", err)
+		})
+	}
+}
+
+func Test_goName(t *testing.T) {
+	assert.Equal(t, "SourceURL", goName("source_url"))
+	assert.Equal(t, "PlayID", goName("play_id"))
+	assert.Equal(t, "AvatarSmall", goName("avatar_small"))
+	assert.Equal(t, "userURI", lowerGoName("user_uri"))
+	assert.Equal(t, "date", lowerGoName("date"))
+}
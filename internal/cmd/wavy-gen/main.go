@@ -0,0 +1,56 @@
+// Command wavy-gen generates the response models, request functions and service method sets of the wavy package,
+// and the fake api handlers of the wavytest package, from the OpenAPI description in wavy/schema. It is run by go
+// generate:
+//
+//	go generate ./wavy/...
+//
+// Every operation is a method of the service interface named by the x-go-service of its tag, e.g. MetricsService.
+// The service interfaces embed the generated method sets, and their implementations are written by hand on top of
+// the generated request functions, as they bind parameters and wrap responses. To add an endpoint, describe it in
+// wavy/schema/openapi-v1beta.json, run go generate, and implement the new method of the service.
+package main
+
+import (
+	"flag"
+	"io/ioutil"
+	"log"
+	"path/filepath"
+
+	"github.com/OGKevin/go-wavy/wavy/schema"
+)
+
+func main() {
+	schemaPath := flag.String("schema", "", "path of the OpenAPI description")
+	pkg := flag.String("package", "", "package to generate, wavy or wavytest")
+	out := flag.String("out", "", "path of the generated file")
+	testOut := flag.String("test-out", "", "path of the generated test file, only for package wavy")
+	flag.Parse()
+
+	if *schemaPath == "" || *pkg == "" || *out == "" {
+		flag.Usage()
+		log.Fatal("wavy-gen: -schema, -package and -out are required")
+	}
+
+	b, err := ioutil.ReadFile(*schemaPath)
+	if err != nil {
+		log.Fatalf("wavy-gen: failed to read schema: %s", err)
+	}
+	doc, err := schema.Parse(b)
+	if err != nil {
+		log.Fatalf("wavy-gen: %s", err)
+	}
+
+	files, err := generate(doc, *pkg, filepath.Base(*schemaPath))
+	if err != nil {
+		log.Fatalf("wavy-gen: %s", err)
+	}
+
+	if err := ioutil.WriteFile(*out, files.code, 0644); err != nil {
+		log.Fatalf("wavy-gen: failed to write %s: %s", *out, err)
+	}
+	if *testOut != "" && files.test != nil {
+		if err := ioutil.WriteFile(*testOut, files.test, 0644); err != nil {
+			log.Fatalf("wavy-gen: failed to write %s: %s", *testOut, err)
+		}
+	}
+}
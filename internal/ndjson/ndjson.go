@@ -0,0 +1,94 @@
+// Package ndjson persists values as newline-delimited JSON files, one value per line.
+package ndjson
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// maxLine is the size of the longest line Read accepts.
+const maxLine = 1024 * 1024
+
+// Read calls fn with every value of the file at path, in order. Empty lines are skipped, a file which does not exist
+// holds no values.
+func Read[T any](path string, fn func(T)) error {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), maxLine)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var v T
+		if err := json.Unmarshal(scanner.Bytes(), &v); err != nil {
+			return fmt.Errorf("failed to parse line %d: %w", line, err)
+		}
+		fn(v)
+	}
+
+	return scanner.Err()
+}
+
+// Append appends values to the file at path, creating it if it does not exist yet. The file is synced before
+// Append returns.
+func Append[T any](path string, values ...T) error {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+
+	if err := encode(file, values); err != nil {
+		file.Close()
+		return err
+	}
+
+	return file.Close()
+}
+
+// Rewrite replaces the file at path with one holding values. The file is replaced atomically, so it holds either
+// the previous or the new values when Rewrite fails.
+func Rewrite[T any](path string, values []T) error {
+	tmp, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path))
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := encode(tmp, values); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+func encode[T any](file *os.File, values []T) error {
+	w := bufio.NewWriter(file)
+	enc := json.NewEncoder(w)
+	for _, v := range values {
+		if err := enc.Encode(v); err != nil {
+			return err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	return file.Sync()
+}
@@ -0,0 +1,67 @@
+package ndjson
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type value struct {
+	N int `json:"n"`
+}
+
+func read(t *testing.T, path string) []value {
+	var values []value
+	require.NoError(t, Read(path, func(v value) {
+		values = append(values, v)
+	}))
+	return values
+}
+
+func TestAppend(t *testing.T) {
+	dir, err := ioutil.TempDir("", "go-wavy-ndjson")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "values.ndjson")
+
+	assert.Empty(t, read(t, path), "missing file")
+
+	require.NoError(t, Append(path, value{N: 1}))
+	require.NoError(t, Append(path, value{N: 2}, value{N: 3}))
+	assert.Equal(t, []value{{N: 1}, {N: 2}, {N: 3}}, read(t, path))
+
+	raw, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "{\"n\":1}\n{\"n\":2}\n{\"n\":3}\n", string(raw))
+}
+
+func TestRewrite(t *testing.T) {
+	dir, err := ioutil.TempDir("", "go-wavy-ndjson")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "values.ndjson")
+
+	require.NoError(t, Append(path, value{N: 1}, value{N: 2}))
+	require.NoError(t, Rewrite(path, []value{{N: 3}}))
+	assert.Equal(t, []value{{N: 3}}, read(t, path))
+
+	files, err := ioutil.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, files, 1, "temporary file is removed")
+}
+
+func TestRead_errors(t *testing.T) {
+	dir, err := ioutil.TempDir("", "go-wavy-ndjson")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "values.ndjson")
+
+	require.NoError(t, ioutil.WriteFile(path, []byte("{\"n\":1}\n\n{\"n\":\n"), 0600))
+	err = Read(path, func(value) {})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to parse line 3")
+}
@@ -0,0 +1,23 @@
+// Package poll runs a function periodically.
+package poll
+
+import (
+	"context"
+	"time"
+)
+
+// Every calls fn right away and then every interval until ctx is done. fn is never called concurrently.
+func Every(ctx context.Context, interval time.Duration, fn func(ctx context.Context)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		fn(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
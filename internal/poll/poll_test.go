@@ -0,0 +1,28 @@
+package poll
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEvery(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	calls := 0
+	Every(ctx, time.Millisecond, func(ctx context.Context) {
+		calls++
+		if calls == 3 {
+			cancel()
+		}
+	})
+	assert.Equal(t, 3, calls)
+
+	// A done context still gets a single call.
+	Every(ctx, time.Hour, func(ctx context.Context) {
+		calls++
+	})
+	assert.Equal(t, 4, calls)
+}
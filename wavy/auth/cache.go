@@ -0,0 +1,121 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"golang.org/x/oauth2"
+)
+
+// ErrCacheMiss is returned by TokenCache.Load when no token is cached for a key.
+var ErrCacheMiss = errors.New("auth: no token cached")
+
+// TokenCache shares tokens between token sources, possibly across processes.
+// Keys identify the credentials a token belongs to.
+type TokenCache interface {
+	// Load returns the token cached for key, or ErrCacheMiss.
+	Load(ctx context.Context, key string) (*oauth2.Token, error)
+	// Store caches token for key, replacing the previous one.
+	Store(ctx context.Context, key string, token *oauth2.Token) error
+	// Lock blocks until the caller is the only user of the cache fetching a token for key, or ctx is done.
+	// The returned func releases the lock.
+	Lock(ctx context.Context, key string) (unlock func(), err error)
+}
+
+// DefaultRefreshBefore is how long before its expiry a cached token is replaced by CachedTokenSource.
+const DefaultRefreshBefore = time.Minute
+
+// CacheOptions configures CachedTokenSource.
+type CacheOptions struct {
+	// RefreshBefore defaults to DefaultRefreshBefore.
+	RefreshBefore time.Duration
+	// Logger receives cache failures, which do not fail Token. Both hclog and wavy loggers satisfy it.
+	// Defaults to discarding them.
+	Logger interface {
+		Warn(msg string, args ...interface{})
+	}
+}
+
+// CachedTokenSource returns a source which reuses the tokens cached for key, and fetches a token from src once
+// the cached one is about to expire. The fetch is done under the lock of key, so concurrent users of cache
+// fetch a single token. src must fetch a new token on every call, e.g. clientcredentials.Config.Token.
+func CachedTokenSource(ctx context.Context, cache TokenCache, key string, src oauth2.TokenSource, opts CacheOptions) oauth2.TokenSource {
+	if opts.RefreshBefore == 0 {
+		opts.RefreshBefore = DefaultRefreshBefore
+	}
+	if opts.Logger == nil {
+		opts.Logger = hclog.NewNullLogger()
+	}
+
+	return &cachedSource{ctx: ctx, cache: cache, key: key, src: src, opts: opts}
+}
+
+type cachedSource struct {
+	ctx   context.Context
+	cache TokenCache
+	key   string
+	src   oauth2.TokenSource
+	opts  CacheOptions
+
+	mu   sync.Mutex
+	last *oauth2.Token
+}
+
+func (s *cachedSource) Token() (*oauth2.Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.fresh(s.last) {
+		return s.last, nil
+	}
+	if token := s.load(); s.fresh(token) {
+		s.last = token
+		return token, nil
+	}
+
+	unlock, err := s.cache.Lock(s.ctx, s.key)
+	if err != nil {
+		return nil, fmt.Errorf("auth: failed to lock token cache: %w", err)
+	}
+	defer unlock()
+
+	// Another user of the cache may have fetched a token while we waited for the lock.
+	if token := s.load(); s.fresh(token) {
+		s.last = token
+		return token, nil
+	}
+
+	token, err := s.src.Token()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.cache.Store(s.ctx, s.key, token); err != nil {
+		s.opts.Logger.Warn("failed to store token in cache", "error", err)
+	}
+
+	s.last = token
+	return token, nil
+}
+
+func (s *cachedSource) load() *oauth2.Token {
+	token, err := s.cache.Load(s.ctx, s.key)
+	if err != nil {
+		if !errors.Is(err, ErrCacheMiss) {
+			s.opts.Logger.Warn("failed to load token from cache", "error", err)
+		}
+		return nil
+	}
+	return token
+}
+
+// fresh reports whether token can be used without refreshing it. Tokens without an expiry never expire.
+func (s *cachedSource) fresh(token *oauth2.Token) bool {
+	if token == nil || token.AccessToken == "" {
+		return false
+	}
+	return token.Expiry.IsZero() || time.Until(token.Expiry) > s.opts.RefreshBefore
+}
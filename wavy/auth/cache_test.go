@@ -0,0 +1,137 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+)
+
+// countingSource returns a new token valid for ttl on every call.
+type countingSource struct {
+	ttl   time.Duration
+	calls int32
+	err   error
+}
+
+func (s *countingSource) Token() (*oauth2.Token, error) {
+	n := atomic.AddInt32(&s.calls, 1)
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &oauth2.Token{AccessToken: fmt.Sprintf("token-%d", n), Expiry: time.Now().Add(s.ttl)}, nil
+}
+
+// testCache runs the behaviour every TokenCache shares against cache.
+func testCache(t *testing.T, cache TokenCache) {
+	ctx := context.Background()
+
+	t.Run("miss", func(t *testing.T) {
+		_, err := cache.Load(ctx, "missing")
+		assert.True(t, errors.Is(err, ErrCacheMiss))
+	})
+
+	t.Run("store", func(t *testing.T) {
+		expiry := time.Now().Add(time.Hour).Round(time.Second)
+		require.NoError(t, cache.Store(ctx, "key", &oauth2.Token{AccessToken: "a", Expiry: expiry}))
+		require.NoError(t, cache.Store(ctx, "key", &oauth2.Token{AccessToken: "b", Expiry: expiry}))
+		require.NoError(t, cache.Store(ctx, "other key", &oauth2.Token{AccessToken: "c"}))
+
+		got, err := cache.Load(ctx, "key")
+		require.NoError(t, err)
+		assert.Equal(t, "b", got.AccessToken)
+		assert.True(t, expiry.Equal(got.Expiry))
+	})
+
+	t.Run("lock", func(t *testing.T) {
+		unlock, err := cache.Lock(ctx, "key")
+		require.NoError(t, err)
+
+		timeout, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+		defer cancel()
+		_, err = cache.Lock(timeout, "key")
+		assert.True(t, errors.Is(err, context.DeadlineExceeded))
+
+		otherUnlock, err := cache.Lock(ctx, "other key")
+		require.NoError(t, err)
+		otherUnlock()
+
+		unlock()
+		unlock, err = cache.Lock(ctx, "key")
+		require.NoError(t, err)
+		unlock()
+	})
+}
+
+func TestCachedTokenSource(t *testing.T) {
+	cache := NewMemoryCache()
+	src := &countingSource{ttl: time.Hour}
+
+	a := CachedTokenSource(context.Background(), cache, "key", src, CacheOptions{})
+	b := CachedTokenSource(context.Background(), cache, "key", src, CacheOptions{})
+
+	for _, s := range []oauth2.TokenSource{a, a, b} {
+		token, err := s.Token()
+		require.NoError(t, err)
+		assert.Equal(t, "token-1", token.AccessToken)
+	}
+	assert.Equal(t, int32(1), src.calls)
+}
+
+func TestCachedTokenSource_refreshBefore(t *testing.T) {
+	src := &countingSource{ttl: 30 * time.Second}
+	s := CachedTokenSource(context.Background(), NewMemoryCache(), "key", src, CacheOptions{})
+
+	// Tokens expiring within DefaultRefreshBefore are replaced right away.
+	_, err := s.Token()
+	require.NoError(t, err)
+	token, err := s.Token()
+	require.NoError(t, err)
+	assert.Equal(t, "token-2", token.AccessToken)
+
+	s = CachedTokenSource(context.Background(), NewMemoryCache(), "key", src, CacheOptions{RefreshBefore: time.Second})
+	first, err := s.Token()
+	require.NoError(t, err)
+	second, err := s.Token()
+	require.NoError(t, err)
+	assert.Equal(t, first.AccessToken, second.AccessToken)
+}
+
+func TestCachedTokenSource_concurrent(t *testing.T) {
+	cache, err := NewFileCache(t.TempDir())
+	require.NoError(t, err)
+	src := &countingSource{ttl: time.Hour}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// Every goroutine acts as a separate process, sharing only the cache.
+			_, err := CachedTokenSource(context.Background(), cache, "key", src, CacheOptions{}).Token()
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(1), src.calls)
+}
+
+func TestCachedTokenSource_error(t *testing.T) {
+	fetchErr := errors.New("token endpoint down")
+	cache := NewMemoryCache()
+	s := CachedTokenSource(context.Background(), cache, "key", &countingSource{err: fetchErr}, CacheOptions{})
+
+	_, err := s.Token()
+	assert.True(t, errors.Is(err, fetchErr))
+
+	_, err = cache.Load(context.Background(), "key")
+	assert.True(t, errors.Is(err, ErrCacheMiss))
+}
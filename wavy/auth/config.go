@@ -0,0 +1,62 @@
+// Package auth obtains tokens to act on behalf of a wavy.fm user, with the OAuth2 authorization code flow and PKCE.
+//
+// CLIs use Authorize, which receives the redirect on a loopback address. Web applications create a Flow per
+// login, redirect the user to Flow.AuthCodeURL and pass the query of the redirect to Flow.HandleRedirect.
+// The resulting token is refreshed by TokenSource and used with wavy.NewUserClient:
+//
+//	token, err := auth.Authorize(ctx, conf, auth.PrintURL(os.Stderr))
+//	...
+//	c := wavy.NewUserClient(ctx, logger, auth.TokenSource(ctx, conf, token, nil))
+package auth
+
+import (
+	"golang.org/x/oauth2"
+)
+
+const (
+	// DefaultAuthURL is the wavy.fm page where users grant access to an application.
+	DefaultAuthURL = "https://wavy.fm/oauth/authorize"
+	// DefaultTokenURL is the wavy.fm token endpoint, shared with the client credentials grant of wavy.NewClient.
+	DefaultTokenURL = "https://wavy.fm/api/v1beta/token"
+)
+
+// Config describes the wavy.fm application requesting access.
+type Config struct {
+	ClientID string
+	// ClientSecret is optional, public clients such as CLIs rely on PKCE alone.
+	ClientSecret string
+	// RedirectURL is where wavy.fm sends the user after granting access. It must be registered with the application.
+	// Authorize defaults to a loopback address on a random port.
+	RedirectURL string
+	Scopes      []string
+
+	// AuthURL and TokenURL default to DefaultAuthURL and DefaultTokenURL.
+	AuthURL  string
+	TokenURL string
+}
+
+func (c Config) oauth2() *oauth2.Config {
+	conf := &oauth2.Config{
+		ClientID:     c.ClientID,
+		ClientSecret: c.ClientSecret,
+		RedirectURL:  c.RedirectURL,
+		Scopes:       c.Scopes,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:   DefaultAuthURL,
+			TokenURL:  DefaultTokenURL,
+			AuthStyle: oauth2.AuthStyleInHeader,
+		},
+	}
+
+	if c.AuthURL != "" {
+		conf.Endpoint.AuthURL = c.AuthURL
+	}
+	if c.TokenURL != "" {
+		conf.Endpoint.TokenURL = c.TokenURL
+	}
+	if c.ClientSecret == "" {
+		conf.Endpoint.AuthStyle = oauth2.AuthStyleInParams
+	}
+
+	return conf
+}
@@ -0,0 +1,111 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+const (
+	// lockRetry is how often a held lock is retried.
+	lockRetry = 50 * time.Millisecond
+	// staleLock is the age after which a lock file is considered left behind by a crashed process.
+	staleLock = 30 * time.Second
+)
+
+type fileCache struct {
+	dir string
+}
+
+// NewFileCache returns a TokenCache which keeps one file per key in dir, so tokens are shared by every process
+// using dir. Files are only readable by the current user. Locks are lock files next to the tokens, which work
+// on every platform and network file systems.
+func NewFileCache(dir string) (TokenCache, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("auth: failed to create token cache dir: %w", err)
+	}
+	return &fileCache{dir: dir}, nil
+}
+
+// path returns the file of key. Keys are hashed, as they may hold characters which are not valid in file names.
+func (f *fileCache) path(key, ext string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(f.dir, hex.EncodeToString(sum[:])+ext)
+}
+
+func (f *fileCache) Load(_ context.Context, key string) (*oauth2.Token, error) {
+	b, err := ioutil.ReadFile(f.path(key, ".json"))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrCacheMiss
+	}
+	if err != nil {
+		return nil, fmt.Errorf("auth: failed to read cached token: %w", err)
+	}
+
+	var token oauth2.Token
+	if err := json.Unmarshal(b, &token); err != nil {
+		return nil, fmt.Errorf("auth: failed to parse cached token: %w", err)
+	}
+	return &token, nil
+}
+
+func (f *fileCache) Store(_ context.Context, key string, token *oauth2.Token) error {
+	b, err := json.Marshal(token)
+	if err != nil {
+		return fmt.Errorf("auth: failed to encode token: %w", err)
+	}
+
+	// Write to a temporary file first, so readers never see a partially written token.
+	tmp, err := ioutil.TempFile(f.dir, "token-*.tmp")
+	if err != nil {
+		return fmt.Errorf("auth: failed to create token file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return fmt.Errorf("auth: failed to write token file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("auth: failed to write token file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), f.path(key, ".json")); err != nil {
+		return fmt.Errorf("auth: failed to replace token file: %w", err)
+	}
+	return nil
+}
+
+func (f *fileCache) Lock(ctx context.Context, key string) (func(), error) {
+	path := f.path(key, ".lock")
+
+	for {
+		lock, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+		if err == nil {
+			lock.Close()
+			return func() { os.Remove(path) }, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, fmt.Errorf("auth: failed to create lock file: %w", err)
+		}
+
+		if info, err := os.Stat(path); err == nil && time.Since(info.ModTime()) > staleLock {
+			os.Remove(path)
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(lockRetry):
+		}
+	}
+}
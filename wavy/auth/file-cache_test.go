@@ -0,0 +1,58 @@
+package auth
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+)
+
+func TestFileCache(t *testing.T) {
+	cache, err := NewFileCache(filepath.Join(t.TempDir(), "tokens"))
+	require.NoError(t, err)
+
+	testCache(t, cache)
+}
+
+func TestFileCache_shared(t *testing.T) {
+	dir := t.TempDir()
+
+	a, err := NewFileCache(dir)
+	require.NoError(t, err)
+	require.NoError(t, a.Store(context.Background(), "key", &oauth2.Token{AccessToken: "a"}))
+
+	b, err := NewFileCache(dir)
+	require.NoError(t, err)
+	got, err := b.Load(context.Background(), "key")
+	require.NoError(t, err)
+	assert.Equal(t, "a", got.AccessToken)
+
+	info, err := os.Stat(asFileCache(a).path("key", ".json"))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+}
+
+func TestFileCache_staleLock(t *testing.T) {
+	c, err := NewFileCache(t.TempDir())
+	require.NoError(t, err)
+
+	path := asFileCache(c).path("key", ".lock")
+	require.NoError(t, os.WriteFile(path, nil, 0600))
+	old := time.Now().Add(-2 * staleLock)
+	require.NoError(t, os.Chtimes(path, old, old))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	unlock, err := c.Lock(ctx, "key")
+	require.NoError(t, err)
+	unlock()
+}
+
+func asFileCache(c TokenCache) *fileCache {
+	return c.(*fileCache)
+}
@@ -0,0 +1,106 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+
+	"golang.org/x/oauth2"
+)
+
+// ErrStateMismatch is returned when the state of a redirect does not belong to the Flow,
+// e.g. because the redirect was forged.
+var ErrStateMismatch = errors.New("auth: state of the redirect does not match the flow")
+
+// AuthorizationError is returned when wavy.fm redirects with an error instead of a code,
+// e.g. because the user denied access.
+type AuthorizationError struct {
+	Code        string
+	Description string
+}
+
+func (e *AuthorizationError) Error() string {
+	if e.Description == "" {
+		return fmt.Sprintf("auth: authorization failed: %s", e.Code)
+	}
+	return fmt.Sprintf("auth: authorization failed: %s: %s", e.Code, e.Description)
+}
+
+// Flow is a single authorization code grant with PKCE. Create a new Flow for every login.
+type Flow struct {
+	conf     *oauth2.Config
+	state    string
+	verifier string
+}
+
+// NewFlow starts a grant for conf with a random state and PKCE code verifier.
+func NewFlow(conf Config) (*Flow, error) {
+	state, err := randomString(16)
+	if err != nil {
+		return nil, fmt.Errorf("auth: failed to generate state: %w", err)
+	}
+	verifier, err := randomString(32)
+	if err != nil {
+		return nil, fmt.Errorf("auth: failed to generate code verifier: %w", err)
+	}
+
+	return &Flow{conf: conf.oauth2(), state: state, verifier: verifier}, nil
+}
+
+// State returns the state sent to wavy.fm, which is expected back on the redirect.
+func (f *Flow) State() string {
+	return f.state
+}
+
+// AuthCodeURL returns the url the user grants access on.
+func (f *Flow) AuthCodeURL(opts ...oauth2.AuthCodeOption) string {
+	opts = append(opts,
+		oauth2.SetAuthURLParam("code_challenge", codeChallenge(f.verifier)),
+		oauth2.SetAuthURLParam("code_challenge_method", "S256"),
+	)
+	return f.conf.AuthCodeURL(f.state, opts...)
+}
+
+// Exchange trades the code of the redirect for a token, after checking state.
+func (f *Flow) Exchange(ctx context.Context, state, code string) (*oauth2.Token, error) {
+	if subtle.ConstantTimeCompare([]byte(state), []byte(f.state)) != 1 {
+		return nil, ErrStateMismatch
+	}
+
+	token, err := f.conf.Exchange(ctx, code, oauth2.SetAuthURLParam("code_verifier", f.verifier))
+	if err != nil {
+		return nil, fmt.Errorf("auth: failed to exchange code: %w", err)
+	}
+	return token, nil
+}
+
+// HandleRedirect exchanges the query of the redirect to RedirectURL for a token.
+func (f *Flow) HandleRedirect(ctx context.Context, query url.Values) (*oauth2.Token, error) {
+	if code := query.Get("error"); code != "" {
+		return nil, &AuthorizationError{Code: code, Description: query.Get("error_description")}
+	}
+	if query.Get("code") == "" {
+		return nil, errors.New("auth: redirect holds neither a code nor an error")
+	}
+
+	return f.Exchange(ctx, query.Get("state"), query.Get("code"))
+}
+
+// codeChallenge derives the S256 challenge of verifier, see RFC 7636.
+func codeChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func randomString(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,184 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeServer is a minimal OAuth2 server supporting the authorization code grant with PKCE and refresh tokens.
+// Its authorize endpoint grants access right away and redirects with a code.
+type fakeServer struct {
+	*httptest.Server
+
+	mu         sync.Mutex
+	challenges map[string]string
+	refreshes  int
+	// deny makes the authorize endpoint redirect with an access_denied error.
+	deny bool
+}
+
+func newFakeServer(t *testing.T) *fakeServer {
+	s := &fakeServer{challenges: map[string]string{}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/authorize", s.authorize)
+	mux.HandleFunc("/token", s.token)
+	s.Server = httptest.NewServer(mux)
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *fakeServer) config() Config {
+	return Config{
+		ClientID: "client",
+		AuthURL:  s.URL + "/authorize",
+		TokenURL: s.URL + "/token",
+	}
+}
+
+func (s *fakeServer) authorize(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	redirect, _ := url.Parse(q.Get("redirect_uri"))
+
+	params := redirect.Query()
+	params.Set("state", q.Get("state"))
+	if s.deny {
+		params.Set("error", "access_denied")
+		params.Set("error_description", "the user denied access")
+	} else {
+		s.mu.Lock()
+		code := fmt.Sprintf("code-%d", len(s.challenges))
+		s.challenges[code] = q.Get("code_challenge")
+		s.mu.Unlock()
+		params.Set("code", code)
+	}
+
+	redirect.RawQuery = params.Encode()
+	http.Redirect(w, r, redirect.String(), http.StatusFound)
+}
+
+func (s *fakeServer) token(w http.ResponseWriter, r *http.Request) {
+	r.ParseForm()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch r.Form.Get("grant_type") {
+	case "authorization_code":
+		challenge, ok := s.challenges[r.Form.Get("code")]
+		if !ok || challenge != codeChallenge(r.Form.Get("code_verifier")) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error": "invalid_grant"}`))
+			return
+		}
+		delete(s.challenges, r.Form.Get("code"))
+	case "refresh_token":
+		if r.Form.Get("refresh_token") != fmt.Sprintf("refresh-%d", s.refreshes) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error": "invalid_grant"}`))
+			return
+		}
+		s.refreshes++
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"access_token":  fmt.Sprintf("access-%d", s.refreshes),
+		"refresh_token": fmt.Sprintf("refresh-%d", s.refreshes),
+		"token_type":    "Bearer",
+		"expires_in":    3600,
+	})
+}
+
+// redirectOf follows the authorize endpoint of the fake server for authURL and returns the redirect query.
+func redirectOf(t *testing.T, authURL string) url.Values {
+	c := &http.Client{CheckRedirect: func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+	res, err := c.Get(authURL)
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	location, err := res.Location()
+	require.NoError(t, err)
+	return location.Query()
+}
+
+func TestFlow(t *testing.T) {
+	s := newFakeServer(t)
+	conf := s.config()
+	conf.RedirectURL = "https://example.com/callback"
+
+	flow, err := NewFlow(conf)
+	require.NoError(t, err)
+
+	authURL, err := url.Parse(flow.AuthCodeURL())
+	require.NoError(t, err)
+	assert.Equal(t, "S256", authURL.Query().Get("code_challenge_method"))
+	assert.Equal(t, codeChallenge(flow.verifier), authURL.Query().Get("code_challenge"))
+	assert.Equal(t, flow.State(), authURL.Query().Get("state"))
+	assert.Empty(t, authURL.Query().Get("code_verifier"))
+
+	token, err := flow.HandleRedirect(context.Background(), redirectOf(t, authURL.String()))
+	require.NoError(t, err)
+	assert.Equal(t, "access-0", token.AccessToken)
+	assert.Equal(t, "refresh-0", token.RefreshToken)
+}
+
+func TestFlow_wrongVerifier(t *testing.T) {
+	s := newFakeServer(t)
+
+	flow, err := NewFlow(s.config())
+	require.NoError(t, err)
+	query := redirectOf(t, flow.AuthCodeURL())
+
+	other, err := NewFlow(s.config())
+	require.NoError(t, err)
+	other.state = flow.state
+
+	_, err = other.HandleRedirect(context.Background(), query)
+	assert.Error(t, err)
+}
+
+func TestFlow_stateMismatch(t *testing.T) {
+	s := newFakeServer(t)
+
+	flow, err := NewFlow(s.config())
+	require.NoError(t, err)
+
+	_, err = flow.Exchange(context.Background(), "forged", "code-0")
+	assert.True(t, errors.Is(err, ErrStateMismatch))
+}
+
+func TestFlow_denied(t *testing.T) {
+	s := newFakeServer(t)
+	s.deny = true
+
+	flow, err := NewFlow(s.config())
+	require.NoError(t, err)
+
+	_, err = flow.HandleRedirect(context.Background(), redirectOf(t, flow.AuthCodeURL()))
+
+	var authErr *AuthorizationError
+	require.True(t, errors.As(err, &authErr))
+	assert.Equal(t, "access_denied", authErr.Code)
+	assert.Equal(t, "the user denied access", authErr.Description)
+}
+
+func Test_codeChallenge(t *testing.T) {
+	// Example from RFC 7636, appendix B.
+	assert.Equal(t, "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM", codeChallenge("dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"))
+}
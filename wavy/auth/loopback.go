@@ -0,0 +1,127 @@
+package auth
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// Authorize runs a Flow for a CLI. It receives the redirect on a loopback address and calls open with the url
+// the user has to visit, e.g. PrintURL or a func opening a browser. It returns once the redirect was handled
+// or ctx is done.
+//
+// When conf.RedirectURL is empty, http://127.0.0.1 with a random port and the /callback path is used.
+// Otherwise it must be a http url on a loopback address, and it is sent to wavy.fm as is, so it matches the
+// redirect url registered for the application. Only a port of 0 is replaced by a random port.
+//
+// Only a request to the path of the redirect url carrying the state of the flow, or an error, ends the flow.
+// Other requests, such as a browser fetching a favicon, are answered with an error and ignored.
+func Authorize(ctx context.Context, conf Config, open func(authURL string) error) (*oauth2.Token, error) {
+	redirect := &url.URL{Scheme: "http", Host: "127.0.0.1:0", Path: "/callback"}
+	if conf.RedirectURL != "" {
+		u, err := url.Parse(conf.RedirectURL)
+		if err != nil {
+			return nil, fmt.Errorf("auth: failed to parse redirect url: %w", err)
+		}
+		if u.Scheme != "http" || !isLoopback(u.Hostname()) {
+			return nil, fmt.Errorf("auth: redirect url %q is not a http url on a loopback address", conf.RedirectURL)
+		}
+		redirect = u
+	}
+
+	path := redirect.Path
+	if path == "" {
+		path = "/"
+	}
+	port := redirect.Port()
+	if port == "" {
+		port = "80"
+	}
+
+	ln, err := net.Listen("tcp", net.JoinHostPort(redirect.Hostname(), port))
+	if err != nil {
+		return nil, fmt.Errorf("auth: failed to listen for the redirect: %w", err)
+	}
+	defer ln.Close()
+
+	if port == "0" {
+		redirect.Host = net.JoinHostPort(redirect.Hostname(), strconv.Itoa(ln.Addr().(*net.TCPAddr).Port))
+		conf.RedirectURL = redirect.String()
+	}
+	flow, err := NewFlow(conf)
+	if err != nil {
+		return nil, err
+	}
+
+	type result struct {
+		token *oauth2.Token
+		err   error
+	}
+	results := make(chan result, 1)
+
+	server := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if r.URL.Path != path {
+			http.NotFound(w, r)
+			return
+		}
+		if query.Get("error") == "" && subtle.ConstantTimeCompare([]byte(query.Get("state")), []byte(flow.State())) != 1 {
+			http.Error(w, "Not an authorization redirect of this flow.", http.StatusBadRequest)
+			return
+		}
+
+		token, err := flow.HandleRedirect(ctx, query)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Authorization failed: %s", err), http.StatusBadRequest)
+		} else {
+			fmt.Fprintln(w, "Authorization succeeded, you can close this window.")
+		}
+
+		select {
+		case results <- result{token: token, err: err}:
+		default:
+		}
+	})}
+	go server.Serve(ln)
+	defer func() {
+		// Let the page reach the browser before the server goes away.
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+	}()
+
+	if err := open(flow.AuthCodeURL()); err != nil {
+		return nil, fmt.Errorf("auth: failed to open authorization url: %w", err)
+	}
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case r := <-results:
+		return r.token, r.err
+	}
+}
+
+// PrintURL returns an open func for Authorize which asks the user to visit the url by writing it to w.
+func PrintURL(w io.Writer) func(authURL string) error {
+	return func(authURL string) error {
+		_, err := fmt.Fprintf(w, "Visit the following url to authorize access to your wavy.fm account:\n\n%s\n\n", authURL)
+		return err
+	}
+}
+
+func isLoopback(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
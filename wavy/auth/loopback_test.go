@@ -0,0 +1,128 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// visit acts as the browser of the user, following the redirect of the fake server to the loopback address.
+// The page shown to the user is sent on pages.
+func visit(t *testing.T, pages chan<- string) func(string) error {
+	return func(authURL string) error {
+		go func() {
+			res, err := http.Get(authURL)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			defer res.Body.Close()
+			body, _ := ioutil.ReadAll(res.Body)
+			pages <- string(body)
+		}()
+		return nil
+	}
+}
+
+func TestAuthorize(t *testing.T) {
+	s := newFakeServer(t)
+
+	pages := make(chan string, 1)
+	token, err := Authorize(context.Background(), s.config(), visit(t, pages))
+	require.NoError(t, err)
+	assert.Equal(t, "access-0", token.AccessToken)
+	assert.Contains(t, <-pages, "Authorization succeeded")
+}
+
+func TestAuthorize_strayRequests(t *testing.T) {
+	s := newFakeServer(t)
+
+	pages := make(chan string, 1)
+	browse := visit(t, pages)
+	token, err := Authorize(context.Background(), s.config(), func(authURL string) error {
+		u, err := url.Parse(authURL)
+		require.NoError(t, err)
+		redirect, err := url.Parse(u.Query().Get("redirect_uri"))
+		require.NoError(t, err)
+
+		// Requests which are not the redirect of the flow do not end it.
+		for _, stray := range []string{"/favicon.ico", redirect.Path, redirect.Path + "?code=c&state=other"} {
+			res, err := http.Get("http://" + redirect.Host + stray)
+			require.NoError(t, err)
+			res.Body.Close()
+			assert.True(t, res.StatusCode >= 400, stray)
+		}
+
+		return browse(authURL)
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "access-0", token.AccessToken)
+	assert.Contains(t, <-pages, "Authorization succeeded")
+}
+
+func TestAuthorize_denied(t *testing.T) {
+	s := newFakeServer(t)
+	s.deny = true
+
+	pages := make(chan string, 1)
+	_, err := Authorize(context.Background(), s.config(), visit(t, pages))
+
+	var authErr *AuthorizationError
+	assert.True(t, errors.As(err, &authErr))
+	assert.Contains(t, <-pages, "access_denied")
+}
+
+func TestAuthorize_redirectURL(t *testing.T) {
+	s := newFakeServer(t)
+
+	conf := s.config()
+	conf.RedirectURL = "https://example.com/callback"
+	_, err := Authorize(context.Background(), conf, PrintURL(ioutil.Discard))
+	assert.Error(t, err)
+
+	// A port of 0 is replaced by the port listened on, the rest of the url is kept.
+	conf.RedirectURL = "http://localhost:0/wavy?app=cli"
+	var printed bytes.Buffer
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err = Authorize(ctx, conf, PrintURL(&printed))
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	assert.Contains(t, printed.String(), s.URL+"/authorize?")
+	assert.Regexp(t, `redirect_uri=http%3A%2F%2Flocalhost%3A[1-9][0-9]*%2Fwavy%3Fapp%3Dcli&`, printed.String())
+}
+
+func TestAuthorize_registeredRedirectURL(t *testing.T) {
+	s := newFakeServer(t)
+
+	ln, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	port := ln.Addr().(*net.TCPAddr).Port
+	require.NoError(t, ln.Close())
+
+	conf := s.config()
+	conf.RedirectURL = fmt.Sprintf("http://localhost:%d/wavy?app=cli", port)
+
+	var redirectURI string
+	pages := make(chan string, 1)
+	token, err := Authorize(context.Background(), conf, func(authURL string) error {
+		u, err := url.Parse(authURL)
+		require.NoError(t, err)
+		redirectURI = u.Query().Get("redirect_uri")
+		return visit(t, pages)(authURL)
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "access-0", token.AccessToken)
+	assert.Equal(t, conf.RedirectURL, redirectURI)
+	assert.Contains(t, <-pages, "Authorization succeeded")
+}
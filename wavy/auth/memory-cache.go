@@ -0,0 +1,58 @@
+package auth
+
+import (
+	"context"
+	"sync"
+
+	"golang.org/x/oauth2"
+)
+
+type memoryCache struct {
+	mu     sync.Mutex
+	tokens map[string]oauth2.Token
+	locks  map[string]chan struct{}
+}
+
+// NewMemoryCache returns a TokenCache shared by the token sources of this process.
+func NewMemoryCache() TokenCache {
+	return &memoryCache{
+		tokens: map[string]oauth2.Token{},
+		locks:  map[string]chan struct{}{},
+	}
+}
+
+func (m *memoryCache) Load(_ context.Context, key string) (*oauth2.Token, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	token, ok := m.tokens[key]
+	if !ok {
+		return nil, ErrCacheMiss
+	}
+	return &token, nil
+}
+
+func (m *memoryCache) Store(_ context.Context, key string, token *oauth2.Token) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.tokens[key] = *token
+	return nil
+}
+
+func (m *memoryCache) Lock(ctx context.Context, key string) (func(), error) {
+	m.mu.Lock()
+	lock, ok := m.locks[key]
+	if !ok {
+		lock = make(chan struct{}, 1)
+		m.locks[key] = lock
+	}
+	m.mu.Unlock()
+
+	select {
+	case lock <- struct{}{}:
+		return func() { <-lock }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
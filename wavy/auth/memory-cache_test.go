@@ -0,0 +1,9 @@
+package auth
+
+import (
+	"testing"
+)
+
+func TestMemoryCache(t *testing.T) {
+	testCache(t, NewMemoryCache())
+}
@@ -0,0 +1,63 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"golang.org/x/oauth2"
+)
+
+// ErrNoRefreshToken is returned by Refresh for tokens without a refresh token.
+var ErrNoRefreshToken = errors.New("auth: token has no refresh token")
+
+// TokenSource returns a source which returns token until it expires and refreshes it afterwards.
+// onRefresh, when not nil, receives every refreshed token so it can be persisted, as wavy.fm may rotate
+// refresh tokens.
+func TokenSource(ctx context.Context, conf Config, token *oauth2.Token, onRefresh func(*oauth2.Token)) oauth2.TokenSource {
+	src := conf.oauth2().TokenSource(ctx, token)
+	if onRefresh == nil {
+		return src
+	}
+
+	return &notifyingSource{src: src, last: token, onRefresh: onRefresh}
+}
+
+// Refresh trades the refresh token of token for a new token, regardless of whether token expired.
+func Refresh(ctx context.Context, conf Config, token *oauth2.Token) (*oauth2.Token, error) {
+	if token.RefreshToken == "" {
+		return nil, ErrNoRefreshToken
+	}
+
+	refreshed, err := conf.oauth2().TokenSource(ctx, &oauth2.Token{RefreshToken: token.RefreshToken}).Token()
+	if err != nil {
+		return nil, fmt.Errorf("auth: failed to refresh token: %w", err)
+	}
+	return refreshed, nil
+}
+
+type notifyingSource struct {
+	src       oauth2.TokenSource
+	onRefresh func(*oauth2.Token)
+
+	mu   sync.Mutex
+	last *oauth2.Token
+}
+
+func (s *notifyingSource) Token() (*oauth2.Token, error) {
+	token, err := s.src.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	refreshed := s.last == nil || s.last.AccessToken != token.AccessToken
+	s.last = token
+	s.mu.Unlock()
+
+	if refreshed {
+		s.onRefresh(token)
+	}
+	return token, nil
+}
@@ -0,0 +1,61 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+)
+
+func TestTokenSource(t *testing.T) {
+	s := newFakeServer(t)
+
+	var refreshed []*oauth2.Token
+	expired := &oauth2.Token{AccessToken: "old", RefreshToken: "refresh-0", Expiry: time.Now().Add(-time.Minute)}
+	src := TokenSource(context.Background(), s.config(), expired, func(token *oauth2.Token) {
+		refreshed = append(refreshed, token)
+	})
+
+	token, err := src.Token()
+	require.NoError(t, err)
+	assert.Equal(t, "access-1", token.AccessToken)
+	assert.Equal(t, "refresh-1", token.RefreshToken)
+
+	token, err = src.Token()
+	require.NoError(t, err)
+	assert.Equal(t, "access-1", token.AccessToken)
+
+	require.Len(t, refreshed, 1)
+	assert.Equal(t, "refresh-1", refreshed[0].RefreshToken)
+}
+
+func TestTokenSource_valid(t *testing.T) {
+	s := newFakeServer(t)
+
+	valid := &oauth2.Token{AccessToken: "valid", Expiry: time.Now().Add(time.Hour)}
+	src := TokenSource(context.Background(), s.config(), valid, func(token *oauth2.Token) {
+		t.Errorf("unexpected refresh to %q", token.AccessToken)
+	})
+
+	token, err := src.Token()
+	require.NoError(t, err)
+	assert.Equal(t, "valid", token.AccessToken)
+}
+
+func TestRefresh(t *testing.T) {
+	s := newFakeServer(t)
+
+	token, err := Refresh(context.Background(), s.config(), &oauth2.Token{AccessToken: "valid", RefreshToken: "refresh-0", Expiry: time.Now().Add(time.Hour)})
+	require.NoError(t, err)
+	assert.Equal(t, "access-1", token.AccessToken)
+
+	_, err = Refresh(context.Background(), s.config(), &oauth2.Token{RefreshToken: "refresh-0"})
+	assert.Error(t, err)
+
+	_, err = Refresh(context.Background(), s.config(), &oauth2.Token{AccessToken: "valid"})
+	assert.True(t, errors.Is(err, ErrNoRefreshToken))
+}
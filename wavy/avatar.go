@@ -0,0 +1,267 @@
+package wavy
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	xdraw "golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+const (
+	// SmallAvatarSize is the largest size GetAvatar serves from the small avatar of a profile.
+	SmallAvatarSize = 64
+
+	// DefaultAvatarCacheSize is the amount of avatars a client caches unless WithAvatarCache is passed.
+	DefaultAvatarCacheSize = 128
+	// DefaultAvatarCacheTTL is how long a client caches avatars unless WithAvatarCache is passed.
+	DefaultAvatarCacheTTL = time.Hour
+
+	// MaxAvatarSize is the largest size GetAvatar scales avatars to.
+	MaxAvatarSize = 1024
+
+	// maxAvatarBytes limits the size of avatar images read by GetAvatar.
+	maxAvatarBytes = 10 << 20
+	// maxAvatarPixels limits the pixels of avatar images decoded by GetAvatar, as small files may declare huge images.
+	maxAvatarPixels = 4096 * 4096
+)
+
+// ErrNoAvatar is returned by GetAvatar for profiles without avatar.
+var ErrNoAvatar = errors.New("profile has no avatar")
+
+// WithAvatarCache caches up to size avatars fetched with GetAvatar for ttl, instead of DefaultAvatarCacheSize
+// for DefaultAvatarCacheTTL. A size of 0 disables the cache.
+func WithAvatarCache(size int, ttl time.Duration) ClientOption {
+	return func(c *client) {
+		c.avatars = newAvatarCache(size, ttl)
+	}
+}
+
+// AvatarURL returns the url of the avatar of p best suited to show it at size x size pixels: the small avatar for
+// sizes up to SmallAvatarSize, the avatar otherwise. A size of 0 selects the avatar. It is empty when p has no avatar.
+func (p Profile) AvatarURL(size int) string {
+	if size > 0 && size <= SmallAvatarSize && p.AvatarSmall != "" {
+		return p.AvatarSmall
+	}
+	if p.Avatar != "" {
+		return p.Avatar
+	}
+	return p.AvatarSmall
+}
+
+// GetAvatar
+// Fetches the avatar of profile, scaled and cropped to size x size pixels. A size of 0 returns the avatar as served.
+// Sizes above MaxAvatarSize are rejected. Of opts, only WithTimeout, WithLogger, WithSkipCache and WithForceRefresh
+// apply, as the request goes to the host serving the avatar rather than the api.
+func (u *userService) GetAvatar(ctx context.Context, profile Profile, size int, opts ...CallOption) (image.Image, error) {
+	if size < 0 || size > MaxAvatarSize {
+		return nil, fmt.Errorf("%s: failed to fetch avatar: size %d is not between 0 and %d", u.logger.Name(), size, MaxAvatarSize)
+	}
+
+	avatarURL := profile.AvatarURL(size)
+	if avatarURL == "" {
+		return nil, fmt.Errorf("%s: failed to fetch avatar: %w", u.logger.Name(), ErrNoAvatar)
+	}
+
+	return u.c.avatar(ctx, u.logger, avatarURL, size, avatarOptions(u.c.callOptions(opts)))
+}
+
+// avatarOptions returns the options of o which apply to avatar requests. Headers, retry policies and the other call
+// options are meant for the api, so they are not used for the hosts serving avatars. The Cache-Control header of
+// WithSkipCache and WithForceRefresh is kept to control the avatar cache, but it is not sent either.
+func avatarOptions(o *callOptions) *callOptions {
+	avatar := &callOptions{timeout: o.timeout, logger: o.logger, header: http.Header{}}
+	if cacheControl := o.header.Get("Cache-Control"); cacheControl != "" {
+		avatar.header.Set("Cache-Control", cacheControl)
+	}
+	return avatar
+}
+
+// avatar fetches the image at avatarURL, resized to size, or serves it from the avatar cache of c.
+// WithSkipCache bypasses the cache, WithForceRefresh replaces the cached image.
+func (c *client) avatar(ctx context.Context, logger Logger, avatarURL string, size int, o *callOptions) (image.Image, error) {
+	key := fmt.Sprintf("%s %d", avatarURL, size)
+	cacheControl := o.header.Get("Cache-Control")
+	if !strings.Contains(cacheControl, "no-store") && !strings.Contains(cacheControl, "no-cache") {
+		if img, ok := c.avatars.get(key); ok {
+			return img, nil
+		}
+	}
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if o.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, o.timeout)
+		defer cancel()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", avatarURL, nil)
+	if err != nil {
+		return nil, c.redaction.Error(fmt.Errorf("%s: failed to fetch avatar: %w", logger.Name(), err))
+	}
+
+	res, err := c.send(c.avatarClient(), req, &callOptions{header: http.Header{}}, o.loggerOr(logger))
+	if err != nil {
+		return nil, c.redaction.Error(fmt.Errorf("%s: failed to fetch avatar: %w", logger.Name(), err))
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s: failed to fetch avatar: unexpected status code %d", logger.Name(), res.StatusCode)
+	}
+
+	img, err := decodeAvatar(io.LimitReader(res.Body, maxAvatarBytes))
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to decode avatar: %w", logger.Name(), err)
+	}
+	if size > 0 {
+		img = resizeSquare(img, size)
+	}
+
+	if !strings.Contains(cacheControl, "no-store") {
+		c.avatars.put(key, img)
+	}
+	return img, nil
+}
+
+// decodeAvatar decodes the image read from r, unless its header declares more than maxAvatarPixels pixels.
+func decodeAvatar(r io.Reader) (image.Image, error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+	if config.Width <= 0 || config.Height <= 0 || config.Width > maxAvatarPixels/config.Height {
+		return nil, fmt.Errorf("image of %dx%d pixels exceeds %d pixels", config.Width, config.Height, maxAvatarPixels)
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(b))
+	return img, err
+}
+
+// avatarClient returns the http client fetching avatars. It uses the transport of c, but not its token, as avatars
+// are served outside of the api.
+func (c *client) avatarClient() *http.Client {
+	if c.transport != nil {
+		return &http.Client{Transport: c.transport}
+	}
+	return http.DefaultClient
+}
+
+// resizeSquare crops the center square of img and scales it to size x size pixels.
+func resizeSquare(img image.Image, size int) image.Image {
+	b := img.Bounds()
+	side := b.Dx()
+	if b.Dy() < side {
+		side = b.Dy()
+	}
+	crop := image.Rect(0, 0, side, side).Add(b.Min).Add(image.Pt((b.Dx()-side)/2, (b.Dy()-side)/2))
+
+	dst := image.NewRGBA(image.Rect(0, 0, size, size))
+	xdraw.CatmullRom.Scale(dst, dst.Bounds(), img, crop, draw.Src, nil)
+	return dst
+}
+
+// cloneImage copies img, so cached avatars are not modified through the images returned by GetAvatar.
+func cloneImage(img image.Image) image.Image {
+	dst := image.NewRGBA(img.Bounds())
+	draw.Draw(dst, dst.Bounds(), img, img.Bounds().Min, draw.Src)
+	return dst
+}
+
+// avatarCache holds the most recently used avatars for a limited time.
+type avatarCache struct {
+	size int
+	ttl  time.Duration
+	now  func() time.Time
+
+	mu      sync.Mutex
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+type avatarEntry struct {
+	key     string
+	img     image.Image
+	fetched time.Time
+}
+
+// newAvatarCache returns a cache of size avatars, nil when size is not positive. A nil cache holds nothing.
+func newAvatarCache(size int, ttl time.Duration) *avatarCache {
+	if size <= 0 {
+		return nil
+	}
+	return &avatarCache{
+		size:    size,
+		ttl:     ttl,
+		now:     time.Now,
+		order:   list.New(),
+		entries: make(map[string]*list.Element),
+	}
+}
+
+// get returns a copy of the avatar cached as key.
+func (a *avatarCache) get(key string) (image.Image, bool) {
+	if a == nil {
+		return nil, false
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	e, ok := a.entries[key]
+	if !ok {
+		return nil, false
+	}
+	entry := e.Value.(*avatarEntry)
+	if a.ttl > 0 && a.now().Sub(entry.fetched) >= a.ttl {
+		a.order.Remove(e)
+		delete(a.entries, key)
+		return nil, false
+	}
+
+	a.order.MoveToFront(e)
+	return cloneImage(entry.img), true
+}
+
+// put caches a copy of img as key.
+func (a *avatarCache) put(key string, img image.Image) {
+	if a == nil {
+		return
+	}
+	img = cloneImage(img)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if e, ok := a.entries[key]; ok {
+		e.Value = &avatarEntry{key: key, img: img, fetched: a.now()}
+		a.order.MoveToFront(e)
+		return
+	}
+
+	a.entries[key] = a.order.PushFront(&avatarEntry{key: key, img: img, fetched: a.now()})
+	for a.order.Len() > a.size {
+		oldest := a.order.Back()
+		a.order.Remove(oldest)
+		delete(a.entries, oldest.Value.(*avatarEntry).key)
+	}
+}
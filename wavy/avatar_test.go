@@ -0,0 +1,213 @@
+package wavy
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newAvatarServer serves a 40x20 png avatar, red on the left half and blue on the right half, and counts requests.
+func newAvatarServer(t *testing.T, requests *int32) *httptest.Server {
+	img := image.NewRGBA(image.Rect(0, 0, 40, 20))
+	for x := 0; x < 40; x++ {
+		for y := 0; y < 20; y++ {
+			c := color.RGBA{R: 255, A: 255}
+			if x >= 20 {
+				c = color.RGBA{B: 255, A: 255}
+			}
+			img.Set(x, y, c)
+		}
+	}
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(requests, 1)
+		if r.Header.Get("Authorization") != "" {
+			t.Error("avatars must be fetched without token")
+		}
+		switch r.URL.Path {
+		case "/missing.png":
+			w.WriteHeader(http.StatusNotFound)
+			return
+		case "/huge.png":
+			w.Header().Set("Content-Type", "image/png")
+			w.Write(pngHeader(50000, 50000))
+			return
+		}
+		w.Header().Set("Content-Type", "image/png")
+		w.Write(buf.Bytes())
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// pngHeader returns the start of a png of width x height pixels, up to its header chunk.
+func pngHeader(width, height uint32) []byte {
+	ihdr := make([]byte, 17)
+	copy(ihdr, "IHDR")
+	binary.BigEndian.PutUint32(ihdr[4:], width)
+	binary.BigEndian.PutUint32(ihdr[8:], height)
+	ihdr[12], ihdr[13] = 8, 6 // 8 bit RGBA
+
+	b := []byte("\x89PNG\r\n\x1a\n")
+	b = binary.BigEndian.AppendUint32(b, 13)
+	b = append(b, ihdr...)
+	return binary.BigEndian.AppendUint32(b, crc32.ChecksumIEEE(ihdr))
+}
+
+func TestProfile_AvatarURL(t *testing.T) {
+	p := Profile{Avatar: "https://cdn.wavy.fm/a.png", AvatarSmall: "https://cdn.wavy.fm/a_small.png"}
+	assert.Equal(t, p.Avatar, p.AvatarURL(0))
+	assert.Equal(t, p.AvatarSmall, p.AvatarURL(SmallAvatarSize))
+	assert.Equal(t, p.Avatar, p.AvatarURL(SmallAvatarSize+1))
+	assert.Equal(t, p.AvatarSmall, Profile{AvatarSmall: p.AvatarSmall}.AvatarURL(256))
+	assert.Empty(t, Profile{}.AvatarURL(0))
+}
+
+func TestUserService_GetAvatar(t *testing.T) {
+	var requests int32
+	server := newAvatarServer(t, &requests)
+
+	c := newClient(NopLogger(), []ClientOption{WithAvatarCache(2, time.Hour)})
+	c.c = server.Client()
+	u := newUserService(c, c.logger)
+	profile := Profile{Avatar: server.URL + "/avatar.png"}
+
+	img, err := u.GetAvatar(context.Background(), profile, 0)
+	require.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, 40, 20), img.Bounds())
+
+	img, err = u.GetAvatar(context.Background(), profile, 10)
+	require.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, 10, 10), img.Bounds())
+	// The center square is half red and half blue.
+	r, _, b, _ := img.At(1, 5).RGBA()
+	assert.True(t, r > b, "left is red")
+	r, _, b, _ = img.At(8, 5).RGBA()
+	assert.True(t, b > r, "right is blue")
+
+	_, err = u.GetAvatar(context.Background(), profile, 10)
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&requests), "cached")
+
+	_, err = u.GetAvatar(context.Background(), profile, 10, WithForceRefresh())
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&requests))
+
+	_, err = u.GetAvatar(context.Background(), Profile{}, 10)
+	assert.True(t, errors.Is(err, ErrNoAvatar))
+
+	_, err = u.GetAvatar(context.Background(), Profile{Avatar: server.URL + "/missing.png"}, 10)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unexpected status code 404")
+
+	_, err = u.GetAvatar(context.Background(), Profile{Avatar: server.URL + "/huge.png"}, 10)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "image of 50000x50000 pixels exceeds")
+
+	requested := atomic.LoadInt32(&requests)
+	for _, size := range []int{-1, MaxAvatarSize + 1, 1 << 16} {
+		_, err = u.GetAvatar(context.Background(), profile, size)
+		assert.Error(t, err, size)
+	}
+	assert.Equal(t, requested, atomic.LoadInt32(&requests), "invalid sizes are not fetched")
+}
+
+func TestUserService_GetAvatar_transport(t *testing.T) {
+	var requests int32
+	server := newAvatarServer(t, &requests)
+
+	var viaTransport int32
+	transport := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		atomic.AddInt32(&viaTransport, 1)
+		return http.DefaultTransport.RoundTrip(req)
+	})
+
+	c := newClient(NopLogger(), []ClientOption{WithTransport(transport), WithAvatarCache(0, 0)})
+	u := newUserService(c, c.logger)
+
+	for i := 0; i < 2; i++ {
+		_, err := u.GetAvatar(context.Background(), Profile{Avatar: server.URL + "/avatar.png"}, 0)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, int32(2), atomic.LoadInt32(&viaTransport), "not cached")
+}
+
+func TestUserService_GetAvatar_callOptions(t *testing.T) {
+	var (
+		header   http.Header
+		requests int
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		header = r.Header.Clone()
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(server.Close)
+
+	c := newClient(NopLogger(), []ClientOption{WithDefaultCallOptions(WithHeader("X-Api-Key", "secret"))})
+	c.c = server.Client()
+	u := newUserService(c, c.logger)
+
+	// Headers and retry policies are meant for the api and do not apply to the host serving the avatar.
+	_, err := u.GetAvatar(context.Background(), Profile{Avatar: server.URL + "/avatar.png"}, 0,
+		WithHeader("X-Request-Id", "1"), WithForceRefresh(), WithRetryPolicy(RetryPolicy{MaxAttempts: 3}))
+	require.Error(t, err)
+	assert.Equal(t, 1, requests)
+	assert.Empty(t, header.Get("X-Api-Key"))
+	assert.Empty(t, header.Get("X-Request-Id"))
+	assert.Empty(t, header.Get("Cache-Control"))
+}
+
+type roundTripperFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func Test_avatarCache(t *testing.T) {
+	now := time.Unix(0, 0)
+	cache := newAvatarCache(2, time.Minute)
+	cache.now = func() time.Time { return now }
+	img := image.NewRGBA(image.Rect(0, 0, 1, 1))
+
+	cache.put("a", img)
+	cache.put("b", img)
+	got, ok := cache.get("a")
+	assert.True(t, ok)
+
+	// Cached avatars are copies.
+	img.Set(0, 0, color.White)
+	got.(*image.RGBA).Set(0, 0, color.Black)
+	got, _ = cache.get("a")
+	assert.Equal(t, color.RGBA{}, got.At(0, 0))
+
+	cache.put("c", img)
+	_, ok = cache.get("b")
+	assert.False(t, ok, "least recently used is evicted")
+	_, ok = cache.get("a")
+	assert.True(t, ok)
+
+	now = now.Add(time.Minute)
+	_, ok = cache.get("a")
+	assert.False(t, ok, "expired")
+
+	var disabled *avatarCache
+	disabled.put("a", img)
+	_, ok = disabled.get("a")
+	assert.False(t, ok)
+}
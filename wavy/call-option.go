@@ -0,0 +1,110 @@
+package wavy
+
+import (
+	"io"
+	"net/http"
+	"time"
+)
+
+// CallOption changes the behaviour of a single service method call,
+// so one-off behaviour does not require a separate Client.
+type CallOption func(o *callOptions)
+
+type callOptions struct {
+	response *Response
+	timeout  time.Duration
+	header   http.Header
+	retry    *RetryPolicy
+	logger   Logger
+	strict   bool
+	// route is the path template of the endpoint requested by the call, set by getJSON.
+	route string
+	dump  io.Writer
+}
+
+func newCallOptions(opts []CallOption) *callOptions {
+	o := &callOptions{header: http.Header{}}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// loggerOr returns the request scoped logger, or fallback when none was set.
+func (o *callOptions) loggerOr(fallback Logger) Logger {
+	if o.logger != nil {
+		return o.logger
+	}
+	return fallback
+}
+
+// WithResponse stores the http details of the call in res, such as the status, headers, rate limit and request id.
+// res is filled for failed calls too, as long as wavy.fm responded.
+func WithResponse(res *Response) CallOption {
+	return func(o *callOptions) {
+		o.response = res
+	}
+}
+
+// WithTimeout limits the call, including retries and reading the response, to d.
+func WithTimeout(d time.Duration) CallOption {
+	return func(o *callOptions) {
+		o.timeout = d
+	}
+}
+
+// WithHeader adds a header to the request. It can be passed multiple times.
+func WithHeader(key, value string) CallOption {
+	return func(o *callOptions) {
+		o.header.Add(key, value)
+	}
+}
+
+// WithSkipCache asks wavy.fm and any cache in between, such as a caching http.Transport, to neither serve
+// nor store a cached response.
+func WithSkipCache() CallOption {
+	return func(o *callOptions) {
+		o.header.Set("Cache-Control", "no-store")
+	}
+}
+
+// WithForceRefresh asks wavy.fm and any cache in between to revalidate instead of serving a cached response.
+// Unlike WithSkipCache, the fresh response may be cached for later calls.
+func WithForceRefresh() CallOption {
+	return func(o *callOptions) {
+		o.header.Set("Cache-Control", "no-cache")
+		o.header.Set("Pragma", "no-cache")
+	}
+}
+
+// WithRetryPolicy retries the call according to policy. Calls are not retried by default.
+func WithRetryPolicy(policy RetryPolicy) CallOption {
+	return func(o *callOptions) {
+		o.retry = &policy
+	}
+}
+
+// WithLogger logs the call with logger instead of the logger of the Client.
+func WithLogger(logger Logger) CallOption {
+	return func(o *callOptions) {
+		o.logger = logger
+	}
+}
+
+// WithStrictDecoding fails the call when the response holds fields this package does not know about,
+// instead of ignoring them, or does not match the api description vendored in the schema package, e.g. because
+// a field is missing or has another type. The error then wraps a *schema.ValidationError listing the differences.
+// Useful to notice changes of the wavy.fm api, also for every call with WithDefaultCallOptions.
+func WithStrictDecoding() CallOption {
+	return func(o *callOptions) {
+		o.strict = true
+	}
+}
+
+// WithDump writes every request and response of the call to w, for debugging. Credentials are masked,
+// as are user identifiers when the Client was created WithRedaction for them.
+func WithDump(w io.Writer) CallOption {
+	return func(o *callOptions) {
+		o.dump = w
+	}
+}
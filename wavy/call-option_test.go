@@ -0,0 +1,82 @@
+package wavy
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithHeader(t *testing.T) {
+	var got http.Header
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header
+		w.Write([]byte("42"))
+	}))
+
+	_, err := newMetricsService(c, c.logger).GetTotalListens(context.Background(),
+		WithHeader("X-Trace", "a"),
+		WithHeader("X-Trace", "b"),
+		WithSkipCache(),
+	)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, got.Values("X-Trace"))
+	assert.Equal(t, "no-store", got.Get("Cache-Control"))
+}
+
+func TestWithForceRefresh(t *testing.T) {
+	var got http.Header
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header
+		w.Write([]byte("42"))
+	}))
+
+	_, err := newMetricsService(c, c.logger).GetTotalUsers(context.Background(), WithForceRefresh())
+	require.NoError(t, err)
+	assert.Equal(t, "no-cache", got.Get("Cache-Control"))
+	assert.Equal(t, "no-cache", got.Get("Pragma"))
+}
+
+func TestWithTimeout(t *testing.T) {
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+		w.Write([]byte("42"))
+	}))
+
+	_, err := newMetricsService(c, c.logger).GetTotalListens(context.Background(), WithTimeout(10*time.Millisecond))
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded), err.Error())
+}
+
+func TestWithTimeout_body(t *testing.T) {
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"username": "a", "user_id": "1", "count": 3}]`))
+	}))
+
+	got, err := newMetricsService(c, c.logger).GetUserListensLeaderboard(context.Background(), WithTimeout(time.Second))
+	require.NoError(t, err)
+	assert.Len(t, got.Entries, 1)
+}
+
+func TestWithLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewHCLogLogger(hclog.New(&hclog.LoggerOptions{Output: &buf, Level: hclog.Trace}))
+
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("42"))
+	}))
+
+	_, err := newMetricsService(c, c.logger).GetTotalListens(context.Background(), WithLogger(logger))
+	require.NoError(t, err)
+	assert.Contains(t, buf.String(), "fetching total listens")
+	assert.Contains(t, buf.String(), "processing request")
+}
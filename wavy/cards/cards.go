@@ -0,0 +1,209 @@
+// Package cards renders summary cards of wavy.fm users as SVG or PNG, e.g. to embed the stats or the current listen
+// of a user in a README or chat message. Rendering is pure Go and does not fetch anything: pass the avatar and album
+// art to show through Options, e.g. from wavy.UserService().GetAvatar.
+//
+//	card := cards.Stats(profile, stats, cards.Options{Theme: cards.ThemeDark, Avatar: avatar})
+//	err := card.WriteSVG(w)
+package cards
+
+import (
+	"image"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/OGKevin/go-wavy/wavy"
+)
+
+// Options configures a card.
+type Options struct {
+	// Theme defaults to ThemeLight.
+	Theme Theme
+	// Avatar of the user, shown on stats cards. The initial of the username is shown without.
+	Avatar image.Image
+	// AlbumArt of the current listen, shown on now playing cards. A placeholder record is shown without.
+	AlbumArt image.Image
+	// Scale is the amount of PNG pixels per SVG unit, it defaults to 1 and is capped at MaxScale.
+	Scale float64
+}
+
+// MaxScale is the largest Options.Scale, which limits PNG cards to 1980 pixels wide.
+const MaxScale = 4
+
+// Card is a rendered summary, written with WriteSVG or WritePNG.
+type Card struct {
+	width, height int
+	// title describes the card for screen readers.
+	title    string
+	scale    float64
+	elements []interface{}
+}
+
+// Size returns the size of c in SVG units.
+func (c *Card) Size() (width, height int) {
+	return c.width, c.height
+}
+
+// Title returns the text describing c, e.g. as alternative text.
+func (c *Card) Title() string {
+	return c.title
+}
+
+const (
+	padding = 25
+	// cardWidth is the width of every card, the width of the cards of common README widgets.
+	cardWidth = 495
+)
+
+func newCard(height int, title string, opts Options) (*Card, Theme) {
+	theme := opts.Theme
+	if theme.Name == "" {
+		theme = ThemeLight
+	}
+	scale := opts.Scale
+	switch {
+	case !(scale > 0):
+		scale = 1
+	case scale > MaxScale:
+		scale = MaxScale
+	}
+
+	c := &Card{width: cardWidth, height: height, title: title, scale: scale}
+	c.add(rect{w: cardWidth, h: float64(height), radius: 4.5, fill: theme.Background, stroke: theme.Border, strokeWidth: 1})
+	return c, theme
+}
+
+func (c *Card) add(e interface{}) {
+	c.elements = append(c.elements, e)
+}
+
+// Stats renders the listening stats of the user of profile.
+func Stats(profile *wavy.GetUserProfileResponse, stats *wavy.GetHistroyStatsResponse, opts Options) *Card {
+	name := username(profile)
+	c, theme := newCard(195, possessive(name)+" wavy.fm stats", opts)
+
+	const avatarSize = 100
+	titleWidth := cardWidth - 2*padding - avatarSize - padding
+	c.add(text{x: padding, y: 45, size: 18, bold: true, fill: theme.Title, s: truncate(c.title, 18, true, float64(titleWidth))})
+
+	var rows [][2]string
+	if stats != nil {
+		rows = append(rows,
+			[2]string{"Total listens", formatCount(int64(stats.TotalListens))},
+			[2]string{"Artists", formatCount(int64(stats.TotalArtists))},
+		)
+	}
+	if profile != nil && !profile.JoinTime.IsZero() {
+		rows = append(rows, [2]string{"Member since", profile.JoinTime.UTC().Format("Jan 2006")})
+	}
+	if profile != nil && profile.Profile.Country != "" {
+		rows = append(rows, [2]string{"Country", profile.Profile.Country})
+	}
+	if len(rows) == 0 {
+		rows = append(rows, [2]string{"No stats available", ""})
+	}
+
+	for i, row := range rows {
+		y := float64(85 + i*25)
+		c.add(text{x: padding, y: y, size: 14, fill: theme.Muted, s: row[0]})
+		c.add(text{x: 160, y: y, size: 14, bold: true, fill: theme.Text, s: truncate(row[1], 14, true, float64(titleWidth-160+padding))})
+	}
+
+	x, y := float64(cardWidth-padding-avatarSize), float64(195-avatarSize)/2
+	if opts.Avatar != nil {
+		c.add(picture{x: x, y: y, w: avatarSize, h: avatarSize, radius: avatarSize / 2, img: opts.Avatar})
+	} else {
+		c.add(circle{cx: x + avatarSize/2, cy: y + avatarSize/2, r: avatarSize / 2, fill: theme.Accent})
+		c.add(text{x: x + avatarSize/2, y: y + avatarSize/2 + 15, size: 42, bold: true, centered: true, fill: theme.Background, s: initial(name)})
+	}
+
+	return c
+}
+
+// NowPlaying renders the listen the user of profile is currently listening to. current may be nil when the user is
+// not listening to anything.
+func NowPlaying(profile *wavy.GetUserProfileResponse, current *wavy.GetCurrentResponse, opts Options) *Card {
+	name := username(profile)
+	playing := current != nil && current.Item.Song.Name != ""
+
+	title := name + " is not listening to anything"
+	if playing {
+		title = name + " is listening to " + current.Item.Song.Name
+	}
+	c, theme := newCard(150, title, opts)
+
+	const artSize = 100
+	x, y := float64(padding), float64(150-artSize)/2
+	switch {
+	case playing && opts.AlbumArt != nil:
+		c.add(picture{x: x, y: y, w: artSize, h: artSize, radius: 6, img: opts.AlbumArt})
+	default:
+		// A record as placeholder for missing album art.
+		c.add(rect{x: x, y: y, w: artSize, h: artSize, radius: 6, fill: theme.Border})
+		c.add(circle{cx: x + artSize/2, cy: y + artSize/2, r: artSize * 0.36, fill: theme.Muted})
+		c.add(circle{cx: x + artSize/2, cy: y + artSize/2, r: artSize * 0.12, fill: theme.Accent})
+		c.add(circle{cx: x + artSize/2, cy: y + artSize/2, r: artSize * 0.03, fill: theme.Border})
+	}
+
+	left := x + artSize + 20
+	width := float64(cardWidth) - left - padding
+	if !playing {
+		c.add(text{x: left, y: 68, size: 12, bold: true, fill: theme.Accent, s: "NOT PLAYING"})
+		c.add(text{x: left, y: 94, size: 18, bold: true, fill: theme.Text, s: truncate(name+" is not listening right now", 18, true, width)})
+		return c
+	}
+
+	item := current.Item
+	c.add(text{x: left, y: 45, size: 12, bold: true, fill: theme.Accent, s: truncate("NOW PLAYING · "+strings.ToUpper(name), 12, true, width)})
+	c.add(text{x: left, y: 75, size: 20, bold: true, fill: theme.Title, s: truncate(item.Song.Name, 20, true, width)})
+
+	artists := make([]string, 0, len(item.Artists))
+	for _, artist := range item.Artists {
+		artists = append(artists, artist.Name)
+	}
+	c.add(text{x: left, y: 100, size: 14, fill: theme.Text, s: truncate(strings.Join(artists, ", "), 14, false, width)})
+
+	album := item.Album.Name
+	if item.Local {
+		album = strings.TrimSpace(album + " (local file)")
+	}
+	c.add(text{x: left, y: 122, size: 12, fill: theme.Muted, s: truncate(album, 12, false, width)})
+
+	return c
+}
+
+func username(profile *wavy.GetUserProfileResponse) string {
+	if profile == nil || profile.Username == "" {
+		return "wavy.fm user"
+	}
+	return profile.Username
+}
+
+func possessive(name string) string {
+	if strings.HasSuffix(name, "s") {
+		return name + "'"
+	}
+	return name + "'s"
+}
+
+func initial(name string) string {
+	r, _ := utf8.DecodeRuneInString(name)
+	if r == utf8.RuneError {
+		return "?"
+	}
+	return string(unicode.ToUpper(r))
+}
+
+// formatCount formats n with thousands separators, e.g. 120,334.
+func formatCount(n int64) string {
+	s := strconv.FormatInt(n, 10)
+	sign := ""
+	if n < 0 {
+		sign, s = "-", s[1:]
+	}
+	for i := len(s) - 3; i > 0; i -= 3 {
+		s = s[:i] + "," + s[i:]
+	}
+	return sign + s
+}
@@ -0,0 +1,167 @@
+package cards
+
+import (
+	"bytes"
+	"encoding/xml"
+	"flag"
+	"image"
+	"image/color"
+	"image/png"
+	"io/ioutil"
+	"math"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/OGKevin/go-wavy/wavy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+var (
+	profile = &wavy.GetUserProfileResponse{
+		Username: "OGKevin",
+		JoinTime: time.Date(2020, 11, 2, 18, 21, 9, 0, time.UTC),
+		Profile:  wavy.Profile{Country: "NL"},
+	}
+	stats   = &wavy.GetHistroyStatsResponse{TotalListens: 120334, TotalArtists: 3120}
+	current = &wavy.GetCurrentResponse{Item: wavy.CurrentPlayingItem{
+		Song:    wavy.Song{Name: "Never Gonna Give You Up"},
+		Album:   wavy.Album{Name: "Whenever You Need Somebody"},
+		Artists: []wavy.Artists{{Name: "Rick Astley"}, {Name: "Stock Aitken Waterman"}},
+	}}
+)
+
+// gradient returns a w x h image fading from from to to, as stand in for avatars and album art.
+func gradient(w, h int, from, to color.RGBA) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for x := 0; x < w; x++ {
+		for y := 0; y < h; y++ {
+			t := float64(x+y) / float64(w+h-2)
+			mix := func(a, b uint8) uint8 { return uint8(float64(a)*(1-t) + float64(b)*t) }
+			img.Set(x, y, color.RGBA{R: mix(from.R, to.R), G: mix(from.G, to.G), B: mix(from.B, to.B), A: 0xff})
+		}
+	}
+	return img
+}
+
+func TestCards_golden(t *testing.T) {
+	avatar := gradient(64, 48, rgb(0xff71ce), rgb(0x01cdfe))
+	art := gradient(50, 50, rgb(0x05ffa1), rgb(0xb967ff))
+
+	tests := []struct {
+		name string
+		card *Card
+	}{
+		{name: "stats-light", card: Stats(profile, stats, Options{})},
+		{name: "stats-dark-avatar", card: Stats(profile, stats, Options{Theme: ThemeDark, Avatar: avatar})},
+		{name: "stats-empty", card: Stats(nil, nil, Options{Theme: ThemeVapor})},
+		{name: "now-playing-art", card: NowPlaying(profile, current, Options{Theme: ThemeVapor, AlbumArt: art})},
+		{name: "now-playing-no-art", card: NowPlaying(profile, current, Options{Theme: ThemeDark})},
+		{name: "not-playing", card: NowPlaying(profile, nil, Options{})},
+		{name: "stats-scaled", card: Stats(profile, stats, Options{Scale: 2})},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var svg, raster bytes.Buffer
+			require.NoError(t, tt.card.WriteSVG(&svg))
+			require.NoError(t, tt.card.WritePNG(&raster))
+
+			svgPath := filepath.Join("testdata", tt.name+".svg")
+			pngPath := filepath.Join("testdata", tt.name+".png")
+			if *update {
+				require.NoError(t, ioutil.WriteFile(svgPath, svg.Bytes(), 0644))
+				require.NoError(t, ioutil.WriteFile(pngPath, raster.Bytes(), 0644))
+			}
+
+			want, err := ioutil.ReadFile(svgPath)
+			require.NoError(t, err, "run go test ./wavy/cards -update to create the golden files")
+			assert.Equal(t, string(want), svg.String())
+
+			want, err = ioutil.ReadFile(pngPath)
+			require.NoError(t, err)
+			wantImg, err := png.Decode(bytes.NewReader(want))
+			require.NoError(t, err)
+			gotImg, err := png.Decode(&raster)
+			require.NoError(t, err)
+			assertSimilar(t, wantImg, gotImg)
+		})
+	}
+}
+
+// assertSimilar fails unless want and got have the same size and differ by a few levels per channel at most,
+// allowing for floating point differences between platforms.
+func assertSimilar(t *testing.T, want, got image.Image) {
+	t.Helper()
+
+	require.Equal(t, want.Bounds(), got.Bounds())
+	differing := 0
+	b := want.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			wr, wg, wb, wa := want.At(x, y).RGBA()
+			gr, gg, gb, ga := got.At(x, y).RGBA()
+			for _, d := range [][2]uint32{{wr, gr}, {wg, gg}, {wb, gb}, {wa, ga}} {
+				if diff(d[0], d[1]) > 4<<8 {
+					differing++
+					break
+				}
+			}
+		}
+	}
+	assert.LessOrEqual(t, differing, b.Dx()*b.Dy()/1000, "pixels differ from the golden image, run go test ./wavy/cards -update after checking the new image")
+}
+
+func diff(a, b uint32) uint32 {
+	if a > b {
+		return a - b
+	}
+	return b - a
+}
+
+func TestCard_Size(t *testing.T) {
+	card := Stats(profile, stats, Options{Scale: 2})
+	w, h := card.Size()
+	assert.Equal(t, 495, w)
+	assert.Equal(t, 195, h)
+	assert.Equal(t, image.Rect(0, 0, 990, 390), card.Image().Bounds())
+	assert.Equal(t, "OGKevin's wavy.fm stats", card.Title())
+
+	assert.Equal(t, "OGKevin is listening to Never Gonna Give You Up", NowPlaying(profile, current, Options{}).Title())
+
+	for _, scale := range []float64{MaxScale + 1, math.Inf(1)} {
+		assert.Equal(t, image.Rect(0, 0, 495*MaxScale, 195*MaxScale), Stats(profile, stats, Options{Scale: scale}).Image().Bounds(), scale)
+	}
+	assert.Equal(t, image.Rect(0, 0, 495, 195), Stats(profile, stats, Options{Scale: math.NaN()}).Image().Bounds())
+}
+
+func Test_formatCount(t *testing.T) {
+	assert.Equal(t, "0", formatCount(0))
+	assert.Equal(t, "999", formatCount(999))
+	assert.Equal(t, "1,000", formatCount(1000))
+	assert.Equal(t, "120,334", formatCount(120334))
+	assert.Equal(t, "-1,234,567", formatCount(-1234567))
+}
+
+func Test_truncate(t *testing.T) {
+	assert.Equal(t, "short", truncate("short", 14, false, 100))
+
+	long := truncate("a rather long song name which does not fit", 14, false, 100)
+	assert.True(t, measure(long, 14, false) <= 100)
+	assert.Equal(t, "…", long[len(long)-len("…"):])
+}
+
+func Test_escapeText(t *testing.T) {
+	assert.Equal(t, "a &lt;b&gt; &amp; &#34;c&#34;", escapeText(`a <b> & "c"`))
+	assert.Equal(t, "tab\tnew\nline", escapeText("tab\tnew\nline"))
+	assert.Equal(t, "bell and nul, 🎵", escapeText("bell\a and nul\x00, \uFFFE🎵"))
+
+	var svg bytes.Buffer
+	name := "Song\x1b[31m\x00"
+	card := NowPlaying(profile, &wavy.GetCurrentResponse{Item: wavy.CurrentPlayingItem{Song: wavy.Song{Name: name}}}, Options{})
+	require.NoError(t, card.WriteSVG(&svg))
+	assert.NoError(t, xml.Unmarshal(svg.Bytes(), new(struct{})))
+	assert.NotContains(t, svg.String(), "\x1b")
+}
@@ -0,0 +1,131 @@
+package cards
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"math"
+
+	xdraw "golang.org/x/image/draw"
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+	"golang.org/x/image/vector"
+)
+
+// kappa places the control points of a cubic Bézier curve approximating a quarter circle.
+const kappa = 0.5522847498
+
+// Image rasterizes c, with Options.Scale pixels per SVG unit.
+func (c *Card) Image() *image.RGBA {
+	s := c.scale
+	dst := image.NewRGBA(image.Rect(0, 0, int(math.Ceil(float64(c.width)*s)), int(math.Ceil(float64(c.height)*s))))
+
+	for _, e := range c.elements {
+		switch e := e.(type) {
+		case rect:
+			if e.strokeWidth > 0 {
+				fillPath(dst, e.stroke, func(z *vector.Rasterizer) {
+					addRoundedRect(z, e.x*s, e.y*s, e.w*s, e.h*s, e.radius*s)
+				})
+				inset := e.strokeWidth
+				fillPath(dst, e.fill, func(z *vector.Rasterizer) {
+					addRoundedRect(z, (e.x+inset)*s, (e.y+inset)*s, (e.w-2*inset)*s, (e.h-2*inset)*s, math.Max(0, e.radius-inset)*s)
+				})
+				continue
+			}
+			fillPath(dst, e.fill, func(z *vector.Rasterizer) {
+				addRoundedRect(z, e.x*s, e.y*s, e.w*s, e.h*s, e.radius*s)
+			})
+		case circle:
+			fillPath(dst, e.fill, func(z *vector.Rasterizer) {
+				addCircle(z, e.cx*s, e.cy*s, e.r*s)
+			})
+		case text:
+			drawText(dst, e, s)
+		case picture:
+			drawPicture(dst, e, s)
+		}
+	}
+	return dst
+}
+
+// WritePNG writes c rasterized as PNG to w.
+func (c *Card) WritePNG(w io.Writer) error {
+	return png.Encode(w, c.Image())
+}
+
+// fillPath fills the path added by path with fill.
+func fillPath(dst draw.Image, fill color.RGBA, path func(z *vector.Rasterizer)) {
+	b := dst.Bounds()
+	z := vector.NewRasterizer(b.Dx(), b.Dy())
+	path(z)
+	z.Draw(dst, b, image.NewUniform(fill), image.Point{})
+}
+
+func addRoundedRect(z *vector.Rasterizer, x, y, w, h, r float64) {
+	r = math.Min(r, math.Min(w, h)/2)
+	k := r * (1 - kappa)
+
+	z.MoveTo(float32(x+r), float32(y))
+	z.LineTo(float32(x+w-r), float32(y))
+	z.CubeTo(float32(x+w-k), float32(y), float32(x+w), float32(y+k), float32(x+w), float32(y+r))
+	z.LineTo(float32(x+w), float32(y+h-r))
+	z.CubeTo(float32(x+w), float32(y+h-k), float32(x+w-k), float32(y+h), float32(x+w-r), float32(y+h))
+	z.LineTo(float32(x+r), float32(y+h))
+	z.CubeTo(float32(x+k), float32(y+h), float32(x), float32(y+h-k), float32(x), float32(y+h-r))
+	z.LineTo(float32(x), float32(y+r))
+	z.CubeTo(float32(x), float32(y+k), float32(x+k), float32(y), float32(x+r), float32(y))
+	z.ClosePath()
+}
+
+func addCircle(z *vector.Rasterizer, cx, cy, r float64) {
+	addRoundedRect(z, cx-r, cy-r, 2*r, 2*r, r)
+}
+
+func drawText(dst draw.Image, t text, s float64) {
+	withFace(t.size*s, t.bold, func(f font.Face) {
+		x := t.x * s
+		if t.centered {
+			x -= fixedToFloat(font.MeasureString(f, t.s)) / 2
+		}
+		d := font.Drawer{
+			Dst:  dst,
+			Src:  image.NewUniform(t.fill),
+			Face: f,
+			Dot:  fixed.Point26_6{X: fixed.Int26_6(math.Round(x * 64)), Y: fixed.Int26_6(math.Round(t.y * s * 64))},
+		}
+		d.DrawString(t.s)
+	})
+}
+
+func drawPicture(dst *image.RGBA, p picture, s float64) {
+	r := image.Rect(int(math.Round(p.x*s)), int(math.Round(p.y*s)), int(math.Round((p.x+p.w)*s)), int(math.Round((p.y+p.h)*s)))
+	src := cover(p.img, r.Dx(), r.Dy())
+
+	b := dst.Bounds()
+	mask := image.NewAlpha(b)
+	z := vector.NewRasterizer(b.Dx(), b.Dy())
+	addRoundedRect(z, float64(r.Min.X), float64(r.Min.Y), float64(r.Dx()), float64(r.Dy()), p.radius*s)
+	z.Draw(mask, b, image.Opaque, image.Point{})
+
+	draw.DrawMask(dst, r, src, image.Point{}, mask, r.Min, draw.Over)
+}
+
+// cover scales img to fill w x h pixels, cropping its center to the aspect ratio of w x h.
+func cover(img image.Image, w, h int) *image.RGBA {
+	b := img.Bounds()
+	crop := b
+	if b.Dx()*h > b.Dy()*w {
+		cw := b.Dy() * w / h
+		crop = image.Rect(0, 0, cw, b.Dy()).Add(b.Min).Add(image.Pt((b.Dx()-cw)/2, 0))
+	} else {
+		ch := b.Dx() * h / w
+		crop = image.Rect(0, 0, b.Dx(), ch).Add(b.Min).Add(image.Pt(0, (b.Dy()-ch)/2))
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	xdraw.CatmullRom.Scale(dst, dst.Bounds(), img, crop, draw.Src, nil)
+	return dst
+}
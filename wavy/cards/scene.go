@@ -0,0 +1,119 @@
+package cards
+
+import (
+	"image"
+	"image/color"
+	"sync"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+// The elements of a card, drawn in order. Coordinates are in SVG units.
+type (
+	rect struct {
+		x, y, w, h, radius float64
+		fill               color.RGBA
+		// stroke is drawn inside the rectangle when strokeWidth is set.
+		stroke      color.RGBA
+		strokeWidth float64
+	}
+
+	circle struct {
+		cx, cy, r float64
+		fill      color.RGBA
+	}
+
+	text struct {
+		// x, y is the start of the baseline, or its middle when centered.
+		x, y     float64
+		size     float64
+		bold     bool
+		centered bool
+		fill     color.RGBA
+		s        string
+	}
+
+	// picture is an image scaled to fill its rectangle, cropped to rounded corners.
+	picture struct {
+		x, y, w, h, radius float64
+		img                image.Image
+	}
+)
+
+var (
+	regular = mustParseFont(goregular.TTF)
+	bold    = mustParseFont(gobold.TTF)
+
+	facesMu sync.Mutex
+	faces   = map[faceKey]font.Face{}
+)
+
+func mustParseFont(ttf []byte) *opentype.Font {
+	f, err := opentype.Parse(ttf)
+	if err != nil {
+		panic(err)
+	}
+	return f
+}
+
+type faceKey struct {
+	size float64
+	bold bool
+}
+
+// withFace calls fn with the Go font face of size pixels. Faces are shared between cards, but not safe for
+// concurrent use, so fn holds a lock.
+func withFace(size float64, isBold bool, fn func(f font.Face)) {
+	facesMu.Lock()
+	defer facesMu.Unlock()
+
+	key := faceKey{size: size, bold: isBold}
+	f, ok := faces[key]
+	if !ok {
+		src := regular
+		if isBold {
+			src = bold
+		}
+		var err error
+		f, err = opentype.NewFace(src, &opentype.FaceOptions{Size: size, DPI: 72, Hinting: font.HintingNone})
+		if err != nil {
+			panic(err)
+		}
+		faces[key] = f
+	}
+	fn(f)
+}
+
+// measure returns the width of s in the Go font.
+func measure(s string, size float64, isBold bool) float64 {
+	var width fixed.Int26_6
+	withFace(size, isBold, func(f font.Face) {
+		width = font.MeasureString(f, s)
+	})
+	return fixedToFloat(width)
+}
+
+// truncate shortens s with an ellipsis to fit width.
+func truncate(s string, size float64, isBold bool, width float64) string {
+	if measure(s, size, isBold) <= width {
+		return s
+	}
+
+	runes := []rune(s)
+	for len(runes) > 0 {
+		runes = runes[:len(runes)-1]
+		candidate := string(runes) + "…"
+		if measure(candidate, size, isBold) <= width {
+			return candidate
+		}
+	}
+	return ""
+}
+
+func fixedToFloat(x fixed.Int26_6) float64 {
+	return float64(x) / 64
+}
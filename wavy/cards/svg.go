@@ -0,0 +1,89 @@
+package cards
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"html"
+	"image/png"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// fontFamily lists the fonts of SVG text, starting with the Go font texts are measured in.
+const fontFamily = "Go, 'Segoe UI', Ubuntu, Helvetica, Arial, sans-serif"
+
+// WriteSVG writes c as SVG to w. Images are embedded, so the SVG does not load anything.
+func (c *Card) WriteSVG(w io.Writer) error {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" role="img" aria-label="%s">`+"\n",
+		c.width, c.height, c.width, c.height, escapeText(c.title))
+	fmt.Fprintf(&b, "<title>%s</title>\n", escapeText(c.title))
+
+	clips := 0
+	for _, e := range c.elements {
+		switch e := e.(type) {
+		case rect:
+			if e.strokeWidth > 0 {
+				// SVG strokes are centered on the outline, cards draw them inside.
+				half := e.strokeWidth / 2
+				fmt.Fprintf(&b, `<rect x="%s" y="%s" width="%s" height="%s" rx="%s" fill="%s" stroke="%s" stroke-width="%s"/>`+"\n",
+					num(e.x+half), num(e.y+half), num(e.w-e.strokeWidth), num(e.h-e.strokeWidth), num(math.Max(0, e.radius-half)),
+					hex(e.fill), hex(e.stroke), num(e.strokeWidth))
+				continue
+			}
+			fmt.Fprintf(&b, `<rect x="%s" y="%s" width="%s" height="%s" rx="%s" fill="%s"/>`+"\n",
+				num(e.x), num(e.y), num(e.w), num(e.h), num(e.radius), hex(e.fill))
+		case circle:
+			fmt.Fprintf(&b, `<circle cx="%s" cy="%s" r="%s" fill="%s"/>`+"\n", num(e.cx), num(e.cy), num(e.r), hex(e.fill))
+		case text:
+			weight, anchor := "normal", "start"
+			if e.bold {
+				weight = "bold"
+			}
+			if e.centered {
+				anchor = "middle"
+			}
+			fmt.Fprintf(&b, `<text x="%s" y="%s" font-family="%s" font-size="%s" font-weight="%s" text-anchor="%s" fill="%s">%s</text>`+"\n",
+				num(e.x), num(e.y), fontFamily, num(e.size), weight, anchor, hex(e.fill), escapeText(e.s))
+		case picture:
+			clips++
+			var img bytes.Buffer
+			// Embedded at twice the size to stay sharp on high density displays.
+			if err := png.Encode(&img, cover(e.img, int(math.Ceil(e.w*2)), int(math.Ceil(e.h*2)))); err != nil {
+				return fmt.Errorf("cards: failed to encode image: %w", err)
+			}
+			fmt.Fprintf(&b, `<clipPath id="clip%d"><rect x="%s" y="%s" width="%s" height="%s" rx="%s"/></clipPath>`+"\n",
+				clips, num(e.x), num(e.y), num(e.w), num(e.h), num(e.radius))
+			fmt.Fprintf(&b, `<image x="%s" y="%s" width="%s" height="%s" preserveAspectRatio="xMidYMid slice" clip-path="url(#clip%d)" href="data:image/png;base64,%s"/>`+"\n",
+				num(e.x), num(e.y), num(e.w), num(e.h), clips, base64.StdEncoding.EncodeToString(img.Bytes()))
+		}
+	}
+
+	b.WriteString("</svg>\n")
+	_, err := w.Write(b.Bytes())
+	return err
+}
+
+// escapeText escapes s for SVG text and attributes. Runes which are not allowed in XML 1.0, such as most control
+// characters, are dropped, as they make the whole document invalid.
+func escapeText(s string) string {
+	return html.EscapeString(strings.Map(func(r rune) rune {
+		switch {
+		case r == '\t' || r == '\n' || r == '\r',
+			r >= 0x20 && r <= 0xD7FF,
+			r >= 0xE000 && r <= 0xFFFD,
+			r >= 0x10000 && r <= unicode.MaxRune:
+			return r
+		}
+		return -1
+	}, s))
+}
+
+// num formats a coordinate without trailing zeros.
+func num(f float64) string {
+	return strconv.FormatFloat(math.Round(f*100)/100, 'f', -1, 64)
+}
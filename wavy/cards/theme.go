@@ -0,0 +1,88 @@
+package cards
+
+import (
+	"fmt"
+	"image/color"
+	"sort"
+)
+
+// Theme holds the colors of a card.
+type Theme struct {
+	Name       string
+	Background color.RGBA
+	Border     color.RGBA
+	Title      color.RGBA
+	Text       color.RGBA
+	Muted      color.RGBA
+	Accent     color.RGBA
+}
+
+var (
+	// ThemeLight is dark text on white, the default theme.
+	ThemeLight = Theme{
+		Name:       "light",
+		Background: rgb(0xffffff),
+		Border:     rgb(0xe4e2e2),
+		Title:      rgb(0x5b2a86),
+		Text:       rgb(0x333333),
+		Muted:      rgb(0x6b6b6b),
+		Accent:     rgb(0x9b5de5),
+	}
+	// ThemeDark is light text on black.
+	ThemeDark = Theme{
+		Name:       "dark",
+		Background: rgb(0x151515),
+		Border:     rgb(0x2a2a2a),
+		Title:      rgb(0xc9a7ff),
+		Text:       rgb(0xe6e6e6),
+		Muted:      rgb(0x9a9a9a),
+		Accent:     rgb(0x9b5de5),
+	}
+	// ThemeVapor is pink and cyan on purple.
+	ThemeVapor = Theme{
+		Name:       "vapor",
+		Background: rgb(0x1b1035),
+		Border:     rgb(0x3b2a6b),
+		Title:      rgb(0xff71ce),
+		Text:       rgb(0xffffff),
+		Muted:      rgb(0xb9a8e6),
+		Accent:     rgb(0x01cdfe),
+	}
+)
+
+var themes = map[string]Theme{
+	ThemeLight.Name: ThemeLight,
+	ThemeDark.Name:  ThemeDark,
+	ThemeVapor.Name: ThemeVapor,
+}
+
+// ThemeByName returns the theme called name, e.g. "dark". An empty name selects ThemeLight.
+func ThemeByName(name string) (Theme, error) {
+	if name == "" {
+		return ThemeLight, nil
+	}
+	theme, ok := themes[name]
+	if !ok {
+		return Theme{}, fmt.Errorf("cards: unknown theme %q, expected one of %v", name, ThemeNames())
+	}
+	return theme, nil
+}
+
+// ThemeNames returns the names of the built in themes, sorted.
+func ThemeNames() []string {
+	names := make([]string, 0, len(themes))
+	for name := range themes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func rgb(hex uint32) color.RGBA {
+	return color.RGBA{R: uint8(hex >> 16), G: uint8(hex >> 8), B: uint8(hex), A: 0xff}
+}
+
+// hex formats c as css color.
+func hex(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
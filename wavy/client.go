@@ -4,16 +4,17 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
+	"strings"
+	"time"
 
+	"github.com/OGKevin/go-wavy/wavy/auth"
 	"github.com/hashicorp/go-hclog"
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/clientcredentials"
-)
-
-const (
-	wavyBaseUrl = "https://wavy.fm/api/v1beta"
+	"golang.org/x/time/rate"
 )
 
 // Client
@@ -30,8 +31,70 @@ type Client interface {
 }
 
 type client struct {
-	c      *http.Client
-	logger hclog.Logger
+	c       *http.Client
+	baseURL string
+	logger  Logger
+
+	tokenCache   auth.TokenCache
+	defaults     []CallOption
+	redaction    RedactionPolicy
+	limiter      *rate.Limiter
+	transport    http.RoundTripper
+	avatars      *avatarCache
+	deprecations deprecationWarner
+}
+
+// ClientOption configures the Client returned by NewClient.
+type ClientOption func(c *client)
+
+// WithBaseURL sends requests to baseURL instead of wavy.fm, e.g. to go through a proxy.
+// baseURL includes the api version, such as https://proxy.example/wavy/api/v1beta.
+func WithBaseURL(baseURL string) ClientOption {
+	return func(c *client) {
+		c.baseURL = strings.TrimSuffix(baseURL, "/")
+	}
+}
+
+// WithTokenCache shares the client credentials tokens of NewClient through cache, so clients of the same
+// application reuse a token until shortly before it expires instead of each fetching their own.
+// Use auth.NewFileCache to share tokens between processes. NewUserClient ignores it.
+func WithTokenCache(cache auth.TokenCache) ClientOption {
+	return func(c *client) {
+		c.tokenCache = cache
+	}
+}
+
+// WithDefaultCallOptions applies opts to every call of the client, before the options passed to the call.
+func WithDefaultCallOptions(opts ...CallOption) ClientOption {
+	return func(c *client) {
+		c.defaults = append(c.defaults, opts...)
+	}
+}
+
+// WithRateLimit limits the client to perform requestsPerSecond requests on average, with bursts of up to burst
+// requests. Calls wait for their turn, every retry of a call counts as a request.
+func WithRateLimit(requestsPerSecond float64, burst int) ClientOption {
+	return func(c *client) {
+		if burst < 1 {
+			burst = 1
+		}
+		c.limiter = rate.NewLimiter(rate.Limit(requestsPerSecond), burst)
+	}
+}
+
+// WithTransport sends the requests of the client, including token requests, through rt instead of
+// http.DefaultTransport, e.g. a recorder.Recorder to replay recorded interactions in tests.
+func WithTransport(rt http.RoundTripper) ClientOption {
+	return func(c *client) {
+		c.transport = rt
+	}
+}
+
+// callOptions combines the default call options of the client with opts.
+func (c *client) callOptions(opts []CallOption) *callOptions {
+	all := make([]CallOption, 0, len(c.defaults)+len(opts))
+	all = append(all, c.defaults...)
+	return newCallOptions(append(all, opts...))
 }
 
 func (c *client) UserService() UserService {
@@ -42,33 +105,72 @@ func (c *client) MetricsService() MetricsService {
 	return newMetricsService(c, c.logger)
 }
 
-func NewClient(ctx context.Context, logger hclog.Logger, clientID, clientSecret string) Client {
+// NewClient returns a Client authenticated with the client credentials of a wavy.fm application.
+// It can only access public data, use NewUserClient to act on behalf of a user.
+func NewClient(ctx context.Context, logger Logger, clientID, clientSecret string, opts ...ClientOption) Client {
+	c := newClient(logger, opts)
+	ctx = c.transportContext(ctx)
+
+	conf := &clientcredentials.Config{
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		TokenURL:     fmt.Sprintf("%s/token", c.baseURL),
+		AuthStyle:    oauth2.AuthStyleInHeader,
+	}
+
+	var src oauth2.TokenSource = tokenSourceFunc(func() (*oauth2.Token, error) {
+		return conf.Token(ctx)
+	})
+	if c.tokenCache != nil {
+		key := fmt.Sprintf("%s %s", conf.TokenURL, clientID)
+		src = auth.CachedTokenSource(ctx, c.tokenCache, key, src, auth.CacheOptions{Logger: c.logger.Named("token-cache")})
+	} else {
+		src = oauth2.ReuseTokenSource(nil, src)
+	}
+	c.c = newTokenClient(ctx, src)
+
+	return c
+}
+
+// NewUserClient returns a Client which acts on behalf of the user who granted the tokens of src.
+// See the auth package to obtain and refresh user tokens.
+func NewUserClient(ctx context.Context, logger Logger, src oauth2.TokenSource, opts ...ClientOption) Client {
+	c := newClient(logger, opts)
+	c.c = newTokenClient(c.transportContext(ctx), src)
+
+	return c
+}
+
+func newClient(logger Logger, opts []ClientOption) *client {
 	if logger == nil {
-		logger = hclog.New(&hclog.LoggerOptions{
+		logger = NewHCLogLogger(hclog.New(&hclog.LoggerOptions{
 			Name:  "go-wavy",
 			Level: hclog.Warn,
-		})
+		}))
 	} else {
 		logger = logger.Named("go-wavy")
 	}
 
 	c := &client{
-		logger: logger,
+		baseURL: DefaultAPIVersion.baseURL(),
+		logger:  logger,
+		avatars: newAvatarCache(DefaultAvatarCacheSize, DefaultAvatarCacheTTL),
 	}
-
-	conf := &clientcredentials.Config{
-		ClientID:     clientID,
-		ClientSecret: clientSecret,
-		TokenURL:     fmt.Sprintf("%s/token", wavyBaseUrl),
-		AuthStyle:    oauth2.AuthStyleInHeader,
+	for _, opt := range opts {
+		opt(c)
 	}
 
-	httpClient := conf.Client(ctx)
-	c.c = httpClient
-
 	return c
 }
 
+// transportContext returns ctx carrying the transport of WithTransport for the oauth2 package.
+func (c *client) transportContext(ctx context.Context) context.Context {
+	if c.transport == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, oauth2.HTTPClient, &http.Client{Transport: c.transport})
+}
+
 // ApiError defines the error object returned by wavy api.
 // For more info see: https://wavy.fm/developers/docs/v1beta/errors
 type ApiError struct {
@@ -82,22 +184,35 @@ func (a *ApiError) Error() string {
 	return fmt.Sprintf("%d: %s", a.Status, a.Name)
 }
 
-func (c *client) do(req *http.Request) (*http.Response, error) {
-	c.logger.Trace("processing request", "url", req.URL.String())
-	defer c.logger.Trace("finished processing request", "url", req.URL.String())
+func (c *client) do(req *http.Request, o *callOptions) (*http.Response, error) {
+	logger := o.loggerOr(c.logger)
+	logger.Trace("processing request", "url", c.redaction.URL(req.URL))
+	defer logger.Trace("finished processing request", "url", c.redaction.URL(req.URL))
 
-	url, err := url.Parse(fmt.Sprintf("%s%s", wavyBaseUrl, req.URL.Path))
+	reqURL, err := joinURL(c.baseURL, req.URL)
 	if err != nil {
 		return nil, fmt.Errorf("%s: failed to parse request url: %w", c.logger.Name(), err)
 	}
-	req.URL = url
+	req.URL = reqURL
 
-	res, err := c.c.Do(req)
+	res, err := c.send(c.c, req, o, logger)
 	if err != nil {
-		return nil, fmt.Errorf("%s: falied to execute request: %w", c.logger.Name(), err)
+		return nil, err
+	}
+
+	route := o.route
+	if route == "" {
+		route = c.redaction.URL(&url.URL{Path: req.URL.Path})
+	}
+	c.deprecations.warn(logger, route, newDeprecation(res.Header))
+
+	if o.response != nil {
+		*o.response = *newResponse(res)
 	}
 
 	if res.StatusCode > 399 {
+		defer res.Body.Close()
+
 		var apiErr ApiError
 		err := json.NewDecoder(res.Body).Decode(&apiErr)
 		if err != nil {
@@ -109,10 +224,108 @@ func (c *client) do(req *http.Request) (*http.Response, error) {
 	return res, nil
 }
 
-func (c *client) get(url string) (resp *http.Response, err error) {
-	req, err := http.NewRequest("GET", url, nil)
+// send sends req with hc, retrying it according to the retry policy of o. Every attempt waits for the rate limit
+// of c, and is logged and dumped according to o.
+func (c *client) send(hc *http.Client, req *http.Request, o *callOptions, logger Logger) (*http.Response, error) {
+	for key, values := range o.header {
+		req.Header[key] = values
+	}
+
+	attempts := 1
+	if o.retry != nil && o.retry.MaxAttempts > 1 {
+		attempts = o.retry.MaxAttempts
+	}
+
+	var (
+		res *http.Response
+		err error
+	)
+	for attempt := 1; ; attempt++ {
+		if c.limiter != nil {
+			if err := c.limiter.Wait(req.Context()); err != nil {
+				return nil, fmt.Errorf("%s: failed to wait for rate limit: %w", c.logger.Name(), err)
+			}
+		}
+
+		start := time.Now()
+		res, err = hc.Do(req)
+		if o.dump != nil {
+			c.redaction.dump(o.dump, req, res)
+		}
+		logger.Debug("request attempt finished", attemptFields(attempt, start, res, c.redaction.Error(err))...)
+		if attempt >= attempts || !o.retry.shouldRetry(res, err) {
+			break
+		}
+
+		wait := o.retry.backoff(attempt, res)
+		if deadline, ok := req.Context().Deadline(); ok && time.Now().Add(wait).After(deadline) {
+			logger.Debug("not retrying request, the wait exceeds the deadline", "url", c.redaction.URL(req.URL), "attempt", attempt, "wait", wait)
+			break
+		}
+		if res != nil {
+			res.Body.Close()
+		}
+		logger.Debug("retrying request", "url", c.redaction.URL(req.URL), "attempt", attempt, "wait", wait)
+
+		select {
+		case <-req.Context().Done():
+			return nil, fmt.Errorf("%s: falied to execute request: %w", c.logger.Name(), req.Context().Err())
+		case <-time.After(wait):
+		}
+	}
 	if err != nil {
+		return nil, fmt.Errorf("%s: falied to execute request: %w", c.logger.Name(), err)
+	}
+
+	return res, nil
+}
+
+// attemptFields returns the structured log fields describing an attempt of a request.
+func attemptFields(attempt int, start time.Time, res *http.Response, err error) []interface{} {
+	fields := []interface{}{"attempt", attempt, "duration", time.Since(start)}
+	if res != nil {
+		fields = append(fields, "status", res.StatusCode)
+	}
+	if err != nil {
+		fields = append(fields, "error", err)
+	}
+	return fields
+}
+
+// get requests url. The caller must close the body of the response, which also releases the timeout of the call.
+func (c *client) get(ctx context.Context, url string, o *callOptions) (resp *http.Response, err error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	cancel := func() {}
+	if o.timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, o.timeout)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	res, err := c.do(req, o)
+	if err != nil {
+		cancel()
 		return nil, err
 	}
-	return c.do(req)
+
+	res.Body = &cancelOnClose{ReadCloser: res.Body, cancel: cancel}
+	return res, nil
+}
+
+// cancelOnClose releases the context of a call once its response body is closed.
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (c *cancelOnClose) Close() error {
+	defer c.cancel()
+	return c.ReadCloser.Close()
 }
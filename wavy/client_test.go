@@ -3,18 +3,60 @@ package wavy
 import (
 	"context"
 	"fmt"
-	"os"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
 	"testing"
+	"time"
 
+	"github.com/OGKevin/go-wavy/wavy/recorder"
 	"github.com/hashicorp/go-hclog"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
 )
 
-func ExampleNewClient() {
+// newRecordedClient returns a client replaying the cassette of the test from testdata/cassettes.
+// Record them with WAVY_RECORDER_MODE=record and credentials resolved by LoadConfig, e.g. WAVY_CLIENT_ID and
+// WAVY_CLIENT_SECRET. Calls decode strictly, so recording fails when the api drifted from the vendored schema.
+func newRecordedClient(t *testing.T, ctx context.Context) Client {
+	t.Helper()
+
+	mode, err := recorder.ModeFromEnv(recorder.ModeReplay)
+	require.NoError(t, err)
+
+	cfg, err := LoadConfig()
+	require.NoError(t, err)
+	if mode == recorder.ModeReplay {
+		// The cassettes hold no credentials, and were recorded against the default api of wavy.fm.
+		cfg.ClientID, cfg.ClientSecret = "replay", "replay"
+		cfg.BaseURL = ""
+	}
+
+	rec, err := recorder.New(filepath.Join("testdata", "cassettes", t.Name()+".json"), mode, recorder.Options{})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		assert.NoError(t, rec.Stop())
+	})
+
+	c, err := NewClientFromConfig(ctx, NopLogger(), cfg, WithTransport(rec), WithDefaultCallOptions(WithStrictDecoding()))
+	require.NoError(t, err)
+	return c
+}
+
+func ExampleNewClientFromConfig() {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	c := NewClient(ctx, hclog.NewNullLogger(), os.Getenv("CLIENT_ID"), os.Getenv("CLIENT_SECRET"))
+	cfg, err := LoadConfig()
+	if err != nil {
+		panic(err)
+	}
+	c, err := NewClientFromConfig(ctx, NopLogger(), cfg)
+	if err != nil {
+		panic(err)
+	}
+
 	profile, err := c.UserService().GetProfile(ctx, UserURI{Username: "OGKevin"})
 	if err != nil {
 		panic(err)
@@ -26,7 +68,7 @@ func ExampleNewClient() {
 func TestNewClient(t *testing.T) {
 	type args struct {
 		ctx          context.Context
-		logger       hclog.Logger
+		logger       Logger
 		clientID     string
 		clientSecret string
 	}
@@ -40,9 +82,9 @@ func TestNewClient(t *testing.T) {
 			name: "",
 			args: args{
 				ctx:          context.Background(),
-				logger:       hclog.New(&hclog.LoggerOptions{Level: hclog.Trace}),
-				clientID:     os.Getenv("CLIENT_ID"),
-				clientSecret: os.Getenv("CLIENT_SECRET"),
+				logger:       NewHCLogLogger(hclog.New(&hclog.LoggerOptions{Level: hclog.Trace})),
+				clientID:     "client-id",
+				clientSecret: "client-secret",
 			},
 			wantErr: false,
 		},
@@ -64,3 +106,62 @@ func TestNewClient(t *testing.T) {
 		})
 	}
 }
+
+// newTestClient returns a client which sends its requests to handler instead of wavy.fm.
+func newTestClient(t *testing.T, handler http.Handler) *client {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	return &client{
+		c:       server.Client(),
+		baseURL: server.URL,
+		logger:  NopLogger(),
+	}
+}
+
+func TestNewUserClient(t *testing.T) {
+	var authorization string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		w.Write([]byte(`{"uri": "wavyfm:user:username:OGKevin"}`))
+	}))
+	defer server.Close()
+
+	src := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "user-token"})
+	c := NewUserClient(context.Background(), NopLogger(), src, WithBaseURL(server.URL+"/api/v1beta/"))
+
+	profile, err := c.UserService().GetProfile(context.Background(), UserURI{Username: "OGKevin"})
+	require.NoError(t, err)
+	assert.Equal(t, "wavyfm:user:username:OGKevin", profile.URI)
+	assert.Equal(t, "Bearer user-token", authorization)
+}
+
+func TestWithRateLimit(t *testing.T) {
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("42"))
+	}))
+	WithRateLimit(1, 1)(c)
+	m := newMetricsService(c, c.logger)
+
+	_, err := m.GetTotalListens(context.Background())
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = m.GetTotalListens(ctx)
+	assert.Error(t, err)
+}
+
+func TestWithDefaultCallOptions(t *testing.T) {
+	var got http.Header
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header
+		w.Write([]byte("42"))
+	}))
+	WithDefaultCallOptions(WithHeader("X-Default", "a"), WithSkipCache())(c)
+
+	_, err := newMetricsService(c, c.logger).GetTotalListens(context.Background(), WithForceRefresh())
+	require.NoError(t, err)
+	assert.Equal(t, "a", got.Get("X-Default"))
+	assert.Equal(t, "no-cache", got.Get("Cache-Control"))
+}
@@ -0,0 +1,331 @@
+package wavy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/hashicorp/go-hclog"
+)
+
+// DefaultProfile is the profile of the config file used when no profile is selected.
+const DefaultProfile = "default"
+
+// Config holds the settings of a Client, resolved by LoadConfig.
+type Config struct {
+	// Profile is the profile of the config file the settings were read from.
+	Profile      string
+	ClientID     string
+	ClientSecret string
+	// BaseURL defaults to the base url of DefaultAPIVersion.
+	BaseURL string
+	// LogLevel is used by NewClientFromConfig when it creates the logger, e.g. "warn".
+	LogLevel string
+	// Retry is applied to every call when MaxAttempts is above 1. Backoffs default to those of DefaultRetryPolicy.
+	Retry RetryPolicy
+	// RateLimit is the amount of requests per second, 0 means unlimited. RateBurst defaults to 1.
+	RateLimit float64
+	RateBurst int
+}
+
+// fileProfile is a profile of the config file. Durations are strings such as "500ms".
+type fileProfile struct {
+	ClientID     string `toml:"client_id"`
+	ClientSecret string `toml:"client_secret"`
+	BaseURL      string `toml:"base_url"`
+	LogLevel     string `toml:"log_level"`
+	Retry        struct {
+		MaxAttempts int    `toml:"max_attempts"`
+		MinBackoff  string `toml:"min_backoff"`
+		MaxBackoff  string `toml:"max_backoff"`
+	} `toml:"retry"`
+	RateLimit float64 `toml:"rate_limit"`
+	RateBurst int     `toml:"rate_burst"`
+}
+
+// ConfigOption changes how LoadConfig resolves a Config.
+type ConfigOption func(l *configLoader)
+
+type configLoader struct {
+	path         string
+	pathRequired bool
+	profile      string
+	overrides    Config
+}
+
+// FromFile reads path instead of the default config file. Unlike the default file, path must exist.
+func FromFile(path string) ConfigOption {
+	return func(l *configLoader) {
+		l.path = path
+		l.pathRequired = true
+	}
+}
+
+// FromProfile selects a profile of the config file. It must exist, unless it is DefaultProfile.
+func FromProfile(profile string) ConfigOption {
+	return func(l *configLoader) {
+		l.profile = profile
+	}
+}
+
+// WithOverrides takes precedence over every other source, for the non zero fields of overrides.
+func WithOverrides(overrides Config) ConfigOption {
+	return func(l *configLoader) {
+		l.overrides = overrides
+	}
+}
+
+// DefaultConfigPath returns the path of the config file read by LoadConfig, $XDG_CONFIG_HOME/wavy/config.toml
+// or ~/.config/wavy/config.toml.
+func DefaultConfigPath() (string, error) {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "wavy", "config.toml"), nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".config", "wavy", "config.toml"), nil
+}
+
+// LoadConfig resolves the settings of a Client. Later sources take precedence over earlier ones:
+//
+//  1. the defaults: log level warn
+//  2. the [default] profile of the config file, see DefaultConfigPath, FromFile and the WAVY_CONFIG variable
+//  3. the selected profile, see FromProfile and the WAVY_PROFILE variable
+//  4. the environment: WAVY_CLIENT_ID (or CLIENT_ID), WAVY_CLIENT_SECRET (or CLIENT_SECRET), WAVY_BASE_URL,
+//     WAVY_LOG_LEVEL, WAVY_RETRY_MAX_ATTEMPTS, WAVY_RETRY_MIN_BACKOFF, WAVY_RETRY_MAX_BACKOFF,
+//     WAVY_RATE_LIMIT and WAVY_RATE_BURST
+//  5. WithOverrides
+//
+// A profile of the config file looks like:
+//
+//	[default]
+//	client_id = "..."
+//	client_secret = "..."
+//	log_level = "info"
+//	rate_limit = 5
+//
+//	[default.retry]
+//	max_attempts = 3
+//	min_backoff = "500ms"
+//	max_backoff = "10s"
+func LoadConfig(opts ...ConfigOption) (*Config, error) {
+	l := &configLoader{
+		path:    os.Getenv("WAVY_CONFIG"),
+		profile: os.Getenv("WAVY_PROFILE"),
+	}
+	l.pathRequired = l.path != ""
+	for _, opt := range opts {
+		opt(l)
+	}
+	if l.profile == "" {
+		l.profile = DefaultProfile
+	}
+
+	cfg := &Config{
+		Profile:  l.profile,
+		LogLevel: "warn",
+	}
+
+	if err := l.loadFile(cfg); err != nil {
+		return nil, err
+	}
+	if err := loadEnv(cfg); err != nil {
+		return nil, err
+	}
+	cfg.merge(l.overrides)
+
+	return cfg, nil
+}
+
+func (l *configLoader) loadFile(cfg *Config) error {
+	path := l.path
+	if path == "" {
+		var err error
+		if path, err = DefaultConfigPath(); err != nil {
+			return nil
+		}
+	}
+
+	var profiles map[string]fileProfile
+	if _, err := toml.DecodeFile(path, &profiles); err != nil {
+		if errors.Is(err, os.ErrNotExist) && !l.pathRequired {
+			if l.profile != DefaultProfile {
+				return fmt.Errorf("config profile %q not found, %s does not exist", l.profile, path)
+			}
+			return nil
+		}
+		return fmt.Errorf("failed to read config file %s: %w", path, err)
+	}
+
+	if p, ok := profiles[DefaultProfile]; ok {
+		if err := cfg.mergeProfile(p); err != nil {
+			return fmt.Errorf("invalid profile %q in %s: %w", DefaultProfile, path, err)
+		}
+	}
+	if l.profile == DefaultProfile {
+		return nil
+	}
+
+	p, ok := profiles[l.profile]
+	if !ok {
+		return fmt.Errorf("config profile %q not found in %s", l.profile, path)
+	}
+	if err := cfg.mergeProfile(p); err != nil {
+		return fmt.Errorf("invalid profile %q in %s: %w", l.profile, path, err)
+	}
+	return nil
+}
+
+func (c *Config) mergeProfile(p fileProfile) error {
+	other := Config{
+		ClientID:     p.ClientID,
+		ClientSecret: p.ClientSecret,
+		BaseURL:      p.BaseURL,
+		LogLevel:     p.LogLevel,
+		RateLimit:    p.RateLimit,
+		RateBurst:    p.RateBurst,
+	}
+	other.Retry.MaxAttempts = p.Retry.MaxAttempts
+
+	var err error
+	if other.Retry.MinBackoff, err = parseDuration("retry.min_backoff", p.Retry.MinBackoff); err != nil {
+		return err
+	}
+	if other.Retry.MaxBackoff, err = parseDuration("retry.max_backoff", p.Retry.MaxBackoff); err != nil {
+		return err
+	}
+
+	c.merge(other)
+	return nil
+}
+
+func loadEnv(cfg *Config) error {
+	env := Config{
+		ClientID:     firstEnv("WAVY_CLIENT_ID", "CLIENT_ID"),
+		ClientSecret: firstEnv("WAVY_CLIENT_SECRET", "CLIENT_SECRET"),
+		BaseURL:      os.Getenv("WAVY_BASE_URL"),
+		LogLevel:     os.Getenv("WAVY_LOG_LEVEL"),
+	}
+
+	var err error
+	if v := os.Getenv("WAVY_RETRY_MAX_ATTEMPTS"); v != "" {
+		if env.Retry.MaxAttempts, err = strconv.Atoi(v); err != nil {
+			return fmt.Errorf("invalid WAVY_RETRY_MAX_ATTEMPTS %q: %w", v, err)
+		}
+	}
+	if env.Retry.MinBackoff, err = parseDuration("WAVY_RETRY_MIN_BACKOFF", os.Getenv("WAVY_RETRY_MIN_BACKOFF")); err != nil {
+		return err
+	}
+	if env.Retry.MaxBackoff, err = parseDuration("WAVY_RETRY_MAX_BACKOFF", os.Getenv("WAVY_RETRY_MAX_BACKOFF")); err != nil {
+		return err
+	}
+	if v := os.Getenv("WAVY_RATE_LIMIT"); v != "" {
+		if env.RateLimit, err = strconv.ParseFloat(v, 64); err != nil {
+			return fmt.Errorf("invalid WAVY_RATE_LIMIT %q: %w", v, err)
+		}
+	}
+	if v := os.Getenv("WAVY_RATE_BURST"); v != "" {
+		if env.RateBurst, err = strconv.Atoi(v); err != nil {
+			return fmt.Errorf("invalid WAVY_RATE_BURST %q: %w", v, err)
+		}
+	}
+
+	cfg.merge(env)
+	return nil
+}
+
+// merge copies the non zero fields of other into c, except Profile.
+func (c *Config) merge(other Config) {
+	if other.ClientID != "" {
+		c.ClientID = other.ClientID
+	}
+	if other.ClientSecret != "" {
+		c.ClientSecret = other.ClientSecret
+	}
+	if other.BaseURL != "" {
+		c.BaseURL = other.BaseURL
+	}
+	if other.LogLevel != "" {
+		c.LogLevel = other.LogLevel
+	}
+	if other.Retry.MaxAttempts != 0 {
+		c.Retry.MaxAttempts = other.Retry.MaxAttempts
+	}
+	if other.Retry.MinBackoff != 0 {
+		c.Retry.MinBackoff = other.Retry.MinBackoff
+	}
+	if other.Retry.MaxBackoff != 0 {
+		c.Retry.MaxBackoff = other.Retry.MaxBackoff
+	}
+	if other.Retry.ShouldRetry != nil {
+		c.Retry.ShouldRetry = other.Retry.ShouldRetry
+	}
+	if other.RateLimit != 0 {
+		c.RateLimit = other.RateLimit
+	}
+	if other.RateBurst != 0 {
+		c.RateBurst = other.RateBurst
+	}
+}
+
+func firstEnv(keys ...string) string {
+	for _, key := range keys {
+		if v := os.Getenv(key); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+func parseDuration(name, v string) (time.Duration, error) {
+	if v == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s %q: %w", name, v, err)
+	}
+	return d, nil
+}
+
+// NewClientFromConfig returns a client credentials Client configured by cfg, see LoadConfig.
+// When logger is nil, a logger with cfg.LogLevel is created. opts are applied after the settings of cfg.
+func NewClientFromConfig(ctx context.Context, logger Logger, cfg *Config, opts ...ClientOption) (Client, error) {
+	if cfg.ClientID == "" || cfg.ClientSecret == "" {
+		return nil, fmt.Errorf("incomplete config of profile %q: client id and secret are required", cfg.Profile)
+	}
+
+	if logger == nil {
+		logger = NewHCLogLogger(hclog.New(&hclog.LoggerOptions{
+			Level: hclog.LevelFromString(cfg.LogLevel),
+		}))
+	}
+
+	var configured []ClientOption
+	if cfg.BaseURL != "" {
+		configured = append(configured, WithBaseURL(cfg.BaseURL))
+	}
+	if cfg.Retry.MaxAttempts > 1 {
+		retry := cfg.Retry
+		if retry.MinBackoff == 0 {
+			retry.MinBackoff = DefaultRetryPolicy.MinBackoff
+		}
+		if retry.MaxBackoff == 0 {
+			retry.MaxBackoff = DefaultRetryPolicy.MaxBackoff
+		}
+		configured = append(configured, WithDefaultCallOptions(WithRetryPolicy(retry)))
+	}
+	if cfg.RateLimit > 0 {
+		configured = append(configured, WithRateLimit(cfg.RateLimit, cfg.RateBurst))
+	}
+
+	return NewClient(ctx, logger, cfg.ClientID, cfg.ClientSecret, append(configured, opts...)...), nil
+}
@@ -0,0 +1,172 @@
+package wavy
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testConfigFile = `
+[default]
+client_id = "default-id"
+client_secret = "default-secret"
+log_level = "info"
+
+[default.retry]
+max_attempts = 3
+min_backoff = "100ms"
+
+[staging]
+client_id = "staging-id"
+base_url = "https://staging.wavy.example/api/v1beta"
+rate_limit = 2.5
+rate_burst = 5
+
+[staging.retry]
+max_backoff = "2s"
+
+[broken.retry]
+min_backoff = "soon"
+`
+
+// setConfigEnv isolates LoadConfig from the environment of the test run and writes the config file to the
+// default location.
+func setConfigEnv(t *testing.T, file string) {
+	dir := t.TempDir()
+	for _, key := range []string{
+		"WAVY_CONFIG", "WAVY_PROFILE", "WAVY_CLIENT_ID", "CLIENT_ID", "WAVY_CLIENT_SECRET", "CLIENT_SECRET",
+		"WAVY_BASE_URL", "WAVY_LOG_LEVEL", "WAVY_RETRY_MAX_ATTEMPTS", "WAVY_RETRY_MIN_BACKOFF",
+		"WAVY_RETRY_MAX_BACKOFF", "WAVY_RATE_LIMIT", "WAVY_RATE_BURST",
+	} {
+		t.Setenv(key, "")
+	}
+	t.Setenv("XDG_CONFIG_HOME", dir)
+
+	if file != "" {
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, "wavy"), 0700))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "wavy", "config.toml"), []byte(file), 0600))
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	setConfigEnv(t, testConfigFile)
+
+	cfg, err := LoadConfig()
+	require.NoError(t, err)
+	assert.Equal(t, &Config{
+		Profile:      "default",
+		ClientID:     "default-id",
+		ClientSecret: "default-secret",
+		LogLevel:     "info",
+		Retry:        RetryPolicy{MaxAttempts: 3, MinBackoff: 100 * time.Millisecond},
+	}, cfg)
+}
+
+func TestLoadConfig_profile(t *testing.T) {
+	setConfigEnv(t, testConfigFile)
+	t.Setenv("WAVY_PROFILE", "staging")
+
+	cfg, err := LoadConfig()
+	require.NoError(t, err)
+	assert.Equal(t, &Config{
+		Profile:      "staging",
+		ClientID:     "staging-id",
+		ClientSecret: "default-secret",
+		BaseURL:      "https://staging.wavy.example/api/v1beta",
+		LogLevel:     "info",
+		Retry:        RetryPolicy{MaxAttempts: 3, MinBackoff: 100 * time.Millisecond, MaxBackoff: 2 * time.Second},
+		RateLimit:    2.5,
+		RateBurst:    5,
+	}, cfg)
+
+	_, err = LoadConfig(FromProfile("missing"))
+	assert.Error(t, err)
+
+	_, err = LoadConfig(FromProfile("broken"))
+	assert.Error(t, err)
+}
+
+func TestLoadConfig_precedence(t *testing.T) {
+	setConfigEnv(t, testConfigFile)
+	t.Setenv("CLIENT_ID", "legacy-id")
+	t.Setenv("WAVY_CLIENT_SECRET", "env-secret")
+	t.Setenv("WAVY_RETRY_MAX_ATTEMPTS", "5")
+	t.Setenv("WAVY_RATE_LIMIT", "1")
+
+	cfg, err := LoadConfig(WithOverrides(Config{LogLevel: "trace", RateBurst: 3}))
+	require.NoError(t, err)
+	assert.Equal(t, "legacy-id", cfg.ClientID)
+	assert.Equal(t, "env-secret", cfg.ClientSecret)
+	assert.Equal(t, "trace", cfg.LogLevel)
+	assert.Equal(t, 5, cfg.Retry.MaxAttempts)
+	assert.Equal(t, 100*time.Millisecond, cfg.Retry.MinBackoff)
+	assert.Equal(t, 1.0, cfg.RateLimit)
+	assert.Equal(t, 3, cfg.RateBurst)
+
+	t.Setenv("WAVY_CLIENT_ID", "env-id")
+	cfg, err = LoadConfig()
+	require.NoError(t, err)
+	assert.Equal(t, "env-id", cfg.ClientID)
+
+	t.Setenv("WAVY_RETRY_MIN_BACKOFF", "soon")
+	_, err = LoadConfig()
+	assert.Error(t, err)
+}
+
+func TestLoadConfig_files(t *testing.T) {
+	setConfigEnv(t, "")
+
+	cfg, err := LoadConfig()
+	require.NoError(t, err, "a missing default config file is not an error")
+	assert.Equal(t, "warn", cfg.LogLevel)
+
+	_, err = LoadConfig(FromProfile("staging"))
+	assert.Error(t, err)
+
+	path := filepath.Join(t.TempDir(), "wavy.toml")
+	_, err = LoadConfig(FromFile(path))
+	assert.Error(t, err)
+
+	require.NoError(t, os.WriteFile(path, []byte(testConfigFile), 0600))
+	cfg, err = LoadConfig(FromFile(path), FromProfile("staging"))
+	require.NoError(t, err)
+	assert.Equal(t, "staging-id", cfg.ClientID)
+
+	require.NoError(t, os.WriteFile(path, []byte("[default"), 0600))
+	t.Setenv("WAVY_CONFIG", path)
+	_, err = LoadConfig()
+	assert.Error(t, err)
+}
+
+func TestNewClientFromConfig(t *testing.T) {
+	var tokens int32
+	server := newTokenServer(t, http.StatusOK, &tokens)
+
+	_, err := NewClientFromConfig(context.Background(), nil, &Config{Profile: "default", ClientID: "id"})
+	assert.Error(t, err)
+
+	c, err := NewClientFromConfig(context.Background(), nil, &Config{
+		ClientID:     "id",
+		ClientSecret: "secret",
+		BaseURL:      server.URL + "/api/v1beta",
+		LogLevel:     "error",
+		Retry:        RetryPolicy{MaxAttempts: 2},
+		RateLimit:    100,
+	})
+	require.NoError(t, err)
+
+	got, err := c.MetricsService().GetTotalListens(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int64(42), got.TotalListens)
+
+	cl := c.(*client)
+	assert.Len(t, cl.defaults, 1)
+	assert.NotNil(t, cl.limiter)
+	assert.Equal(t, DefaultRetryPolicy.MinBackoff, cl.callOptions(nil).retry.MinBackoff)
+}
@@ -0,0 +1,107 @@
+package wavy
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/OGKevin/go-wavy/wavy/recorder"
+	"github.com/OGKevin/go-wavy/wavy/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// contractFixture is a successful response body of a cassette.
+type contractFixture struct {
+	cassette string
+	path     string
+	body     []byte
+}
+
+// loadContractFixtures returns the successful responses of the api in testdata/cassettes, keyed by operation id.
+//
+// The contract tests only check that the cassettes, the models and the vendored schema agree with each other. All
+// three are transcribed from the documentation of wavy.fm, so they do not show that wavy.fm answers that way.
+func loadContractFixtures(t *testing.T) map[string][]contractFixture {
+	t.Helper()
+
+	paths, err := filepath.Glob(filepath.Join("testdata", "cassettes", "*.json"))
+	require.NoError(t, err)
+	require.NotEmpty(t, paths)
+
+	prefix := "/api/" + string(APIVersionV1Beta)
+	fixtures := map[string][]contractFixture{}
+	for _, path := range paths {
+		cassette, err := recorder.LoadCassette(path)
+		require.NoError(t, err)
+
+		for _, i := range cassette.Interactions {
+			u, err := url.Parse(i.Request.URL)
+			require.NoError(t, err)
+			if i.Request.Method != http.MethodGet || i.Response.StatusCode != http.StatusOK || !strings.HasPrefix(u.Path, prefix) {
+				continue
+			}
+
+			apiPath := strings.TrimPrefix(u.EscapedPath(), prefix)
+			_, op, ok := schema.V1Beta().Lookup(apiPath)
+			require.True(t, ok, "%s: %s is not described by the api schema", path, apiPath)
+
+			fixtures[op.OperationID] = append(fixtures[op.OperationID], contractFixture{
+				cassette: filepath.Base(path),
+				path:     apiPath,
+				body:     []byte(i.Response.Body),
+			})
+		}
+	}
+	return fixtures
+}
+
+// Test_contract_fixtures validates the recorded responses against the api schema, and checks that every
+// operation of the schema has a model and a fixture.
+func Test_contract_fixtures(t *testing.T) {
+	doc := schema.V1Beta()
+	fixtures := loadContractFixtures(t)
+
+	for _, template := range doc.PathNames() {
+		op := doc.Paths[template].Get
+		assert.Contains(t, contractModels, op.OperationID, "no model for %s", template)
+		assert.NotEmpty(t, fixtures[op.OperationID], "no fixture for %s", template)
+	}
+
+	for id, recorded := range fixtures {
+		for _, fixture := range recorded {
+			t.Run(id+"/"+fixture.cassette, func(t *testing.T) {
+				assert.NoError(t, doc.ValidateResponse(fixture.path, fixture.body))
+			})
+		}
+	}
+}
+
+// Test_contract_models decodes the recorded responses into the models of this package and validates the models
+// encoded again against the api schema. Fields of a model missing from the schema are reported as unknown fields,
+// fields of the schema missing from a model as missing fields.
+func Test_contract_models(t *testing.T) {
+	doc := schema.V1Beta()
+
+	for id, recorded := range loadContractFixtures(t) {
+		for _, fixture := range recorded {
+			t.Run(id+"/"+fixture.cassette, func(t *testing.T) {
+				newModel, ok := contractModels[id]
+				require.True(t, ok, "no model for %s", id)
+				model := newModel()
+
+				dec := json.NewDecoder(bytes.NewReader(fixture.body))
+				dec.DisallowUnknownFields()
+				require.NoError(t, dec.Decode(model))
+
+				encoded, err := json.Marshal(model)
+				require.NoError(t, err)
+				assert.NoError(t, doc.ValidateResponse(fixture.path, encoded))
+			})
+		}
+	}
+}
@@ -1,2 +1,4 @@
 // Package wavy provides bindings to interact with the wavy.fm api.
 package wavy
+
+//go:generate go run ../internal/cmd/wavy-gen -schema schema/openapi-v1beta.json -package wavy -out api.gen.go -test-out api.gen_test.go
@@ -0,0 +1,137 @@
+package graphql
+
+// document is a parsed executable document: the operations and fragments of a request.
+type document struct {
+	operations []*operation
+	fragments  map[string]*fragment
+}
+
+type operation struct {
+	// kind is query, mutation or subscription.
+	kind       string
+	name       string
+	variables  []*variableDefinition
+	directives []*directive
+	selections []selection
+	loc        Location
+}
+
+type variableDefinition struct {
+	name         string
+	typ          *typeRef
+	defaultValue value
+	loc          Location
+}
+
+// typeRef references a named type, or a list of elem when elem is set.
+type typeRef struct {
+	name    string
+	elem    *typeRef
+	nonNull bool
+}
+
+func (t *typeRef) String() string {
+	s := t.name
+	if t.elem != nil {
+		s = "[" + t.elem.String() + "]"
+	}
+	if t.nonNull {
+		s += "!"
+	}
+	return s
+}
+
+// selection is a *field, *fragmentSpread or *inlineFragment.
+type selection interface {
+	location() Location
+}
+
+type field struct {
+	alias      string
+	name       string
+	arguments  []*argument
+	directives []*directive
+	selections []selection
+	loc        Location
+}
+
+// responseKey is the key of the field in the response.
+func (f *field) responseKey() string {
+	if f.alias != "" {
+		return f.alias
+	}
+	return f.name
+}
+
+type fragmentSpread struct {
+	name       string
+	directives []*directive
+	loc        Location
+}
+
+type inlineFragment struct {
+	typeCondition string
+	directives    []*directive
+	selections    []selection
+	loc           Location
+}
+
+func (f *field) location() Location          { return f.loc }
+func (f *fragmentSpread) location() Location { return f.loc }
+func (f *inlineFragment) location() Location { return f.loc }
+
+type fragment struct {
+	name          string
+	typeCondition string
+	directives    []*directive
+	selections    []selection
+	loc           Location
+}
+
+type argument struct {
+	name  string
+	value value
+	loc   Location
+}
+
+type directive struct {
+	name      string
+	arguments []*argument
+	loc       Location
+}
+
+// value is a literal or variable of a document. Enum values are kept as enumValue, lists as []value and objects as
+// []*objectField.
+type value interface{}
+
+type (
+	variable   string
+	enumValue  string
+	nullValue  struct{}
+	intValue   string
+	floatValue string
+)
+
+type objectField struct {
+	name  string
+	value value
+}
+
+// typeDefinition is an object type of the schema definition language.
+type typeDefinition struct {
+	name   string
+	fields []*fieldDefinition
+}
+
+type fieldDefinition struct {
+	name        string
+	description string
+	arguments   []*inputValueDefinition
+	typ         *typeRef
+}
+
+type inputValueDefinition struct {
+	name         string
+	typ          *typeRef
+	defaultValue value
+}
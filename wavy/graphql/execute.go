@@ -0,0 +1,304 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+)
+
+// execution executes a validated operation. Sibling fields and the items of lists are resolved concurrently, so
+// the loads of their resolvers end up in the same batches.
+type execution struct {
+	schema    *schema
+	doc       *document
+	variables map[string]interface{}
+	loaders   *loaders
+
+	mu     sync.Mutex
+	errors []*Error
+}
+
+// object is a json object which keeps the order of its keys, as GraphQL responses follow the order of the query.
+type object struct {
+	keys   []string
+	values []interface{}
+}
+
+func (o *object) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, key := range o.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		k, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(k)
+		buf.WriteByte(':')
+
+		v, err := json.Marshal(o.values[i])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(v)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// fieldError records the error of the field at path. Errors of fields which are not nullable propagate to their
+// parent, see complete.
+func (e *execution) fieldError(err error, fields []*field, path []interface{}) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.errors = append(e.errors, &Error{
+		Message:   err.Error(),
+		Locations: []Location{fields[0].loc},
+		Path:      append([]interface{}(nil), path...),
+	})
+}
+
+// sortedErrors returns the errors ordered by location and path, as concurrent resolvers record them in any order.
+func (e *execution) sortedErrors() []*Error {
+	sort.SliceStable(e.errors, func(i, j int) bool {
+		a, b := e.errors[i], e.errors[j]
+		if a.Locations[0] != b.Locations[0] {
+			if a.Locations[0].Line != b.Locations[0].Line {
+				return a.Locations[0].Line < b.Locations[0].Line
+			}
+			return a.Locations[0].Column < b.Locations[0].Column
+		}
+		return fmt.Sprint(a.Path...) < fmt.Sprint(b.Path...)
+	})
+	return e.errors
+}
+
+// collect groups the fields selected by sels on t by response key, in the order of the query, skipping the
+// selections excluded by @skip and @include.
+func (e *execution) collect(t *objectType, sels []selection, keys []string, groups map[string][]*field, visited map[string]bool) []string {
+	for _, sel := range sels {
+		switch sel := sel.(type) {
+		case *field:
+			if !e.included(sel.directives) {
+				continue
+			}
+			key := sel.responseKey()
+			if _, ok := groups[key]; !ok {
+				keys = append(keys, key)
+			}
+			groups[key] = append(groups[key], sel)
+		case *fragmentSpread:
+			f := e.doc.fragments[sel.name]
+			if !e.included(sel.directives) || visited[sel.name] || f.typeCondition != t.name {
+				continue
+			}
+			visited[sel.name] = true
+			keys = e.collect(t, f.selections, keys, groups, visited)
+		case *inlineFragment:
+			if !e.included(sel.directives) || (sel.typeCondition != "" && sel.typeCondition != t.name) {
+				continue
+			}
+			keys = e.collect(t, sel.selections, keys, groups, visited)
+		}
+	}
+	return keys
+}
+
+// included evaluates the @skip and @include directives.
+func (e *execution) included(dirs []*directive) bool {
+	for _, d := range dirs {
+		if len(d.arguments) == 0 {
+			continue
+		}
+		v, _, _ := coerceLiteral(&typeRef{name: "Boolean"}, d.arguments[0].value, e.variables)
+		b, _ := v.(bool)
+		if d.name == "skip" && b || d.name == "include" && !b {
+			return false
+		}
+	}
+	return true
+}
+
+// selectionSet resolves the fields sels select on source, of type t. ok is false when a field which is not
+// nullable resolved to null, which nulls the object.
+func (e *execution) selectionSet(ctx context.Context, t *objectType, source interface{}, sels []selection, path []interface{}) (*object, bool) {
+	groups := make(map[string][]*field)
+	keys := e.collect(t, sels, nil, groups, make(map[string]bool))
+
+	obj := &object{keys: keys, values: make([]interface{}, len(keys))}
+	oks := make([]bool, len(keys))
+
+	var wg sync.WaitGroup
+	for i, key := range keys {
+		wg.Add(1)
+		go func(i int, key string) {
+			defer wg.Done()
+			obj.values[i], oks[i] = e.field(ctx, t, source, groups[key], appendPath(path, key))
+		}(i, key)
+	}
+	wg.Wait()
+
+	for _, ok := range oks {
+		if !ok {
+			return nil, false
+		}
+	}
+	return obj, true
+}
+
+func appendPath(path []interface{}, elem interface{}) []interface{} {
+	p := make([]interface{}, len(path), len(path)+1)
+	copy(p, path)
+	return append(p, elem)
+}
+
+// field resolves the field selected by fields on source and completes its value.
+func (e *execution) field(ctx context.Context, t *objectType, source interface{}, fields []*field, path []interface{}) (interface{}, bool) {
+	f := fields[0]
+	if f.name == "__typename" {
+		return t.name, true
+	}
+
+	def := t.fields[f.name]
+	args, err := e.arguments(def, f)
+	if err != nil {
+		e.fieldError(err, fields, path)
+		return nil, !def.typ.nonNull
+	}
+
+	v, err := def.resolve(ctx, params{source: source, args: args, loaders: e.loaders})
+	if err != nil {
+		e.fieldError(err, fields, path)
+		return nil, !def.typ.nonNull
+	}
+
+	return e.complete(ctx, def.typ, fields, v, path)
+}
+
+// arguments coerces the arguments of f, applying the defaults of def.
+func (e *execution) arguments(def *fieldDef, f *field) (map[string]interface{}, error) {
+	args := make(map[string]interface{}, len(def.arguments))
+	for _, argDef := range def.arguments {
+		var lit value
+		for _, arg := range f.arguments {
+			if arg.name == argDef.name {
+				lit = arg.value
+			}
+		}
+
+		present := false
+		if lit != nil {
+			v, ok, err := coerceLiteral(argDef.typ, lit, e.variables)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value of argument %q: %w", argDef.name, err)
+			}
+			if present = ok; ok {
+				args[argDef.name] = v
+			}
+		}
+		if !present && argDef.defaultValue != nil {
+			v, _, err := coerceLiteral(argDef.typ, argDef.defaultValue, nil)
+			if err != nil {
+				return nil, fmt.Errorf("invalid default value of argument %q: %w", argDef.name, err)
+			}
+			args[argDef.name] = v
+		} else if !present && argDef.typ.nonNull {
+			return nil, fmt.Errorf("argument %q of type %s is required", argDef.name, argDef.typ)
+		}
+	}
+	return args, nil
+}
+
+// complete converts the resolved value v to typ. ok is false when v is null while typ is not nullable, the null
+// then propagates to the nearest nullable parent.
+func (e *execution) complete(ctx context.Context, typ *typeRef, fields []*field, v interface{}, path []interface{}) (interface{}, bool) {
+	if !typ.nonNull {
+		result, ok := e.completeNullable(ctx, typ, fields, v, path)
+		if !ok {
+			return nil, true
+		}
+		return result, true
+	}
+
+	result, ok := e.completeNullable(ctx, nullable(typ), fields, v, path)
+	if !ok {
+		return nil, false
+	}
+	if result == nil {
+		e.fieldError(fmt.Errorf("cannot return null for non-nullable field %s", fields[0].name), fields, path)
+		return nil, false
+	}
+	return result, true
+}
+
+func (e *execution) completeNullable(ctx context.Context, typ *typeRef, fields []*field, v interface{}, path []interface{}) (interface{}, bool) {
+	if isNull(v) {
+		return nil, true
+	}
+
+	if typ.elem != nil {
+		rv := reflect.ValueOf(v)
+		if rv.Kind() != reflect.Slice {
+			e.fieldError(fmt.Errorf("expected a list for field %s, got %T", fields[0].name, v), fields, path)
+			return nil, false
+		}
+
+		items := make([]interface{}, rv.Len())
+		oks := make([]bool, rv.Len())
+		var wg sync.WaitGroup
+		for i := range items {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				items[i], oks[i] = e.complete(ctx, typ.elem, fields, rv.Index(i).Interface(), appendPath(path, i))
+			}(i)
+		}
+		wg.Wait()
+
+		for _, ok := range oks {
+			if !ok {
+				return nil, false
+			}
+		}
+		return items, true
+	}
+
+	if scalars[typ.name] {
+		result, err := serialize(typ.name, v)
+		if err != nil {
+			e.fieldError(err, fields, path)
+			return nil, false
+		}
+		return result, true
+	}
+
+	var sels []selection
+	for _, f := range fields {
+		sels = append(sels, f.selections...)
+	}
+	obj, ok := e.selectionSet(ctx, e.schema.types[typ.name], v, sels, path)
+	if !ok {
+		return nil, false
+	}
+	return obj, true
+}
+
+// isNull reports whether v is nil, or a nil pointer or slice.
+func isNull(v interface{}) bool {
+	if v == nil {
+		return true
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Map, reflect.Interface:
+		return rv.IsNil()
+	}
+	return false
+}
@@ -0,0 +1,159 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSDL = `
+type Query {
+  items: [Item]
+  strict: [Item!]
+  item: Item!
+}
+
+type Item {
+  name: String!
+  size: Int
+}
+`
+
+// item is an Item of testSDL, items without name fail to resolve.
+type item struct {
+	name string
+	size int
+}
+
+func testSchema(t *testing.T) *schema {
+	name := func(ctx context.Context, p params) (interface{}, error) {
+		if p.source.(*item).name == "" {
+			return nil, errors.New("no name")
+		}
+		return p.source.(*item).name, nil
+	}
+	items := func(ctx context.Context, p params) (interface{}, error) {
+		return []*item{{name: "a", size: 1}, {size: 2}}, nil
+	}
+
+	s, err := newSchema(testSDL, map[string]resolver{
+		"Query.items":  items,
+		"Query.strict": items,
+		"Query.item":   func(ctx context.Context, p params) (interface{}, error) { return &item{name: "only"}, nil },
+		"Item.name":    name,
+		"Item.size":    source(func(i *item) interface{} { return i.size }),
+	})
+	require.NoError(t, err)
+	return s
+}
+
+func executeTest(t *testing.T, s *schema, query string, vars map[string]interface{}) string {
+	t.Helper()
+
+	doc, err := parseDocument(query)
+	require.NoError(t, err)
+	op := doc.operations[0]
+	require.Empty(t, s.validate(doc, op, 0))
+
+	e := &execution{schema: s, doc: doc, variables: vars}
+	data, ok := e.selectionSet(context.Background(), s.query, nil, op.selections, nil)
+
+	res := &Response{Data: json.RawMessage("null"), Errors: e.sortedErrors()}
+	if ok {
+		res.Data, err = json.Marshal(data)
+		require.NoError(t, err)
+	}
+	b, err := json.Marshal(res)
+	require.NoError(t, err)
+	return string(b)
+}
+
+func Test_execution_nullPropagation(t *testing.T) {
+	s := testSchema(t)
+
+	// The failing name nulls its item, which is nullable in items.
+	assert.JSONEq(t, `{
+		"data": {"items": [{"name": "a"}, null]},
+		"errors": [{"message": "no name", "locations": [{"line": 1, "column": 11}], "path": ["items", 1, "name"]}]
+	}`, executeTest(t, s, `{ items { name } }`, nil))
+
+	// Items of strict are not nullable, so the list is nulled.
+	assert.JSONEq(t, `{
+		"data": {"strict": null},
+		"errors": [{"message": "no name", "locations": [{"line": 1, "column": 12}], "path": ["strict", 1, "name"]}]
+	}`, executeTest(t, s, `{ strict { name } }`, nil))
+}
+
+func Test_execution_fragmentsAndDirectives(t *testing.T) {
+	s := testSchema(t)
+
+	got := executeTest(t, s, `
+		query ($skip: Boolean!) {
+			item {
+				...sized
+				name @skip(if: $skip)
+				... on Item @include(if: false) { hidden: name }
+				alias: name
+			}
+		}
+		fragment sized on Item { size kind: __typename }
+	`, map[string]interface{}{"skip": true})
+	assert.Equal(t, `{"data":{"item":{"size":0,"kind":"Item","alias":"only"}}}`, got)
+
+	// Fields selected several times are merged.
+	got = executeTest(t, s, `{ item { name } item { size } }`, nil)
+	assert.Equal(t, `{"data":{"item":{"name":"only","size":0}}}`, got)
+}
+
+func Test_newSchema_errors(t *testing.T) {
+	_, err := newSchema(testSDL, map[string]resolver{})
+	assert.Error(t, err, "missing resolvers")
+
+	resolvers := map[string]resolver{}
+	for _, key := range []string{"Query.items", "Query.strict", "Query.item", "Item.name", "Item.size", "Item.color"} {
+		resolvers[key] = func(ctx context.Context, p params) (interface{}, error) { return nil, nil }
+	}
+	_, err = newSchema(testSDL, resolvers)
+	assert.EqualError(t, err, "resolver Item.color has no field")
+
+	_, err = newSchema(`type Query { a: Missing }`, map[string]resolver{"Query.a": resolvers["Item.name"]})
+	assert.EqualError(t, err, "field Query.a has unknown type Missing")
+}
+
+func Test_coerceVariable(t *testing.T) {
+	list := &typeRef{elem: &typeRef{name: "Int", nonNull: true}}
+
+	v, err := coerceVariable(list, []interface{}{float64(1), json.Number("2")})
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{1, 2}, v)
+
+	v, err = coerceVariable(list, float64(3))
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{3}, v, "single values are coerced to lists")
+
+	_, err = coerceVariable(list, []interface{}{1.5})
+	assert.EqualError(t, err, "at index 0: expected Int!, found 1.5")
+
+	_, err = coerceVariable(&typeRef{name: "Int"}, float64(1<<40))
+	assert.Error(t, err)
+
+	v, err = coerceVariable(&typeRef{name: "ID"}, float64(7))
+	require.NoError(t, err)
+	assert.Equal(t, "7", v)
+}
+
+func Test_serialize(t *testing.T) {
+	v, err := serialize("Int", int64(12))
+	require.NoError(t, err)
+	assert.Equal(t, int64(12), v)
+
+	_, err = serialize("Int", int64(1)<<40)
+	assert.EqualError(t, err, "Int cannot represent 1099511627776, it does not fit 32 bits")
+
+	_, err = serialize("String", 1)
+	assert.EqualError(t, err, "String cannot represent 1")
+}
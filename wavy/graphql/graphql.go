@@ -0,0 +1,291 @@
+// Package graphql serves the wavy.fm api as a GraphQL schema, so clients fetch profiles, history stats, current
+// tracks and recent listens of several users in a single round trip.
+//
+// The schema is described by SDL. Every field is resolved through a wavy.Client. The requests of a query are
+// batched and deduplicated like a dataloader: the resolvers of sibling fields and list items run concurrently,
+// and a response of wavy.fm is requested at most once per query, however often the query selects it.
+//
+// Only queries are supported, with variables, fragments, aliases and the @skip and @include directives.
+// Introspection is limited to __typename, use SDL for tooling.
+//
+// The package implements this subset of GraphQL itself rather than depending on a GraphQL library, which every
+// user of the SDK would import: the schema is fixed and read-only, so a parser, a validator and an executor of
+// queries are all it needs. Queries are untrusted input and bounded accordingly: Execute rejects queries larger
+// than 1 MiB, the parser rejects selection sets, values and types nested deeper than 64 levels, and validation
+// rejects selections deeper than Options.MaxDepth. Fields needing more than Options.MaxRequests responses of wavy.fm
+// fail. Supporting mutations, subscriptions or full introspection would outgrow this scope and warrant a library
+// such as github.com/graph-gophers/graphql-go.
+package graphql
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/OGKevin/go-wavy/wavy"
+)
+
+// Defaults of Options.
+const (
+	DefaultBatchWait      = time.Millisecond
+	DefaultMaxBatch       = 100
+	DefaultMaxConcurrency = 4
+	DefaultMaxDepth       = 10
+	DefaultMaxRequests    = 100
+)
+
+// maxRequestSize is the maximum size of the body of a request to the Executor, and of the query passed to Execute.
+const maxRequestSize = 1 << 20
+
+//go:embed schema.graphql
+var sdl string
+
+// SDL returns the schema served by the Executor in the GraphQL schema definition language.
+func SDL() string {
+	return sdl
+}
+
+var defaultSchema = mustSchema(newSchema(sdl, resolvers))
+
+func mustSchema(s *schema, err error) *schema {
+	if err != nil {
+		panic(fmt.Sprintf("graphql: invalid schema: %s", err))
+	}
+	return s
+}
+
+// Options configures an Executor. Zero values select the defaults.
+type Options struct {
+	// BatchWait is how long a batch of loads collects keys before they are requested.
+	BatchWait time.Duration
+	// MaxBatch is the amount of keys after which a batch is requested right away.
+	MaxBatch int
+	// MaxConcurrency is the amount of requests a batch sends to wavy.fm at the same time.
+	MaxConcurrency int
+	// MaxDepth is how deep selections may be nested, to reject queries fanning out to many requests.
+	MaxDepth int
+	// MaxRequests is how many distinct responses of wavy.fm a query may load, to reject queries selecting many users
+	// side by side. Fields beyond it fail.
+	MaxRequests int
+	// CallOptions are passed to every call of the client.
+	CallOptions []wavy.CallOption
+	// Logger logs failed queries, defaults to wavy.NopLogger.
+	Logger wavy.Logger
+}
+
+// Request is a GraphQL request, as sent in the body of a POST request.
+type Request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName,omitempty"`
+	Variables     map[string]interface{} `json:"variables,omitempty"`
+}
+
+// Response is the result of a Request. Data is absent when the request could not be executed, and null when a
+// field which is not nullable failed.
+type Response struct {
+	Data   json.RawMessage `json:"data,omitempty"`
+	Errors []*Error        `json:"errors,omitempty"`
+}
+
+// Location is a position in the query of a Request, starting at line 1 and column 1.
+type Location struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// Error is an error of a Request. Path is the response path of the field which failed, if any.
+type Error struct {
+	Message   string        `json:"message"`
+	Locations []Location    `json:"locations,omitempty"`
+	Path      []interface{} `json:"path,omitempty"`
+}
+
+func (e *Error) Error() string {
+	if len(e.Locations) == 0 {
+		return e.Message
+	}
+	return fmt.Sprintf("%s (line %d, column %d)", e.Message, e.Locations[0].Line, e.Locations[0].Column)
+}
+
+// Executor executes GraphQL requests against a wavy.Client. It serves them over http as well, see ServeHTTP.
+type Executor struct {
+	c      wavy.Client
+	opts   Options
+	schema *schema
+}
+
+// New returns an Executor resolving queries with c.
+func New(c wavy.Client, opts Options) *Executor {
+	if opts.BatchWait <= 0 {
+		opts.BatchWait = DefaultBatchWait
+	}
+	if opts.MaxBatch <= 0 {
+		opts.MaxBatch = DefaultMaxBatch
+	}
+	if opts.MaxConcurrency <= 0 {
+		opts.MaxConcurrency = DefaultMaxConcurrency
+	}
+	if opts.MaxDepth <= 0 {
+		opts.MaxDepth = DefaultMaxDepth
+	}
+	if opts.MaxRequests <= 0 {
+		opts.MaxRequests = DefaultMaxRequests
+	}
+	if opts.Logger == nil {
+		opts.Logger = wavy.NopLogger()
+	}
+	opts.Logger = opts.Logger.Named("graphql")
+
+	return &Executor{c: c, opts: opts, schema: defaultSchema}
+}
+
+// Execute executes req. Errors are reported in the Response.
+func (e *Executor) Execute(ctx context.Context, req Request) *Response {
+	if len(req.Query) > maxRequestSize {
+		return &Response{Errors: []*Error{{Message: fmt.Sprintf("query exceeds %d bytes", maxRequestSize)}}}
+	}
+
+	doc, err := parseDocument(req.Query)
+	if err != nil {
+		return &Response{Errors: []*Error{toError(err)}}
+	}
+
+	op, err := selectOperation(doc, req.OperationName)
+	if err != nil {
+		return &Response{Errors: []*Error{toError(err)}}
+	}
+	if errs := e.schema.validate(doc, op, e.opts.MaxDepth); len(errs) > 0 {
+		return &Response{Errors: errs}
+	}
+
+	vars, err := coerceVariables(op, req.Variables)
+	if err != nil {
+		return &Response{Errors: []*Error{toError(err)}}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	ex := &execution{
+		schema:    e.schema,
+		doc:       doc,
+		variables: vars,
+		loaders:   newLoaders(ctx, e.c, e.opts),
+	}
+	data, ok := ex.selectionSet(ctx, e.schema.query, nil, op.selections, nil)
+
+	res := &Response{Data: json.RawMessage("null"), Errors: ex.sortedErrors()}
+	if ok {
+		if res.Data, err = json.Marshal(data); err != nil {
+			return &Response{Errors: []*Error{{Message: fmt.Sprintf("failed to encode response: %s", err)}}}
+		}
+	}
+	for _, err := range res.Errors {
+		e.opts.Logger.Debug("field failed", "path", err.Path, "error", err.Message)
+	}
+
+	return res
+}
+
+func toError(err error) *Error {
+	if e, ok := err.(*Error); ok {
+		return e
+	}
+	return &Error{Message: err.Error()}
+}
+
+// selectOperation returns the operation called name, or the only operation of doc when name is empty.
+func selectOperation(doc *document, name string) (*operation, error) {
+	if name == "" {
+		if len(doc.operations) > 1 {
+			return nil, fmt.Errorf("operationName is required when the document contains several operations")
+		}
+		return doc.operations[0], nil
+	}
+
+	for _, op := range doc.operations {
+		if op.name == name {
+			return op, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown operation %q", name)
+}
+
+// coerceVariables coerces the variables of a request to the types defined by op, applying their defaults.
+func coerceVariables(op *operation, raw map[string]interface{}) (map[string]interface{}, error) {
+	vars := make(map[string]interface{}, len(op.variables))
+	for _, def := range op.variables {
+		v, ok := raw[def.name]
+		switch {
+		case !ok && def.defaultValue != nil:
+			d, _, err := coerceLiteral(def.typ, def.defaultValue, nil)
+			if err != nil {
+				return nil, &Error{Message: fmt.Sprintf("invalid default value of variable $%s: %s", def.name, err), Locations: []Location{def.loc}}
+			}
+			vars[def.name] = d
+		case !ok && def.typ.nonNull:
+			return nil, &Error{Message: fmt.Sprintf("variable $%s of type %s is required", def.name, def.typ), Locations: []Location{def.loc}}
+		case ok:
+			c, err := coerceVariable(def.typ, v)
+			if err != nil {
+				return nil, &Error{Message: fmt.Sprintf("invalid value of variable $%s: %s", def.name, err), Locations: []Location{def.loc}}
+			}
+			vars[def.name] = c
+		}
+	}
+	return vars, nil
+}
+
+// ServeHTTP serves GraphQL over http: POST requests with a json Request as body, and GET requests with the query,
+// operationName and json encoded variables as query parameters.
+func (e *Executor) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req Request
+	switch r.Method {
+	case http.MethodGet:
+		q := r.URL.Query()
+		req.Query, req.OperationName = q.Get("query"), q.Get("operationName")
+		if v := q.Get("variables"); v != "" {
+			if err := json.Unmarshal([]byte(v), &req.Variables); err != nil {
+				writeResponse(w, http.StatusBadRequest, &Response{Errors: []*Error{{Message: "variables must be a json object"}}})
+				return
+			}
+		}
+	case http.MethodPost:
+		if ct := r.Header.Get("Content-Type"); ct != "" && !strings.HasPrefix(ct, "application/json") {
+			writeResponse(w, http.StatusUnsupportedMediaType, &Response{Errors: []*Error{{Message: "content type must be application/json"}}})
+			return
+		}
+		dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestSize))
+		dec.UseNumber()
+		if err := dec.Decode(&req); err != nil {
+			writeResponse(w, http.StatusBadRequest, &Response{Errors: []*Error{{Message: fmt.Sprintf("invalid request body: %s", err)}}})
+			return
+		}
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		writeResponse(w, http.StatusMethodNotAllowed, &Response{Errors: []*Error{{Message: "method not allowed"}}})
+		return
+	}
+
+	if req.Query == "" {
+		writeResponse(w, http.StatusBadRequest, &Response{Errors: []*Error{{Message: "query is required"}}})
+		return
+	}
+
+	res := e.Execute(r.Context(), req)
+	status := http.StatusOK
+	if res.Data == nil {
+		status = http.StatusBadRequest
+	}
+	writeResponse(w, status, res)
+}
+
+func writeResponse(w http.ResponseWriter, status int, res *Response) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(res)
+}
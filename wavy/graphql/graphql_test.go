@@ -0,0 +1,271 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/OGKevin/go-wavy/wavy"
+	"github.com/OGKevin/go-wavy/wavy/wavytest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var joined = time.Date(2020, 11, 2, 10, 0, 0, 0, time.UTC)
+
+func newTestClient() *wavytest.Client {
+	c := wavytest.NewClient()
+	for _, u := range []struct{ id, name string }{{"1", "OGKevin"}, {"2", "wavy"}} {
+		profile := &wavy.GetUserProfileResponse{
+			URI:      "wavyfm:user:id:" + u.id,
+			ID:       u.id,
+			Username: u.name,
+			JoinTime: joined,
+			Profile:  wavy.Profile{URL: "https://wavy.fm/" + u.name, Avatar: "https://cdn.wavy.fm/" + u.id + ".png", Country: "NL"},
+		}
+		stats := &wavy.GetHistroyStatsResponse{TotalListens: 100, TotalArtists: 10}
+		for _, uri := range []wavy.UserURI{{UserID: u.id}, {Username: u.name}} {
+			c.Profiles[uri.String()] = profile
+			c.Stats[uri.String()] = stats
+			c.Current[uri.String()] = &wavy.GetCurrentResponse{}
+		}
+	}
+
+	c.Current["wavyfm:user:username:OGKevin"] = &wavy.GetCurrentResponse{Item: wavy.CurrentPlayingItem{
+		Song:    wavy.Song{Name: "Never Gonna Give You Up", Source: "spotify", SourceURL: "https://open.spotify.com/track/1"},
+		Album:   wavy.Album{Name: "Whenever You Need Somebody"},
+		Artists: []wavy.Artists{{Name: "Rick Astley"}},
+	}}
+	c.Recent["wavyfm:user:username:OGKevin"] = &wavy.GetRecentResponse{Items: []wavy.Item{
+		{PlayID: "b", Date: joined.Add(2 * time.Minute), CurrentPlayingItem: wavy.CurrentPlayingItem{Song: wavy.Song{Name: "Second"}}},
+		{PlayID: "a", Date: joined.Add(time.Minute), CurrentPlayingItem: wavy.CurrentPlayingItem{Song: wavy.Song{Name: "First"}, Local: true}},
+	}}
+	c.TotalListens = 1000
+	c.TotalUsers = 10
+	c.Leaderboard = []wavy.LeaderboardEntry{
+		{Count: 500, Username: "OGKevin", UserID: "1"},
+		{Count: 300, Username: "wavy", UserID: "2"},
+		{Count: 100, Username: "private", UserID: "3"},
+	}
+
+	return c
+}
+
+func execute(t *testing.T, c wavy.Client, query string, vars map[string]interface{}) *Response {
+	t.Helper()
+	return New(c, Options{}).Execute(context.Background(), Request{Query: query, Variables: vars})
+}
+
+func assertResponse(t *testing.T, expected string, res *Response) {
+	t.Helper()
+	got, err := json.Marshal(res)
+	require.NoError(t, err)
+	assert.JSONEq(t, expected, string(got))
+}
+
+func TestExecutor_Execute(t *testing.T) {
+	res := execute(t, newTestClient(), `
+		query Me($uri: String!) {
+			me: user(uri: $uri) {
+				uri
+				profile { id username joinTime url avatar(size: 64) country twitter }
+				stats { totalListens }
+				current { song { name url } album { name artUrl } artists { name } local }
+				recent(limit: 1) { playId date song { name } local }
+				__typename
+			}
+			metrics { totalListens totalUsers }
+		}`, map[string]interface{}{"uri": "wavyfm:user:username:OGKevin"})
+
+	assertResponse(t, `{"data": {
+		"me": {
+			"uri": "wavyfm:user:username:OGKevin",
+			"profile": {
+				"id": "1", "username": "OGKevin", "joinTime": "2020-11-02T10:00:00Z", "url": "https://wavy.fm/OGKevin",
+				"avatar": "https://cdn.wavy.fm/1.png", "country": "NL", "twitter": null
+			},
+			"stats": {"totalListens": 100},
+			"current": {
+				"song": {"name": "Never Gonna Give You Up", "url": "https://open.spotify.com/track/1"},
+				"album": {"name": "Whenever You Need Somebody", "artUrl": null},
+				"artists": [{"name": "Rick Astley"}],
+				"local": false
+			},
+			"recent": [{"playId": "b", "date": "2020-11-02T10:02:00Z", "song": {"name": "Second"}, "local": false}],
+			"__typename": "User"
+		},
+		"metrics": {"totalListens": 1000, "totalUsers": 10}
+	}}`, res)
+
+	b, err := json.Marshal(res)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(b), `{"data":{"me":{"uri":`), "response does not follow the order of the query: %s", b)
+}
+
+func TestExecutor_Execute_notFound(t *testing.T) {
+	res := execute(t, newTestClient(), `{
+		missing: user(uri: "wavyfm:user:username:missing") { uri }
+		user(uri: "wavyfm:user:username:wavy") { current { local } recent { playId } }
+	}`, nil)
+
+	assertResponse(t, `{"data": {"missing": null, "user": {"current": null, "recent": null}}}`, res)
+}
+
+func TestExecutor_Execute_batching(t *testing.T) {
+	c := newTestClient()
+	res := execute(t, c, `{
+		leaderboard {
+			entries {
+				rank
+				username
+				user { profile { username } stats { totalListens } }
+			}
+		}
+		users(uris: ["wavyfm:user:id:1", "wavyfm:user:id:2", "wavyfm:user:id:1"]) { profile { username } }
+		again: leaderboard { entries(limit: 1) { count } }
+	}`, nil)
+
+	assertResponse(t, `{"data": {
+		"leaderboard": {"entries": [
+			{"rank": 1, "username": "OGKevin", "user": {"profile": {"username": "OGKevin"}, "stats": {"totalListens": 100}}},
+			{"rank": 2, "username": "wavy", "user": {"profile": {"username": "wavy"}, "stats": {"totalListens": 100}}},
+			{"rank": 3, "username": "private", "user": {"profile": null, "stats": null}}
+		]},
+		"users": [{"profile": {"username": "OGKevin"}}, {"profile": {"username": "wavy"}}, {"profile": {"username": "OGKevin"}}],
+		"again": {"entries": [{"count": 500}]}
+	}}`, res)
+
+	// Every response is requested once, however often the query selects it.
+	assert.Equal(t, 1, c.Calls("GetUserListensLeaderboard"))
+	assert.Equal(t, 3, c.Calls("GetProfile"))
+	assert.Equal(t, 3, c.Calls("GetStats"))
+}
+
+func TestExecutor_Execute_maxRequests(t *testing.T) {
+	c := newTestClient()
+	res := New(c, Options{MaxRequests: 2}).Execute(context.Background(), Request{Query: `{
+		users(uris: ["wavyfm:user:id:1", "wavyfm:user:id:2", "wavyfm:user:username:wavy", "wavyfm:user:id:1"]) {
+			profile { username }
+		}
+	}`})
+
+	// The third distinct profile exceeds the limit and is not requested.
+	assert.Equal(t, 2, c.Calls("GetProfile"))
+	require.Len(t, res.Errors, 1)
+	assert.Equal(t, "query requests more than 2 responses of wavy.fm", res.Errors[0].Message)
+}
+
+func TestExecutor_Execute_errors(t *testing.T) {
+	c := newTestClient()
+	c.Err = errors.New("wavy.fm is down")
+
+	// Errors of nullable fields null the field.
+	res := execute(t, c, `{ user(uri: "wavyfm:user:username:OGKevin") { uri } }`, nil)
+	assertResponse(t, `{
+		"data": {"user": null},
+		"errors": [{"message": "wavy.fm is down", "locations": [{"line": 1, "column": 3}], "path": ["user"]}]
+	}`, res)
+
+	// Errors of fields which are not nullable propagate to the nearest nullable parent, here the data.
+	res = execute(t, c, `{ metrics { totalListens totalUsers } }`, nil)
+	assert.Equal(t, "null", string(res.Data))
+	require.Len(t, res.Errors, 2)
+	assert.Equal(t, []interface{}{"metrics", "totalListens"}, res.Errors[0].Path)
+	assert.Equal(t, []interface{}{"metrics", "totalUsers"}, res.Errors[1].Path)
+
+	c.Err = nil
+	res = execute(t, c, `{ user(uri: "not a uri") { uri } }`, nil)
+	assertResponse(t, `{
+		"data": {"user": null},
+		"errors": [{"message": "failed to parse UserURI: \"not a uri\"", "locations": [{"line": 1, "column": 3}], "path": ["user"]}]
+	}`, res)
+}
+
+func TestExecutor_Execute_requestErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		req   Request
+		error string
+	}{
+		{name: "syntax", req: Request{Query: `{ user(`}, error: "syntax error"},
+		{name: "validation", req: Request{Query: `{ user(uri: "a") { password } }`}, error: `cannot query field "password" on type User`},
+		{name: "several operations", req: Request{Query: `query A { metrics { totalUsers } } query B { metrics { totalUsers } }`}, error: "operationName is required"},
+		{name: "unknown operation", req: Request{Query: `query A { metrics { totalUsers } }`, OperationName: "B"}, error: `unknown operation "B"`},
+		{name: "missing variable", req: Request{Query: `query ($uri: String!) { user(uri: $uri) { uri } }`}, error: "variable $uri of type String! is required"},
+		{name: "invalid variable", req: Request{Query: `query ($n: Int) { leaderboard { entries(limit: $n) { rank } } }`, Variables: map[string]interface{}{"n": "ten"}}, error: "invalid value of variable $n"},
+		{name: "mutation", req: Request{Query: `mutation { metrics { totalUsers } }`}, error: "mutation operations are not supported"},
+		{name: "too large", req: Request{Query: `{ metrics { totalUsers } }` + strings.Repeat(" ", maxRequestSize)}, error: "query exceeds 1048576 bytes"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestClient()
+			res := New(c, Options{}).Execute(context.Background(), tt.req)
+			assert.Nil(t, res.Data)
+			require.NotEmpty(t, res.Errors)
+			assert.Contains(t, res.Errors[0].Message, tt.error)
+			assert.Equal(t, 0, c.Calls("GetProfile")+c.Calls("GetTotalUsers"))
+		})
+	}
+}
+
+func TestExecutor_Execute_operationName(t *testing.T) {
+	res := New(newTestClient(), Options{}).Execute(context.Background(), Request{
+		Query:         `query Users { metrics { totalUsers } } query Listens { metrics { totalListens } }`,
+		OperationName: "Listens",
+	})
+	assertResponse(t, `{"data": {"metrics": {"totalListens": 1000}}}`, res)
+}
+
+func TestExecutor_ServeHTTP(t *testing.T) {
+	srv := httptest.NewServer(New(newTestClient(), Options{}))
+	defer srv.Close()
+
+	post := func(body string) (*http.Response, Response) {
+		res, err := http.Post(srv.URL, "application/json", strings.NewReader(body))
+		require.NoError(t, err)
+		defer res.Body.Close()
+
+		var out Response
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&out))
+		return res, out
+	}
+
+	res, out := post(`{"query": "query ($n: Int) { leaderboard { entries(limit: $n) { username } } }", "variables": {"n": 1}}`)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, "application/json", res.Header.Get("Content-Type"))
+	assert.JSONEq(t, `{"leaderboard": {"entries": [{"username": "OGKevin"}]}}`, string(out.Data))
+
+	res, out = post(`{"query": "{ nope }"}`)
+	assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+	assert.Contains(t, out.Errors[0].Message, `cannot query field "nope" on type Query`)
+
+	res, _ = post(`not json`)
+	assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+
+	res, err := http.Get(srv.URL + "?query=" + url.QueryEscape(`{ metrics { totalUsers } }`))
+	require.NoError(t, err)
+	defer res.Body.Close()
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&out))
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.JSONEq(t, `{"metrics": {"totalUsers": 10}}`, string(out.Data))
+
+	req, err := http.NewRequest(http.MethodDelete, srv.URL, nil)
+	require.NoError(t, err)
+	res, err = http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	res.Body.Close()
+	assert.Equal(t, http.StatusMethodNotAllowed, res.StatusCode)
+}
+
+func TestSDL(t *testing.T) {
+	for _, typ := range []string{"User", "Profile", "HistoryStats", "CurrentItem", "Listen", "Leaderboard", "GlobalMetrics"} {
+		assert.Contains(t, SDL(), "type "+typ+" {")
+		assert.Contains(t, defaultSchema.types, typ)
+	}
+}
@@ -0,0 +1,149 @@
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// batchFunc fetches the values of keys. It returns a value or error per key, in the order of keys.
+type batchFunc[K comparable, V any] func(ctx context.Context, keys []K) ([]V, []error)
+
+// loader batches and caches the loads of a single request, like a dataloader.
+// Keys loaded within wait of the first key of a batch are fetched together, once the batch holds maxBatch keys it is
+// fetched right away. Every key is fetched at most once, later loads share the value or error of the first.
+// Every key takes one from the budget, keys beyond it fail without being fetched.
+type loader[K comparable, V any] struct {
+	ctx      context.Context
+	fetch    batchFunc[K, V]
+	wait     time.Duration
+	maxBatch int
+	budget   *budget
+
+	mu    sync.Mutex
+	cache map[K]*loaded[V]
+	batch *batch[K, V]
+}
+
+type loaded[V any] struct {
+	done  chan struct{}
+	value V
+	err   error
+}
+
+type batch[K comparable, V any] struct {
+	keys    []K
+	results []*loaded[V]
+}
+
+// budget limits the amount of keys the loaders of a request fetch together. A nil budget is unlimited.
+type budget struct {
+	max  int
+	left atomic.Int64
+}
+
+func newBudget(max int) *budget {
+	b := &budget{max: max}
+	b.left.Store(int64(max))
+	return b
+}
+
+// take reports whether a key may be fetched.
+func (b *budget) take() bool {
+	return b == nil || b.left.Add(-1) >= 0
+}
+
+// newLoader returns a loader fetching with fetch. ctx is passed to fetch and should live as long as the request.
+func newLoader[K comparable, V any](ctx context.Context, fetch batchFunc[K, V], wait time.Duration, maxBatch int, b *budget) *loader[K, V] {
+	return &loader[K, V]{
+		ctx:      ctx,
+		fetch:    fetch,
+		wait:     wait,
+		maxBatch: maxBatch,
+		budget:   b,
+		cache:    make(map[K]*loaded[V]),
+	}
+}
+
+// load returns the value of key, waiting for the batch it is fetched in.
+func (l *loader[K, V]) load(ctx context.Context, key K) (V, error) {
+	l.mu.Lock()
+	r, ok := l.cache[key]
+	if !ok {
+		if !l.budget.take() {
+			l.mu.Unlock()
+			var zero V
+			return zero, fmt.Errorf("query requests more than %d responses of wavy.fm", l.budget.max)
+		}
+
+		r = &loaded[V]{done: make(chan struct{})}
+		l.cache[key] = r
+
+		if l.batch == nil {
+			b := &batch[K, V]{}
+			l.batch = b
+			time.AfterFunc(l.wait, func() { l.dispatch(b) })
+		}
+		l.batch.keys = append(l.batch.keys, key)
+		l.batch.results = append(l.batch.results, r)
+
+		if l.maxBatch > 0 && len(l.batch.keys) >= l.maxBatch {
+			b := l.batch
+			l.batch = nil
+			go l.run(b)
+		}
+	}
+	l.mu.Unlock()
+
+	select {
+	case <-r.done:
+		return r.value, r.err
+	case <-ctx.Done():
+		var zero V
+		return zero, ctx.Err()
+	}
+}
+
+// dispatch fetches b unless it was fetched already because it was full.
+func (l *loader[K, V]) dispatch(b *batch[K, V]) {
+	l.mu.Lock()
+	if l.batch != b {
+		l.mu.Unlock()
+		return
+	}
+	l.batch = nil
+	l.mu.Unlock()
+
+	l.run(b)
+}
+
+// run fetches b. The loads of b are done once run returns, also when fetch panics.
+func (l *loader[K, V]) run(b *batch[K, V]) {
+	var (
+		values []V
+		errs   []error
+	)
+	defer func() {
+		if p := recover(); p != nil {
+			values, errs = nil, nil
+			err := fmt.Errorf("panic while loading: %v", p)
+			for range b.keys {
+				errs = append(errs, err)
+			}
+		}
+
+		for i, r := range b.results {
+			if i < len(values) {
+				r.value = values[i]
+			}
+			if i < len(errs) {
+				r.err = errs[i]
+			}
+			close(r.done)
+		}
+	}()
+
+	values, errs = l.fetch(l.ctx, b.keys)
+}
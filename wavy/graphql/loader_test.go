@@ -0,0 +1,134 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingFetch returns a batchFunc returning the length of every key, failing for "fail", and the batches it got.
+func recordingFetch() (batchFunc[string, int], func() [][]string) {
+	var (
+		mu      sync.Mutex
+		batches [][]string
+	)
+	fetch := func(ctx context.Context, keys []string) ([]int, []error) {
+		mu.Lock()
+		batches = append(batches, append([]string(nil), keys...))
+		mu.Unlock()
+
+		values, errs := make([]int, len(keys)), make([]error, len(keys))
+		for i, key := range keys {
+			if key == "fail" {
+				errs[i] = errors.New("failed")
+				continue
+			}
+			values[i] = len(key)
+		}
+		return values, errs
+	}
+	return fetch, func() [][]string {
+		mu.Lock()
+		defer mu.Unlock()
+		return batches
+	}
+}
+
+func Test_loader_batches(t *testing.T) {
+	fetch, batches := recordingFetch()
+	l := newLoader(context.Background(), fetch, 10*time.Millisecond, 0, nil)
+
+	keys := []string{"a", "bb", "a", "ccc", "fail"}
+	values := make([]int, len(keys))
+	errs := make([]error, len(keys))
+	var wg sync.WaitGroup
+	for i, key := range keys {
+		wg.Add(1)
+		go func(i int, key string) {
+			defer wg.Done()
+			values[i], errs[i] = l.load(context.Background(), key)
+		}(i, key)
+	}
+	wg.Wait()
+
+	assert.Equal(t, []int{1, 2, 1, 3, 0}, values)
+	assert.EqualError(t, errs[4], "failed")
+
+	require.Len(t, batches(), 1)
+	got := batches()[0]
+	sort.Strings(got)
+	assert.Equal(t, []string{"a", "bb", "ccc", "fail"}, got)
+
+	// Loaded keys are cached, errors included.
+	v, err := l.load(context.Background(), "bb")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, v)
+	_, err = l.load(context.Background(), "fail")
+	assert.Error(t, err)
+	assert.Len(t, batches(), 1)
+}
+
+func Test_loader_maxBatch(t *testing.T) {
+	fetch, batches := recordingFetch()
+	l := newLoader(context.Background(), fetch, time.Hour, 2, nil)
+
+	var wg sync.WaitGroup
+	for _, key := range []string{"a", "b", "c", "d"} {
+		wg.Add(1)
+		go func(key string) {
+			defer wg.Done()
+			l.load(context.Background(), key)
+		}(key)
+	}
+	wg.Wait()
+
+	assert.Len(t, batches(), 2)
+}
+
+func Test_loader_budget(t *testing.T) {
+	fetch, batches := recordingFetch()
+	b := newBudget(2)
+	first := newLoader(context.Background(), fetch, time.Millisecond, 0, b)
+	second := newLoader(context.Background(), fetch, time.Millisecond, 0, b)
+
+	_, err := first.load(context.Background(), "a")
+	require.NoError(t, err)
+	_, err = first.load(context.Background(), "a")
+	require.NoError(t, err, "cached keys take nothing")
+	_, err = second.load(context.Background(), "b")
+	require.NoError(t, err)
+
+	// The budget is shared by the loaders of a request.
+	_, err = first.load(context.Background(), "c")
+	assert.EqualError(t, err, "query requests more than 2 responses of wavy.fm")
+	_, err = second.load(context.Background(), "d")
+	assert.Error(t, err)
+	assert.Len(t, batches(), 2)
+}
+
+func Test_loader_panic(t *testing.T) {
+	l := newLoader(context.Background(), func(ctx context.Context, keys []string) ([]int, []error) {
+		panic("boom")
+	}, time.Millisecond, 0, nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	_, err := l.load(ctx, "a")
+	assert.EqualError(t, err, "panic while loading: boom")
+}
+
+func Test_loader_cancel(t *testing.T) {
+	fetch, _ := recordingFetch()
+	l := newLoader(context.Background(), fetch, time.Hour, 0, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := l.load(ctx, "a")
+	assert.Equal(t, context.Canceled, err)
+}
@@ -0,0 +1,791 @@
+package graphql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenPunct
+	tokenName
+	tokenInt
+	tokenFloat
+	tokenString
+)
+
+type token struct {
+	kind  tokenKind
+	value string
+	loc   Location
+}
+
+func (t token) String() string {
+	switch t.kind {
+	case tokenEOF:
+		return "<EOF>"
+	case tokenString:
+		return strconv.Quote(t.value)
+	default:
+		return fmt.Sprintf("%q", t.value)
+	}
+}
+
+// lexer splits a GraphQL source into tokens, skipping whitespace, commas and comments.
+type lexer struct {
+	src  string
+	pos  int
+	line int
+	col  int
+}
+
+func newLexer(src string) *lexer {
+	return &lexer{src: strings.TrimPrefix(src, "\ufeff"), line: 1, col: 1}
+}
+
+func (l *lexer) advance(n int) {
+	for _, r := range l.src[l.pos : l.pos+n] {
+		if r == '\n' {
+			l.line++
+			l.col = 1
+		} else {
+			l.col++
+		}
+	}
+	l.pos += n
+}
+
+func (l *lexer) next() (token, error) {
+	for l.pos < len(l.src) {
+		switch c := l.src[l.pos]; {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == ',':
+			l.advance(1)
+		case c == '#':
+			end := strings.IndexAny(l.src[l.pos:], "\r\n")
+			if end < 0 {
+				end = len(l.src) - l.pos
+			}
+			l.advance(end)
+		default:
+			return l.token()
+		}
+	}
+	return token{kind: tokenEOF, loc: Location{Line: l.line, Column: l.col}}, nil
+}
+
+func (l *lexer) token() (token, error) {
+	loc := Location{Line: l.line, Column: l.col}
+	rest := l.src[l.pos:]
+	c := rest[0]
+
+	switch {
+	case strings.HasPrefix(rest, "..."):
+		l.advance(3)
+		return token{kind: tokenPunct, value: "...", loc: loc}, nil
+	case strings.IndexByte("!$&():=@[]{|}", c) >= 0:
+		l.advance(1)
+		return token{kind: tokenPunct, value: string(c), loc: loc}, nil
+	case c == '_' || isLetter(c):
+		n := 1
+		for n < len(rest) && (rest[n] == '_' || isLetter(rest[n]) || isDigit(rest[n])) {
+			n++
+		}
+		l.advance(n)
+		return token{kind: tokenName, value: rest[:n], loc: loc}, nil
+	case c == '-' || isDigit(c):
+		return l.number(loc)
+	case strings.HasPrefix(rest, `"""`):
+		end := 3
+		for {
+			i := strings.Index(rest[end:], `"""`)
+			if i < 0 {
+				return token{}, syntaxError(loc, "unterminated string")
+			}
+			end += i
+			if rest[end-1] != '\\' {
+				break
+			}
+			end += 3
+		}
+		end -= 3
+		l.advance(end + 6)
+		return token{kind: tokenString, value: blockString(rest[3 : end+3]), loc: loc}, nil
+	case c == '"':
+		return l.string(loc)
+	}
+
+	r, _ := utf8.DecodeRuneInString(rest)
+	return token{}, syntaxError(loc, fmt.Sprintf("unexpected character %q", r))
+}
+
+func (l *lexer) number(loc Location) (token, error) {
+	rest := l.src[l.pos:]
+	n := 0
+	if rest[n] == '-' {
+		n++
+	}
+	digits := func() int {
+		start := n
+		for n < len(rest) && isDigit(rest[n]) {
+			n++
+		}
+		return n - start
+	}
+	if digits() == 0 {
+		return token{}, syntaxError(loc, "invalid number")
+	}
+
+	kind := tokenInt
+	if n < len(rest) && rest[n] == '.' {
+		kind = tokenFloat
+		n++
+		if digits() == 0 {
+			return token{}, syntaxError(loc, "invalid number")
+		}
+	}
+	if n < len(rest) && (rest[n] == 'e' || rest[n] == 'E') {
+		kind = tokenFloat
+		n++
+		if n < len(rest) && (rest[n] == '+' || rest[n] == '-') {
+			n++
+		}
+		if digits() == 0 {
+			return token{}, syntaxError(loc, "invalid number")
+		}
+	}
+	if n < len(rest) && (rest[n] == '_' || rest[n] == '.' || isLetter(rest[n])) {
+		return token{}, syntaxError(loc, "invalid number")
+	}
+
+	l.advance(n)
+	return token{kind: kind, value: rest[:n], loc: loc}, nil
+}
+
+func (l *lexer) string(loc Location) (token, error) {
+	var b strings.Builder
+	rest := l.src[l.pos:]
+	for i := 1; i < len(rest); {
+		switch c := rest[i]; c {
+		case '"':
+			l.advance(i + 1)
+			return token{kind: tokenString, value: b.String(), loc: loc}, nil
+		case '\n', '\r':
+			return token{}, syntaxError(loc, "unterminated string")
+		case '\\':
+			if i+1 >= len(rest) {
+				return token{}, syntaxError(loc, "unterminated string")
+			}
+			switch e := rest[i+1]; e {
+			case '"', '\\', '/':
+				b.WriteByte(e)
+			case 'b':
+				b.WriteByte('\b')
+			case 'f':
+				b.WriteByte('\f')
+			case 'n':
+				b.WriteByte('\n')
+			case 'r':
+				b.WriteByte('\r')
+			case 't':
+				b.WriteByte('\t')
+			case 'u':
+				if i+6 > len(rest) {
+					return token{}, syntaxError(loc, "invalid unicode escape")
+				}
+				r, err := strconv.ParseUint(rest[i+2:i+6], 16, 32)
+				if err != nil {
+					return token{}, syntaxError(loc, "invalid unicode escape")
+				}
+				b.WriteRune(rune(r))
+				i += 4
+			default:
+				return token{}, syntaxError(loc, fmt.Sprintf("invalid escape sequence \\%c", e))
+			}
+			i += 2
+		default:
+			b.WriteByte(c)
+			i++
+		}
+	}
+	return token{}, syntaxError(loc, "unterminated string")
+}
+
+// blockString removes the common indentation and the blank first and last lines of a block string.
+func blockString(raw string) string {
+	lines := strings.Split(strings.ReplaceAll(raw, `\"""`, `"""`), "\n")
+
+	indent := -1
+	for _, line := range lines[1:] {
+		trimmed := strings.TrimLeft(line, " \t")
+		if trimmed == "" {
+			continue
+		}
+		if n := len(line) - len(trimmed); indent < 0 || n < indent {
+			indent = n
+		}
+	}
+	if indent > 0 {
+		for i := 1; i < len(lines); i++ {
+			if len(lines[i]) >= indent {
+				lines[i] = lines[i][indent:]
+			} else {
+				lines[i] = strings.TrimLeft(lines[i], " \t")
+			}
+		}
+	}
+
+	for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return strings.Join(lines, "\n")
+}
+
+func isLetter(c byte) bool { return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' }
+func isDigit(c byte) bool  { return c >= '0' && c <= '9' }
+
+func syntaxError(loc Location, message string) *Error {
+	return &Error{Message: "syntax error: " + message, Locations: []Location{loc}}
+}
+
+// maxNesting is how deep selection sets, list and object values and list types may be nested in a document, so
+// parsing a hostile document does not exhaust the stack.
+const maxNesting = 64
+
+// parser is a recursive descent parser of GraphQL documents.
+type parser struct {
+	lex   *lexer
+	tok   token
+	depth int
+}
+
+func newParser(src string) (*parser, error) {
+	p := &parser{lex: newLexer(src)}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (p *parser) advance() error {
+	tok, err := p.lex.next()
+	if err != nil {
+		return err
+	}
+	p.tok = tok
+	return nil
+}
+
+// peek reports whether the current token is the punctuator or keyword s.
+func (p *parser) peek(s string) bool {
+	return (p.tok.kind == tokenPunct || p.tok.kind == tokenName) && p.tok.value == s
+}
+
+// skip consumes the current token when it is s.
+func (p *parser) skip(s string) (bool, error) {
+	if !p.peek(s) {
+		return false, nil
+	}
+	return true, p.advance()
+}
+
+func (p *parser) expect(s string) error {
+	if !p.peek(s) {
+		return p.unexpected(fmt.Sprintf("%q", s))
+	}
+	return p.advance()
+}
+
+// nest enters a nested selection set, value or type, callers leave it with unnest.
+func (p *parser) nest() error {
+	p.depth++
+	if p.depth > maxNesting {
+		return syntaxError(p.tok.loc, fmt.Sprintf("exceeds the maximum nesting of %d", maxNesting))
+	}
+	return nil
+}
+
+func (p *parser) unnest() {
+	p.depth--
+}
+
+func (p *parser) unexpected(expected string) error {
+	return syntaxError(p.tok.loc, fmt.Sprintf("expected %s, found %s", expected, p.tok))
+}
+
+func (p *parser) name() (string, error) {
+	if p.tok.kind != tokenName {
+		return "", p.unexpected("name")
+	}
+	name := p.tok.value
+	return name, p.advance()
+}
+
+// parseDocument parses an executable document: operations and fragments.
+func parseDocument(src string) (*document, error) {
+	p, err := newParser(src)
+	if err != nil {
+		return nil, err
+	}
+
+	doc := &document{fragments: make(map[string]*fragment)}
+	for p.tok.kind != tokenEOF {
+		switch {
+		case p.peek("{"), p.peek("query"), p.peek("mutation"), p.peek("subscription"):
+			op, err := p.operation()
+			if err != nil {
+				return nil, err
+			}
+			doc.operations = append(doc.operations, op)
+		case p.peek("fragment"):
+			f, err := p.fragment()
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := doc.fragments[f.name]; ok {
+				return nil, &Error{Message: fmt.Sprintf("there can be only one fragment named %q", f.name), Locations: []Location{f.loc}}
+			}
+			doc.fragments[f.name] = f
+		default:
+			return nil, p.unexpected("operation or fragment")
+		}
+	}
+	if len(doc.operations) == 0 {
+		return nil, &Error{Message: "document does not contain an operation"}
+	}
+
+	return doc, nil
+}
+
+func (p *parser) operation() (*operation, error) {
+	op := &operation{kind: "query", loc: p.tok.loc}
+	if p.peek("{") {
+		sels, err := p.selectionSet()
+		op.selections = sels
+		return op, err
+	}
+
+	op.kind = p.tok.value
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	if p.tok.kind == tokenName {
+		op.name = p.tok.value
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+	}
+
+	if ok, err := p.skip("("); err != nil {
+		return nil, err
+	} else if ok {
+		for !p.peek(")") {
+			def, err := p.variableDefinition()
+			if err != nil {
+				return nil, err
+			}
+			op.variables = append(op.variables, def)
+		}
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+	}
+
+	var err error
+	if op.directives, err = p.directives(); err != nil {
+		return nil, err
+	}
+	op.selections, err = p.selectionSet()
+	return op, err
+}
+
+func (p *parser) variableDefinition() (*variableDefinition, error) {
+	def := &variableDefinition{loc: p.tok.loc}
+	if err := p.expect("$"); err != nil {
+		return nil, err
+	}
+
+	var err error
+	if def.name, err = p.name(); err != nil {
+		return nil, err
+	}
+	if err := p.expect(":"); err != nil {
+		return nil, err
+	}
+	if def.typ, err = p.typeRef(); err != nil {
+		return nil, err
+	}
+	if ok, err := p.skip("="); err != nil {
+		return nil, err
+	} else if ok {
+		if def.defaultValue, err = p.value(true); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := p.directives(); err != nil {
+		return nil, err
+	}
+
+	return def, nil
+}
+
+func (p *parser) typeRef() (*typeRef, error) {
+	if err := p.nest(); err != nil {
+		return nil, err
+	}
+	defer p.unnest()
+
+	t := &typeRef{}
+	if ok, err := p.skip("["); err != nil {
+		return nil, err
+	} else if ok {
+		if t.elem, err = p.typeRef(); err != nil {
+			return nil, err
+		}
+		if err := p.expect("]"); err != nil {
+			return nil, err
+		}
+	} else if t.name, err = p.name(); err != nil {
+		return nil, err
+	}
+
+	var err error
+	t.nonNull, err = p.skip("!")
+	return t, err
+}
+
+func (p *parser) selectionSet() ([]selection, error) {
+	if err := p.nest(); err != nil {
+		return nil, err
+	}
+	defer p.unnest()
+
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+
+	var sels []selection
+	for !p.peek("}") {
+		sel, err := p.selection()
+		if err != nil {
+			return nil, err
+		}
+		sels = append(sels, sel)
+	}
+	if len(sels) == 0 {
+		return nil, p.unexpected("selection")
+	}
+
+	return sels, p.advance()
+}
+
+func (p *parser) selection() (selection, error) {
+	loc := p.tok.loc
+	if ok, err := p.skip("..."); err != nil {
+		return nil, err
+	} else if ok {
+		return p.fragmentSelection(loc)
+	}
+
+	f := &field{loc: loc}
+	var err error
+	if f.name, err = p.name(); err != nil {
+		return nil, err
+	}
+	if ok, err := p.skip(":"); err != nil {
+		return nil, err
+	} else if ok {
+		f.alias = f.name
+		if f.name, err = p.name(); err != nil {
+			return nil, err
+		}
+	}
+	if f.arguments, err = p.arguments(false); err != nil {
+		return nil, err
+	}
+	if f.directives, err = p.directives(); err != nil {
+		return nil, err
+	}
+	if p.peek("{") {
+		if f.selections, err = p.selectionSet(); err != nil {
+			return nil, err
+		}
+	}
+
+	return f, nil
+}
+
+func (p *parser) fragmentSelection(loc Location) (selection, error) {
+	if p.tok.kind == tokenName && p.tok.value != "on" {
+		spread := &fragmentSpread{name: p.tok.value, loc: loc}
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		var err error
+		spread.directives, err = p.directives()
+		return spread, err
+	}
+
+	inline := &inlineFragment{loc: loc}
+	if ok, err := p.skip("on"); err != nil {
+		return nil, err
+	} else if ok {
+		if inline.typeCondition, err = p.name(); err != nil {
+			return nil, err
+		}
+	}
+
+	var err error
+	if inline.directives, err = p.directives(); err != nil {
+		return nil, err
+	}
+	inline.selections, err = p.selectionSet()
+	return inline, err
+}
+
+func (p *parser) fragment() (*fragment, error) {
+	f := &fragment{loc: p.tok.loc}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+
+	var err error
+	if f.name, err = p.name(); err != nil {
+		return nil, err
+	}
+	if f.name == "on" {
+		return nil, syntaxError(f.loc, `fragment can not be named "on"`)
+	}
+	if err := p.expect("on"); err != nil {
+		return nil, err
+	}
+	if f.typeCondition, err = p.name(); err != nil {
+		return nil, err
+	}
+	if f.directives, err = p.directives(); err != nil {
+		return nil, err
+	}
+	f.selections, err = p.selectionSet()
+	return f, err
+}
+
+func (p *parser) arguments(constant bool) ([]*argument, error) {
+	if ok, err := p.skip("("); err != nil || !ok {
+		return nil, err
+	}
+
+	var args []*argument
+	for !p.peek(")") {
+		arg := &argument{loc: p.tok.loc}
+		var err error
+		if arg.name, err = p.name(); err != nil {
+			return nil, err
+		}
+		if err := p.expect(":"); err != nil {
+			return nil, err
+		}
+		if arg.value, err = p.value(constant); err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+	}
+	if len(args) == 0 {
+		return nil, p.unexpected("argument")
+	}
+
+	return args, p.advance()
+}
+
+func (p *parser) directives() ([]*directive, error) {
+	var dirs []*directive
+	for p.peek("@") {
+		d := &directive{loc: p.tok.loc}
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+
+		var err error
+		if d.name, err = p.name(); err != nil {
+			return nil, err
+		}
+		if d.arguments, err = p.arguments(false); err != nil {
+			return nil, err
+		}
+		dirs = append(dirs, d)
+	}
+	return dirs, nil
+}
+
+// value parses a value, variables are rejected when constant is set.
+func (p *parser) value(constant bool) (value, error) {
+	tok := p.tok
+	switch {
+	case tok.kind == tokenPunct && tok.value == "$":
+		if constant {
+			return nil, p.unexpected("constant value")
+		}
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		name, err := p.name()
+		return variable(name), err
+	case tok.kind == tokenPunct && tok.value == "[":
+		if err := p.nest(); err != nil {
+			return nil, err
+		}
+		defer p.unnest()
+
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		list := []value{}
+		for !p.peek("]") {
+			v, err := p.value(constant)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, v)
+		}
+		return list, p.advance()
+	case tok.kind == tokenPunct && tok.value == "{":
+		if err := p.nest(); err != nil {
+			return nil, err
+		}
+		defer p.unnest()
+
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		fields := []*objectField{}
+		for !p.peek("}") {
+			name, err := p.name()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect(":"); err != nil {
+				return nil, err
+			}
+			v, err := p.value(constant)
+			if err != nil {
+				return nil, err
+			}
+			fields = append(fields, &objectField{name: name, value: v})
+		}
+		return fields, p.advance()
+	case tok.kind == tokenInt:
+		return intValue(tok.value), p.advance()
+	case tok.kind == tokenFloat:
+		return floatValue(tok.value), p.advance()
+	case tok.kind == tokenString:
+		return tok.value, p.advance()
+	case tok.kind == tokenName:
+		var v value
+		switch tok.value {
+		case "true":
+			v = true
+		case "false":
+			v = false
+		case "null":
+			v = nullValue{}
+		default:
+			v = enumValue(tok.value)
+		}
+		return v, p.advance()
+	}
+
+	return nil, p.unexpected("value")
+}
+
+// parseSchema parses the object type definitions of a schema definition language document.
+func parseSchema(src string) ([]*typeDefinition, error) {
+	p, err := newParser(src)
+	if err != nil {
+		return nil, err
+	}
+
+	var defs []*typeDefinition
+	for p.tok.kind != tokenEOF {
+		if p.tok.kind == tokenString {
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+		}
+		if err := p.expect("type"); err != nil {
+			return nil, err
+		}
+
+		def := &typeDefinition{}
+		if def.name, err = p.name(); err != nil {
+			return nil, err
+		}
+		if err := p.expect("{"); err != nil {
+			return nil, err
+		}
+		for !p.peek("}") {
+			f, err := p.fieldDefinition()
+			if err != nil {
+				return nil, err
+			}
+			def.fields = append(def.fields, f)
+		}
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		defs = append(defs, def)
+	}
+
+	return defs, nil
+}
+
+func (p *parser) fieldDefinition() (*fieldDefinition, error) {
+	f := &fieldDefinition{}
+	if p.tok.kind == tokenString {
+		f.description = p.tok.value
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+	}
+
+	var err error
+	if f.name, err = p.name(); err != nil {
+		return nil, err
+	}
+	if ok, err := p.skip("("); err != nil {
+		return nil, err
+	} else if ok {
+		for !p.peek(")") {
+			arg := &inputValueDefinition{}
+			if arg.name, err = p.name(); err != nil {
+				return nil, err
+			}
+			if err := p.expect(":"); err != nil {
+				return nil, err
+			}
+			if arg.typ, err = p.typeRef(); err != nil {
+				return nil, err
+			}
+			if ok, err := p.skip("="); err != nil {
+				return nil, err
+			} else if ok {
+				if arg.defaultValue, err = p.value(true); err != nil {
+					return nil, err
+				}
+			}
+			f.arguments = append(f.arguments, arg)
+		}
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+	}
+	if err := p.expect(":"); err != nil {
+		return nil, err
+	}
+	f.typ, err = p.typeRef()
+	return f, err
+}
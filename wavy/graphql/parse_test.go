@@ -0,0 +1,133 @@
+package graphql
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_parseDocument(t *testing.T) {
+	doc, err := parseDocument(`
+		# comments and commas are ignored
+		query Profile($uri: String!, $limit: Int = 5) @include(if: true) {
+			me: user(uri: $uri) {
+				...names
+				recent(limit: $limit) { playId, date }
+				... on User @skip(if: false) { uri }
+			}
+		}
+
+		fragment names on User { profile { username } }
+	`)
+	require.NoError(t, err)
+	require.Len(t, doc.operations, 1)
+
+	op := doc.operations[0]
+	assert.Equal(t, "query", op.kind)
+	assert.Equal(t, "Profile", op.name)
+	require.Len(t, op.variables, 2)
+	assert.Equal(t, "String!", op.variables[0].typ.String())
+	assert.Equal(t, intValue("5"), op.variables[1].defaultValue)
+	assert.Equal(t, "include", op.directives[0].name)
+
+	me := op.selections[0].(*field)
+	assert.Equal(t, "me", me.responseKey())
+	assert.Equal(t, "user", me.name)
+	assert.Equal(t, variable("uri"), me.arguments[0].value)
+	assert.Equal(t, Location{Line: 4, Column: 4}, me.loc)
+	require.Len(t, me.selections, 3)
+	assert.Equal(t, "names", me.selections[0].(*fragmentSpread).name)
+	assert.Equal(t, "User", me.selections[2].(*inlineFragment).typeCondition)
+
+	assert.Equal(t, "User", doc.fragments["names"].typeCondition)
+}
+
+func Test_parseDocument_values(t *testing.T) {
+	doc, err := parseDocument(`{ f(a: -12, b: 1.5e3, c: "esc\"aped é", d: [true, null, ENUM], e: {x: 1}, f: """
+		block
+		  string
+	""") }`)
+	require.NoError(t, err)
+
+	args := doc.operations[0].selections[0].(*field).arguments
+	assert.Equal(t, intValue("-12"), args[0].value)
+	assert.Equal(t, floatValue("1.5e3"), args[1].value)
+	assert.Equal(t, "esc\"aped é", args[2].value)
+	assert.Equal(t, []value{true, nullValue{}, enumValue("ENUM")}, args[3].value)
+	assert.Equal(t, []*objectField{{name: "x", value: intValue("1")}}, args[4].value)
+	assert.Equal(t, "block\n  string", args[5].value)
+}
+
+func Test_parseDocument_errors(t *testing.T) {
+	tests := []struct {
+		query string
+		err   string
+	}{
+		{query: ``, err: "document does not contain an operation"},
+		{query: `{ user(uri: "a") { uri }`, err: `syntax error: expected name, found <EOF> (line 1, column 25)`},
+		{query: `{ }`, err: `syntax error: expected selection, found "}"`},
+		{query: `{ a(b: $) }`, err: `syntax error: expected name, found ")"`},
+		{query: `{ a(b: "open) }`, err: "syntax error: unterminated string"},
+		{query: `{ a(b: 1.) }`, err: "syntax error: invalid number"},
+		{query: `{ a(b: 12abc) }`, err: "syntax error: invalid number"},
+		{query: `{ a } ?`, err: `syntax error: unexpected character '?'`},
+		{query: `fragment on on User { a }`, err: `fragment can not be named "on"`},
+		{query: `{ a } fragment f on A { a } fragment f on A { a }`, err: `there can be only one fragment named "f"`},
+		{query: `query ($a: Int = $b) { a }`, err: "expected constant value"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			_, err := parseDocument(tt.query)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.err)
+		})
+	}
+}
+
+func Test_parseDocument_nesting(t *testing.T) {
+	nested := func(open, close string, n int) string {
+		return strings.Repeat(open, n) + strings.Repeat(close, n)
+	}
+
+	_, err := parseDocument(`{ a(b: ` + nested("[", "]", maxNesting-1) + `) { c } }`)
+	assert.NoError(t, err)
+
+	for name, query := range map[string]string{
+		"lists":          `{ a(b: ` + nested("[", "]", maxNesting) + `) }`,
+		"objects":        `{ a(b: ` + nested("{c: ", "}", maxNesting) + `) }`,
+		"selection sets": nested("{ a ", "}", maxNesting+1),
+		"types":          `query ($a: ` + nested("[", "]", maxNesting) + `) { a }`,
+		"unterminated":   `{ a(b: ` + strings.Repeat("[", 2<<20),
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := parseDocument(query)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "syntax error: exceeds the maximum nesting of 64")
+		})
+	}
+}
+
+func Test_parseSchema(t *testing.T) {
+	defs, err := parseSchema(`
+		# comment
+		type Query {
+			"description"
+			user(uri: String!, limit: Int = 10): User
+			users: [User!]!
+		}
+	`)
+	require.NoError(t, err)
+	require.Len(t, defs, 1)
+
+	user := defs[0].fields[0]
+	assert.Equal(t, "description", user.description)
+	assert.Equal(t, "User", user.typ.String())
+	assert.Equal(t, "limit", user.arguments[1].name)
+	assert.Equal(t, intValue("10"), user.arguments[1].defaultValue)
+	assert.Equal(t, "[User!]!", defs[0].fields[1].typ.String())
+
+	_, err = parseSchema(`interface Node { id: ID! }`)
+	assert.Error(t, err)
+}
@@ -0,0 +1,280 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/OGKevin/go-wavy/wavy"
+)
+
+// user is the source of the User type.
+type user struct {
+	uri wavy.UserURI
+}
+
+// metrics is the source of the GlobalMetrics type, its fields are loaded when selected.
+type metrics struct{}
+
+// loaders load the responses of the wavy.fm api for a single request. Users are keyed by their UserURI.
+type loaders struct {
+	profiles *loader[wavy.UserURI, *wavy.GetUserProfileResponse]
+	stats    *loader[wavy.UserURI, *wavy.GetHistroyStatsResponse]
+	current  *loader[wavy.UserURI, *wavy.GetCurrentResponse]
+	recent   *loader[wavy.UserURI, *wavy.GetRecentResponse]
+
+	totalListens *loader[struct{}, *wavy.GetTotalListensResponse]
+	totalUsers   *loader[struct{}, *wavy.GetTotalUsersResponse]
+	leaderboard  *loader[struct{}, *wavy.UserListensLeaderboardResponse]
+}
+
+// newLoaders returns the loaders of a request, which load at most opts.MaxRequests responses together.
+func newLoaders(ctx context.Context, c wavy.Client, opts Options) *loaders {
+	b := newBudget(opts.MaxRequests)
+	return &loaders{
+		profiles: newClientLoader(ctx, opts, b, func(ctx context.Context, uri wavy.UserURI) (*wavy.GetUserProfileResponse, error) {
+			return c.UserService().GetProfile(ctx, uri, opts.CallOptions...)
+		}),
+		stats: newClientLoader(ctx, opts, b, func(ctx context.Context, uri wavy.UserURI) (*wavy.GetHistroyStatsResponse, error) {
+			return c.UserService().HistroyService(uri).GetStats(ctx, opts.CallOptions...)
+		}),
+		current: newClientLoader(ctx, opts, b, func(ctx context.Context, uri wavy.UserURI) (*wavy.GetCurrentResponse, error) {
+			return c.UserService().HistroyService(uri).GetCurrent(ctx, opts.CallOptions...)
+		}),
+		recent: newClientLoader(ctx, opts, b, func(ctx context.Context, uri wavy.UserURI) (*wavy.GetRecentResponse, error) {
+			return c.UserService().HistroyService(uri).GetRecent(ctx, opts.CallOptions...)
+		}),
+		totalListens: newClientLoader(ctx, opts, b, func(ctx context.Context, _ struct{}) (*wavy.GetTotalListensResponse, error) {
+			return c.MetricsService().GetTotalListens(ctx, opts.CallOptions...)
+		}),
+		totalUsers: newClientLoader(ctx, opts, b, func(ctx context.Context, _ struct{}) (*wavy.GetTotalUsersResponse, error) {
+			return c.MetricsService().GetTotalUsers(ctx, opts.CallOptions...)
+		}),
+		leaderboard: newClientLoader(ctx, opts, b, func(ctx context.Context, _ struct{}) (*wavy.UserListensLeaderboardResponse, error) {
+			return c.MetricsService().GetUserListensLeaderboard(ctx, opts.CallOptions...)
+		}),
+	}
+}
+
+// newClientLoader returns a loader fetching the keys of a batch with get, as wavy.fm has no batch endpoints.
+// At most opts.MaxConcurrency keys of a batch are requested at the same time.
+func newClientLoader[K comparable, V any](ctx context.Context, opts Options, b *budget, get func(ctx context.Context, key K) (V, error)) *loader[K, V] {
+	return newLoader(ctx, func(ctx context.Context, keys []K) ([]V, []error) {
+		values := make([]V, len(keys))
+		errs := make([]error, len(keys))
+		sem := make(chan struct{}, opts.MaxConcurrency)
+
+		var wg sync.WaitGroup
+		for i, key := range keys {
+			wg.Add(1)
+			sem <- struct{}{}
+			go func(i int, key K) {
+				defer func() {
+					if p := recover(); p != nil {
+						errs[i] = fmt.Errorf("panic while loading: %v", p)
+					}
+					<-sem
+					wg.Done()
+				}()
+				values[i], errs[i] = get(ctx, key)
+			}(i, key)
+		}
+		wg.Wait()
+
+		return values, errs
+	}, opts.BatchWait, opts.MaxBatch, b)
+}
+
+// isNotFound reports whether err is the 404 of wavy.fm, returned for unknown users and private profiles.
+func isNotFound(err error) bool {
+	var apiErr *wavy.ApiError
+	return errors.As(err, &apiErr) && apiErr.Status == http.StatusNotFound
+}
+
+// optional returns nil for empty strings, which are null in the schema.
+func optional(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+// limit returns the first n items of items when the limit argument n is set.
+func limit[T any](items []T, args map[string]interface{}) []T {
+	if n, ok := args["limit"].(int); ok && n >= 0 && n < len(items) {
+		return items[:n]
+	}
+	return items
+}
+
+func artists(items []wavy.Artists) []*wavy.Artists {
+	out := make([]*wavy.Artists, len(items))
+	for i := range items {
+		out[i] = &items[i]
+	}
+	return out
+}
+
+// source returns a resolver for a field of the type T.
+func source[T any](get func(T) interface{}) resolver {
+	return func(ctx context.Context, p params) (interface{}, error) {
+		return get(p.source.(T)), nil
+	}
+}
+
+var resolvers = map[string]resolver{
+	"Query.user": func(ctx context.Context, p params) (interface{}, error) {
+		uri, err := wavy.ParseUserURI(p.args["uri"].(string))
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.loaders.profiles.load(ctx, *uri); err != nil {
+			if isNotFound(err) {
+				return nil, nil
+			}
+			return nil, err
+		}
+		return &user{uri: *uri}, nil
+	},
+	"Query.users": func(ctx context.Context, p params) (interface{}, error) {
+		raw := p.args["uris"].([]interface{})
+		users := make([]*user, len(raw))
+		for i, s := range raw {
+			uri, err := wavy.ParseUserURI(s.(string))
+			if err != nil {
+				return nil, err
+			}
+			users[i] = &user{uri: *uri}
+		}
+		return users, nil
+	},
+	"Query.leaderboard": func(ctx context.Context, p params) (interface{}, error) {
+		return p.loaders.leaderboard.load(ctx, struct{}{})
+	},
+	"Query.metrics": func(ctx context.Context, p params) (interface{}, error) {
+		return &metrics{}, nil
+	},
+
+	"User.uri": source(func(u *user) interface{} { return u.uri.String() }),
+	"User.profile": func(ctx context.Context, p params) (interface{}, error) {
+		profile, err := p.loaders.profiles.load(ctx, p.source.(*user).uri)
+		if isNotFound(err) {
+			return nil, nil
+		}
+		return profile, err
+	},
+	"User.stats": func(ctx context.Context, p params) (interface{}, error) {
+		stats, err := p.loaders.stats.load(ctx, p.source.(*user).uri)
+		if isNotFound(err) {
+			return nil, nil
+		}
+		return stats, err
+	},
+	"User.current": func(ctx context.Context, p params) (interface{}, error) {
+		current, err := p.loaders.current.load(ctx, p.source.(*user).uri)
+		if isNotFound(err) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if current.Item.Song.Name == "" {
+			return nil, nil
+		}
+		return &current.Item, nil
+	},
+	"User.recent": func(ctx context.Context, p params) (interface{}, error) {
+		recent, err := p.loaders.recent.load(ctx, p.source.(*user).uri)
+		if isNotFound(err) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		items := make([]*wavy.Item, len(recent.Items))
+		for i := range recent.Items {
+			items[i] = &recent.Items[i]
+		}
+		return limit(items, p.args), nil
+	},
+
+	"Profile.id":        source(func(p *wavy.GetUserProfileResponse) interface{} { return p.ID }),
+	"Profile.username":  source(func(p *wavy.GetUserProfileResponse) interface{} { return p.Username }),
+	"Profile.joinTime":  source(func(p *wavy.GetUserProfileResponse) interface{} { return p.JoinTime.UTC().Format(time.RFC3339) }),
+	"Profile.url":       source(func(p *wavy.GetUserProfileResponse) interface{} { return optional(p.Profile.URL) }),
+	"Profile.country":   source(func(p *wavy.GetUserProfileResponse) interface{} { return optional(p.Profile.Country) }),
+	"Profile.biography": source(func(p *wavy.GetUserProfileResponse) interface{} { return optional(p.Profile.Biography) }),
+	"Profile.twitter":   source(func(p *wavy.GetUserProfileResponse) interface{} { return optional(p.Profile.Twitter) }),
+	"Profile.instagram": source(func(p *wavy.GetUserProfileResponse) interface{} { return optional(p.Profile.Instagram) }),
+	"Profile.spotify":   source(func(p *wavy.GetUserProfileResponse) interface{} { return optional(p.Profile.Spotify.ID) }),
+	"Profile.discord":   source(func(p *wavy.GetUserProfileResponse) interface{} { return optional(p.Profile.Discord.ID) }),
+	"Profile.avatar": func(ctx context.Context, p params) (interface{}, error) {
+		size, _ := p.args["size"].(int)
+		if size < 0 {
+			return nil, fmt.Errorf("size must not be negative")
+		}
+		return optional(p.source.(*wavy.GetUserProfileResponse).Profile.AvatarURL(size)), nil
+	},
+
+	"HistoryStats.totalListens": source(func(s *wavy.GetHistroyStatsResponse) interface{} { return s.TotalListens }),
+	"HistoryStats.totalArtists": source(func(s *wavy.GetHistroyStatsResponse) interface{} { return s.TotalArtists }),
+
+	"CurrentItem.song":    source(func(i *wavy.CurrentPlayingItem) interface{} { return &i.Song }),
+	"CurrentItem.album":   source(func(i *wavy.CurrentPlayingItem) interface{} { return &i.Album }),
+	"CurrentItem.artists": source(func(i *wavy.CurrentPlayingItem) interface{} { return artists(i.Artists) }),
+	"CurrentItem.local":   source(func(i *wavy.CurrentPlayingItem) interface{} { return i.Local }),
+
+	"Listen.playId":  source(func(i *wavy.Item) interface{} { return i.PlayID }),
+	"Listen.date":    source(func(i *wavy.Item) interface{} { return i.Date.UTC().Format(time.RFC3339) }),
+	"Listen.song":    source(func(i *wavy.Item) interface{} { return &i.Song }),
+	"Listen.album":   source(func(i *wavy.Item) interface{} { return &i.Album }),
+	"Listen.artists": source(func(i *wavy.Item) interface{} { return artists(i.Artists) }),
+	"Listen.local":   source(func(i *wavy.Item) interface{} { return i.Local }),
+
+	"Song.name":   source(func(s *wavy.Song) interface{} { return s.Name }),
+	"Song.source": source(func(s *wavy.Song) interface{} { return optional(s.Source) }),
+	"Song.url":    source(func(s *wavy.Song) interface{} { return optional(s.SourceURL) }),
+
+	"Album.name":   source(func(a *wavy.Album) interface{} { return a.Name }),
+	"Album.source": source(func(a *wavy.Album) interface{} { return optional(a.Source) }),
+	"Album.url":    source(func(a *wavy.Album) interface{} { return optional(a.SourceURL) }),
+	"Album.artUrl": source(func(a *wavy.Album) interface{} { return optional(a.ArtURL) }),
+
+	"Artist.name":   source(func(a *wavy.Artists) interface{} { return a.Name }),
+	"Artist.source": source(func(a *wavy.Artists) interface{} { return optional(a.Source) }),
+	"Artist.url":    source(func(a *wavy.Artists) interface{} { return optional(a.SourceURL) }),
+
+	"Leaderboard.entries": func(ctx context.Context, p params) (interface{}, error) {
+		board := p.source.(*wavy.UserListensLeaderboardResponse)
+		entries := make([]*wavy.LeaderboardEntry, len(board.Entries))
+		for i := range board.Entries {
+			entries[i] = &board.Entries[i]
+		}
+		return limit(entries, p.args), nil
+	},
+
+	"LeaderboardEntry.rank":     source(func(e *wavy.LeaderboardEntry) interface{} { return e.Rank }),
+	"LeaderboardEntry.count":    source(func(e *wavy.LeaderboardEntry) interface{} { return e.Count }),
+	"LeaderboardEntry.username": source(func(e *wavy.LeaderboardEntry) interface{} { return e.Username }),
+	"LeaderboardEntry.user": source(func(e *wavy.LeaderboardEntry) interface{} {
+		return &user{uri: wavy.UserURI{UserID: e.UserID}}
+	}),
+
+	"GlobalMetrics.totalListens": func(ctx context.Context, p params) (interface{}, error) {
+		res, err := p.loaders.totalListens.load(ctx, struct{}{})
+		if err != nil {
+			return nil, err
+		}
+		return res.TotalListens, nil
+	},
+	"GlobalMetrics.totalUsers": func(ctx context.Context, p params) (interface{}, error) {
+		res, err := p.loaders.totalUsers.load(ctx, struct{}{})
+		if err != nil {
+			return nil, err
+		}
+		return res.TotalUsers, nil
+	},
+}
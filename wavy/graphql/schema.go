@@ -0,0 +1,322 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// scalars are the built in scalar types.
+var scalars = map[string]bool{"Int": true, "Float": true, "String": true, "Boolean": true, "ID": true}
+
+// schema is the executable type system: the object types of the SDL with a resolver per field.
+type schema struct {
+	types map[string]*objectType
+	query *objectType
+}
+
+type objectType struct {
+	name   string
+	fields map[string]*fieldDef
+}
+
+type fieldDef struct {
+	name      string
+	arguments []*inputValueDefinition
+	typ       *typeRef
+	resolve   resolver
+}
+
+// resolver resolves a field of p.source. It returns nil for null.
+type resolver func(ctx context.Context, p params) (interface{}, error)
+
+// params are passed to a resolver: the object the field belongs to, its coerced arguments and the loaders of
+// the request.
+type params struct {
+	source  interface{}
+	args    map[string]interface{}
+	loaders *loaders
+}
+
+// newSchema builds the schema of sdl. resolvers are keyed by "Type.field", every field needs one.
+func newSchema(sdl string, resolvers map[string]resolver) (*schema, error) {
+	defs, err := parseSchema(sdl)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse schema: %w", err)
+	}
+
+	s := &schema{types: make(map[string]*objectType, len(defs))}
+	for _, def := range defs {
+		if _, ok := s.types[def.name]; ok || scalars[def.name] {
+			return nil, fmt.Errorf("type %s is defined twice", def.name)
+		}
+		t := &objectType{name: def.name, fields: make(map[string]*fieldDef, len(def.fields))}
+		for _, f := range def.fields {
+			key := def.name + "." + f.name
+			resolve, ok := resolvers[key]
+			if !ok {
+				return nil, fmt.Errorf("field %s has no resolver", key)
+			}
+			t.fields[f.name] = &fieldDef{name: f.name, arguments: f.arguments, typ: f.typ, resolve: resolve}
+		}
+		s.types[def.name] = t
+	}
+
+	for key := range resolvers {
+		typeName, fieldName, _ := strings.Cut(key, ".")
+		if t, ok := s.types[typeName]; !ok || t.fields[fieldName] == nil {
+			return nil, fmt.Errorf("resolver %s has no field", key)
+		}
+	}
+
+	for _, t := range s.types {
+		for _, f := range t.fields {
+			if name := namedType(f.typ); !scalars[name] && s.types[name] == nil {
+				return nil, fmt.Errorf("field %s.%s has unknown type %s", t.name, f.name, name)
+			}
+			for _, arg := range f.arguments {
+				if name := namedType(arg.typ); !scalars[name] {
+					return nil, fmt.Errorf("argument %s of %s.%s is not a scalar", arg.name, t.name, f.name)
+				}
+			}
+		}
+	}
+
+	if s.query = s.types["Query"]; s.query == nil {
+		return nil, fmt.Errorf("schema does not define the Query type")
+	}
+
+	return s, nil
+}
+
+// namedType returns the name of the type t wraps in lists and non nulls.
+func namedType(t *typeRef) string {
+	for t.elem != nil {
+		t = t.elem
+	}
+	return t.name
+}
+
+// isLeaf reports whether t is a scalar or list of scalars, which have no selections.
+func isLeaf(t *typeRef) bool {
+	return scalars[namedType(t)]
+}
+
+// coerceLiteral coerces the literal v of a document to typ, resolving variables from vars.
+// present is false when v is a variable which is not set.
+func coerceLiteral(typ *typeRef, v value, vars map[string]interface{}) (result interface{}, present bool, err error) {
+	if name, ok := v.(variable); ok {
+		result, present = vars[string(name)]
+		if typ.nonNull && result == nil {
+			return nil, present, fmt.Errorf("expected %s, found null", typ)
+		}
+		return result, present, nil
+	}
+
+	if _, ok := v.(nullValue); ok {
+		if typ.nonNull {
+			return nil, true, fmt.Errorf("expected %s, found null", typ)
+		}
+		return nil, true, nil
+	}
+
+	if typ.elem != nil {
+		list, ok := v.([]value)
+		if !ok {
+			list = []value{v}
+		}
+		out := make([]interface{}, 0, len(list))
+		for _, item := range list {
+			r, present, err := coerceLiteral(typ.elem, item, vars)
+			if err != nil {
+				return nil, true, err
+			}
+			if !present && typ.elem.nonNull {
+				return nil, true, fmt.Errorf("expected %s, found null", typ.elem)
+			}
+			out = append(out, r)
+		}
+		return out, true, nil
+	}
+
+	switch typ.name {
+	case "Int":
+		if raw, ok := v.(intValue); ok {
+			n, err := strconv.ParseInt(string(raw), 10, 32)
+			if err != nil {
+				return nil, true, fmt.Errorf("Int cannot represent %s", raw)
+			}
+			return int(n), true, nil
+		}
+	case "Float":
+		switch raw := v.(type) {
+		case intValue:
+			f, err := strconv.ParseFloat(string(raw), 64)
+			return f, true, err
+		case floatValue:
+			f, err := strconv.ParseFloat(string(raw), 64)
+			return f, true, err
+		}
+	case "String":
+		if s, ok := v.(string); ok {
+			return s, true, nil
+		}
+	case "ID":
+		switch raw := v.(type) {
+		case string:
+			return raw, true, nil
+		case intValue:
+			return string(raw), true, nil
+		}
+	case "Boolean":
+		if b, ok := v.(bool); ok {
+			return b, true, nil
+		}
+	}
+
+	return nil, true, fmt.Errorf("expected %s, found %s", typ, literalString(v))
+}
+
+func literalString(v value) string {
+	switch v := v.(type) {
+	case string:
+		return strconv.Quote(v)
+	case intValue:
+		return string(v)
+	case floatValue:
+		return string(v)
+	case enumValue:
+		return string(v)
+	case bool:
+		return strconv.FormatBool(v)
+	case []value:
+		return "list"
+	case []*objectField:
+		return "object"
+	}
+	return fmt.Sprint(v)
+}
+
+// coerceVariable coerces the decoded json value v of a variable to typ.
+func coerceVariable(typ *typeRef, v interface{}) (interface{}, error) {
+	if v == nil {
+		if typ.nonNull {
+			return nil, fmt.Errorf("expected %s, found null", typ)
+		}
+		return nil, nil
+	}
+
+	if typ.elem != nil {
+		rv := reflect.ValueOf(v)
+		if rv.Kind() != reflect.Slice {
+			item, err := coerceVariable(typ.elem, v)
+			if err != nil {
+				return nil, err
+			}
+			return []interface{}{item}, nil
+		}
+		out := make([]interface{}, rv.Len())
+		for i := range out {
+			item, err := coerceVariable(typ.elem, rv.Index(i).Interface())
+			if err != nil {
+				return nil, fmt.Errorf("at index %d: %w", i, err)
+			}
+			out[i] = item
+		}
+		return out, nil
+	}
+
+	switch typ.name {
+	case "Int":
+		if n, ok := toInt(v); ok && n >= math.MinInt32 && n <= math.MaxInt32 {
+			return int(n), nil
+		}
+	case "Float":
+		switch n := v.(type) {
+		case float64:
+			return n, nil
+		case json.Number:
+			return n.Float64()
+		}
+		if n, ok := toInt(v); ok {
+			return float64(n), nil
+		}
+	case "String":
+		if s, ok := v.(string); ok {
+			return s, nil
+		}
+	case "ID":
+		if s, ok := v.(string); ok {
+			return s, nil
+		}
+		if n, ok := toInt(v); ok {
+			return strconv.FormatInt(n, 10), nil
+		}
+	case "Boolean":
+		if b, ok := v.(bool); ok {
+			return b, nil
+		}
+	}
+
+	return nil, fmt.Errorf("expected %s, found %v", typ, v)
+}
+
+// toInt converts the integral numbers of decoded json and Go to int64.
+func toInt(v interface{}) (int64, bool) {
+	switch n := v.(type) {
+	case int:
+		return int64(n), true
+	case int32:
+		return int64(n), true
+	case int64:
+		return n, true
+	case float64:
+		if n == math.Trunc(n) && math.Abs(n) < 1<<53 {
+			return int64(n), true
+		}
+	case json.Number:
+		i, err := n.Int64()
+		return i, err == nil
+	}
+	return 0, false
+}
+
+// serialize converts the value v resolved for the scalar name to its json representation.
+func serialize(name string, v interface{}) (interface{}, error) {
+	switch name {
+	case "Int":
+		n, ok := toInt(v)
+		if !ok {
+			return nil, fmt.Errorf("Int cannot represent %v", v)
+		}
+		if n < math.MinInt32 || n > math.MaxInt32 {
+			return nil, fmt.Errorf("Int cannot represent %d, it does not fit 32 bits", n)
+		}
+		return n, nil
+	case "Float":
+		if f, ok := v.(float64); ok {
+			return f, nil
+		}
+		if n, ok := toInt(v); ok {
+			return float64(n), nil
+		}
+	case "String", "ID":
+		switch s := v.(type) {
+		case string:
+			return s, nil
+		case time.Time:
+			return s.Format(time.RFC3339), nil
+		}
+	case "Boolean":
+		if b, ok := v.(bool); ok {
+			return b, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%s cannot represent %v", name, v)
+}
@@ -0,0 +1,259 @@
+package graphql
+
+import (
+	"fmt"
+	"sort"
+)
+
+// validator checks an operation against the schema before it is executed, so invalid requests do not reach
+// wavy.fm at all.
+type validator struct {
+	schema   *schema
+	doc      *document
+	maxDepth int
+
+	// defined holds the variables of the operation, used the variables referenced by it.
+	defined map[string]*variableDefinition
+	used    map[string]Location
+	// visiting holds the fragments being validated, to detect cycles.
+	visiting map[string]bool
+	errors   []*Error
+}
+
+// validate returns the errors of op, or nil when it can be executed. Selections nested deeper than maxDepth are
+// rejected when maxDepth is positive.
+func (s *schema) validate(doc *document, op *operation, maxDepth int) []*Error {
+	v := &validator{
+		schema:   s,
+		doc:      doc,
+		maxDepth: maxDepth,
+		defined:  make(map[string]*variableDefinition),
+		used:     make(map[string]Location),
+		visiting: make(map[string]bool),
+	}
+
+	if op.kind != "query" {
+		v.errorf(op.loc, "%s operations are not supported", op.kind)
+		return v.errors
+	}
+
+	for _, def := range op.variables {
+		if _, ok := v.defined[def.name]; ok {
+			v.errorf(def.loc, "there can be only one variable named $%s", def.name)
+		}
+		v.defined[def.name] = def
+		if !scalars[namedType(def.typ)] {
+			v.errorf(def.loc, "variable $%s has unknown input type %s", def.name, def.typ)
+		}
+		if def.defaultValue != nil {
+			if _, _, err := coerceLiteral(def.typ, def.defaultValue, nil); err != nil {
+				v.errorf(def.loc, "invalid default value of variable $%s: %s", def.name, err)
+			}
+		}
+	}
+
+	v.directives(op.directives)
+	v.selections(s.query, op.selections, 1)
+
+	names := make([]string, 0, len(v.used))
+	for name := range v.used {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if _, ok := v.defined[name]; !ok {
+			v.errorf(v.used[name], "variable $%s is not defined", name)
+		}
+	}
+
+	return v.errors
+}
+
+func (v *validator) errorf(loc Location, format string, args ...interface{}) {
+	v.errors = append(v.errors, &Error{Message: fmt.Sprintf(format, args...), Locations: []Location{loc}})
+}
+
+func (v *validator) selections(parent *objectType, sels []selection, depth int) {
+	if v.maxDepth > 0 && depth > v.maxDepth {
+		v.errorf(sels[0].location(), "selection exceeds the maximum depth of %d", v.maxDepth)
+		return
+	}
+
+	for _, sel := range sels {
+		switch sel := sel.(type) {
+		case *field:
+			v.field(parent, sel, depth)
+		case *fragmentSpread:
+			v.directives(sel.directives)
+			f, ok := v.doc.fragments[sel.name]
+			if !ok {
+				v.errorf(sel.loc, "unknown fragment %q", sel.name)
+				continue
+			}
+			if v.visiting[f.name] {
+				v.errorf(sel.loc, "fragment %q spreads itself", f.name)
+				continue
+			}
+			if v.typeCondition(parent, f.typeCondition, sel.loc) {
+				v.visiting[f.name] = true
+				v.directives(f.directives)
+				v.selections(parent, f.selections, depth)
+				delete(v.visiting, f.name)
+			}
+		case *inlineFragment:
+			v.directives(sel.directives)
+			if sel.typeCondition == "" || v.typeCondition(parent, sel.typeCondition, sel.loc) {
+				v.selections(parent, sel.selections, depth)
+			}
+		}
+	}
+}
+
+// typeCondition reports whether fragments on the type called name apply to parent. The schema has no interfaces
+// or unions, so only parent itself matches.
+func (v *validator) typeCondition(parent *objectType, name string, loc Location) bool {
+	if _, ok := v.schema.types[name]; !ok {
+		v.errorf(loc, "unknown type %q", name)
+		return false
+	}
+	if name != parent.name {
+		v.errorf(loc, "fragment on %s can never be spread within %s", name, parent.name)
+		return false
+	}
+	return true
+}
+
+func (v *validator) field(parent *objectType, f *field, depth int) {
+	v.directives(f.directives)
+
+	if f.name == "__typename" {
+		if len(f.arguments) > 0 || f.selections != nil {
+			v.errorf(f.loc, "field __typename takes no arguments or selections")
+		}
+		return
+	}
+
+	def, ok := parent.fields[f.name]
+	if !ok {
+		v.errorf(f.loc, "cannot query field %q on type %s", f.name, parent.name)
+		return
+	}
+
+	v.arguments(def.arguments, f.arguments, fmt.Sprintf("%s.%s", parent.name, f.name), f.loc)
+
+	switch {
+	case isLeaf(def.typ) && f.selections != nil:
+		v.errorf(f.loc, "field %q of type %s must not have a selection", f.name, def.typ)
+	case !isLeaf(def.typ) && f.selections == nil:
+		v.errorf(f.loc, "field %q of type %s must have a selection", f.name, def.typ)
+	case !isLeaf(def.typ):
+		v.selections(v.schema.types[namedType(def.typ)], f.selections, depth+1)
+	}
+}
+
+func (v *validator) directives(dirs []*directive) {
+	for _, d := range dirs {
+		if d.name != "skip" && d.name != "include" {
+			v.errorf(d.loc, "unknown directive @%s", d.name)
+			continue
+		}
+		v.arguments([]*inputValueDefinition{{name: "if", typ: &typeRef{name: "Boolean", nonNull: true}}}, d.arguments, "@"+d.name, d.loc)
+	}
+}
+
+// arguments checks the arguments args passed to the field or directive called owner against their definitions.
+func (v *validator) arguments(defs []*inputValueDefinition, args []*argument, owner string, loc Location) {
+	seen := make(map[string]bool, len(args))
+	for _, arg := range args {
+		if seen[arg.name] {
+			v.errorf(arg.loc, "there can be only one argument named %q", arg.name)
+		}
+		seen[arg.name] = true
+
+		var def *inputValueDefinition
+		for _, d := range defs {
+			if d.name == arg.name {
+				def = d
+			}
+		}
+		if def == nil {
+			v.errorf(arg.loc, "unknown argument %q on %s", arg.name, owner)
+			continue
+		}
+
+		v.variableUsages(def.typ, arg.value, arg.loc)
+		if _, _, err := coerceLiteral(def.typ, arg.value, nil); err != nil && !hasVariable(arg.value) {
+			v.errorf(arg.loc, "invalid value of argument %q: %s", arg.name, err)
+		}
+	}
+
+	for _, def := range defs {
+		if def.typ.nonNull && def.defaultValue == nil && !seen[def.name] {
+			v.errorf(loc, "argument %q of type %s is required on %s", def.name, def.typ, owner)
+		}
+	}
+}
+
+// variableUsages records the variables referenced by val, which is passed where typ is expected, and checks
+// their types are compatible with typ.
+func (v *validator) variableUsages(typ *typeRef, val value, loc Location) {
+	switch val := val.(type) {
+	case variable:
+		if _, ok := v.used[string(val)]; !ok {
+			v.used[string(val)] = loc
+		}
+		def, ok := v.defined[string(val)]
+		if !ok {
+			return
+		}
+		expected := typ
+		if typ.nonNull && !def.typ.nonNull && def.defaultValue != nil {
+			expected = nullable(typ)
+		}
+		if !compatible(def.typ, expected) {
+			v.errorf(loc, "variable $%s of type %s is used where %s is expected", def.name, def.typ, typ)
+		}
+	case []value:
+		elem := typ
+		if typ.elem != nil {
+			elem = typ.elem
+		}
+		for _, item := range val {
+			v.variableUsages(elem, item, loc)
+		}
+	}
+}
+
+// compatible reports whether a variable of type varType can be passed where typ is expected.
+func compatible(varType, typ *typeRef) bool {
+	switch {
+	case typ.nonNull:
+		return varType.nonNull && compatible(nullable(varType), nullable(typ))
+	case varType.nonNull:
+		return compatible(nullable(varType), typ)
+	case typ.elem != nil:
+		return varType.elem != nil && compatible(varType.elem, typ.elem)
+	}
+	return varType.elem == nil && varType.name == typ.name
+}
+
+// nullable returns t without its non null modifier.
+func nullable(t *typeRef) *typeRef {
+	n := *t
+	n.nonNull = false
+	return &n
+}
+
+func hasVariable(val value) bool {
+	switch val := val.(type) {
+	case variable:
+		return true
+	case []value:
+		for _, item := range val {
+			if hasVariable(item) {
+				return true
+			}
+		}
+	}
+	return false
+}
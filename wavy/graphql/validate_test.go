@@ -0,0 +1,61 @@
+package graphql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_schema_validate(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		err   string
+	}{
+		{name: "unknown field", query: `{ nope }`, err: `cannot query field "nope" on type Query`},
+		{name: "missing selection", query: `{ metrics }`, err: `field "metrics" of type GlobalMetrics! must have a selection`},
+		{name: "selection on scalar", query: `{ metrics { totalUsers { a } } }`, err: `field "totalUsers" of type Int! must not have a selection`},
+		{name: "missing argument", query: `{ user { uri } }`, err: `argument "uri" of type String! is required on Query.user`},
+		{name: "unknown argument", query: `{ metrics(a: 1) { totalUsers } }`, err: `unknown argument "a" on Query.metrics`},
+		{name: "duplicate argument", query: `{ user(uri: "a", uri: "b") { uri } }`, err: `there can be only one argument named "uri"`},
+		{name: "invalid argument", query: `{ user(uri: 1) { uri } }`, err: `invalid value of argument "uri": expected String!, found 1`},
+		{name: "int overflow", query: `{ leaderboard { entries(limit: 9999999999) { rank } } }`, err: "Int cannot represent 9999999999"},
+		{name: "unknown fragment", query: `{ ...nope }`, err: `unknown fragment "nope"`},
+		{name: "fragment cycle", query: `{ user(uri: "a") { ...a } } fragment a on User { ...b } fragment b on User { ...a }`, err: `fragment "a" spreads itself`},
+		{name: "fragment type", query: `{ ...u } fragment u on User { uri }`, err: "fragment on User can never be spread within Query"},
+		{name: "unknown type", query: `{ ... on Nope { a } }`, err: `unknown type "Nope"`},
+		{name: "unknown directive", query: `{ metrics @cached { totalUsers } }`, err: "unknown directive @cached"},
+		{name: "directive argument", query: `{ metrics @skip { totalUsers } }`, err: `argument "if" of type Boolean! is required on @skip`},
+		{name: "undefined variable", query: `{ user(uri: $uri) { uri } }`, err: "variable $uri is not defined"},
+		{name: "variable type", query: `query ($uri: Int) { user(uri: $uri) { uri } }`, err: "variable $uri of type Int is used where String! is expected"},
+		{name: "nullable variable", query: `query ($uri: String) { user(uri: $uri) { uri } }`, err: "variable $uri of type String is used where String! is expected"},
+		{name: "input type", query: `query ($u: User) { metrics { totalUsers } }`, err: "variable $u has unknown input type User"},
+		{name: "too deep", query: `{ leaderboard { entries { user { profile { username } } } } }`, err: "selection exceeds the maximum depth of 4"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := parseDocument(tt.query)
+			require.NoError(t, err)
+
+			errs := defaultSchema.validate(doc, doc.operations[0], 4)
+			require.NotEmpty(t, errs)
+			assert.Contains(t, errs[0].Message, tt.err)
+			assert.NotEmpty(t, errs[0].Locations)
+		})
+	}
+}
+
+func Test_schema_validate_valid(t *testing.T) {
+	for _, query := range []string{
+		`query ($uri: String = "wavyfm:user:id:1", $limit: Int) { user(uri: $uri) { recent(limit: $limit) { playId } } }`,
+		`query ($a: String!) { users(uris: [$a, "b"]) { uri } }`,
+		`query ($uris: [String!]!) { users(uris: $uris) { uri } }`,
+		`{ users(uris: "a") { uri } }`,
+		`{ user(uri: "a") { ...u ... @include(if: true) { uri } } } fragment u on User { uri __typename }`,
+	} {
+		doc, err := parseDocument(query)
+		require.NoError(t, err)
+		assert.Empty(t, defaultSchema.validate(doc, doc.operations[0], DefaultMaxDepth), query)
+	}
+}
@@ -0,0 +1,55 @@
+package growth
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	totalListensDesc = prometheus.NewDesc("wavy_sampled_total_listens", "Total amount of listens recorded on wavy.fm at the latest sample.", nil, nil)
+	totalUsersDesc   = prometheus.NewDesc("wavy_sampled_total_users", "Total amount of registered users on wavy.fm at the latest sample.", nil, nil)
+	lastSampleDesc   = prometheus.NewDesc("wavy_sampled_last_sample_timestamp_seconds", "Unix time of the latest sample.", nil, nil)
+	listensRateDesc  = prometheus.NewDesc("wavy_listens_per_minute", "Listens per minute between the two latest samples.", nil, nil)
+	usersRateDesc    = prometheus.NewDesc("wavy_new_users_per_day", "New users per day between the two latest samples.", nil, nil)
+	listensTrendDesc = prometheus.NewDesc("wavy_listens_per_minute_smoothed", "Exponentially smoothed listens per minute.", nil, nil)
+	usersTrendDesc   = prometheus.NewDesc("wavy_new_users_per_day_smoothed", "Exponentially smoothed new users per day.", nil, nil)
+)
+
+type collector struct {
+	s *Sampler
+}
+
+// NewCollector returns a prometheus.Collector exposing the latest sample and rates of s.
+// Collecting never calls the wavy api, the Sampler must be run separately.
+func NewCollector(s *Sampler) prometheus.Collector {
+	return &collector{s: s}
+}
+
+func (c *collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- totalListensDesc
+	ch <- totalUsersDesc
+	ch <- lastSampleDesc
+	ch <- listensRateDesc
+	ch <- usersRateDesc
+	ch <- listensTrendDesc
+	ch <- usersTrendDesc
+}
+
+func (c *collector) Collect(ch chan<- prometheus.Metric) {
+	latest, ok := c.s.Series().Latest()
+	if !ok {
+		return
+	}
+
+	ch <- prometheus.MustNewConstMetric(totalListensDesc, prometheus.GaugeValue, float64(latest.TotalListens))
+	ch <- prometheus.MustNewConstMetric(totalUsersDesc, prometheus.GaugeValue, float64(latest.TotalUsers))
+	ch <- prometheus.MustNewConstMetric(lastSampleDesc, prometheus.GaugeValue, float64(latest.Time.UnixNano())/1e9)
+
+	if rates, ok := c.s.Rates(); ok {
+		ch <- prometheus.MustNewConstMetric(listensRateDesc, prometheus.GaugeValue, rates.ListensPerMinute)
+		ch <- prometheus.MustNewConstMetric(usersRateDesc, prometheus.GaugeValue, rates.NewUsersPerDay)
+	}
+	if trend, ok := c.s.Trend(); ok {
+		ch <- prometheus.MustNewConstMetric(listensTrendDesc, prometheus.GaugeValue, trend.ListensPerMinute)
+		ch <- prometheus.MustNewConstMetric(usersTrendDesc, prometheus.GaugeValue, trend.NewUsersPerDay)
+	}
+}
@@ -0,0 +1,31 @@
+package growth
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/OGKevin/go-wavy/wavy/wavytest"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewCollector(t *testing.T) {
+	s, _ := newSampler(t, wavytest.NewClient(), Options{})
+	collector := NewCollector(s)
+
+	assert.Equal(t, 0, testutil.CollectAndCount(collector))
+
+	s.Series().Add(point(0, 100, 10))
+	s.Series().Add(point(1, 160, 10))
+
+	expected := `
+# HELP wavy_listens_per_minute Listens per minute between the two latest samples.
+# TYPE wavy_listens_per_minute gauge
+wavy_listens_per_minute 60
+# HELP wavy_sampled_total_listens Total amount of listens recorded on wavy.fm at the latest sample.
+# TYPE wavy_sampled_total_listens gauge
+wavy_sampled_total_listens 160
+`
+	assert.NoError(t, testutil.CollectAndCompare(collector, strings.NewReader(expected), "wavy_listens_per_minute", "wavy_sampled_total_listens"))
+	assert.Equal(t, 7, testutil.CollectAndCount(collector))
+}
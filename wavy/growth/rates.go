@@ -0,0 +1,41 @@
+package growth
+
+// Rates is the growth of the platform between points in time.
+type Rates struct {
+	ListensPerMinute float64 `json:"listens_per_minute"`
+	NewUsersPerDay   float64 `json:"new_users_per_day"`
+}
+
+// Between returns the rates from a to b. ok is false when b was not taken after a.
+func Between(a, b Point) (r Rates, ok bool) {
+	elapsed := b.Time.Sub(a.Time)
+	if elapsed <= 0 {
+		return Rates{}, false
+	}
+
+	return Rates{
+		ListensPerMinute: float64(b.TotalListens-a.TotalListens) / elapsed.Minutes(),
+		NewUsersPerDay:   float64(b.TotalUsers-a.TotalUsers) / (elapsed.Hours() / 24),
+	}, true
+}
+
+// Smooth returns the exponentially weighted moving average of the rates between consecutive points.
+// alpha in (0, 1] is the weight of the most recent rate, higher values follow changes faster.
+// ok is false when there are less than two points.
+func Smooth(points []Point, alpha float64) (r Rates, ok bool) {
+	for i := 1; i < len(points); i++ {
+		cur, valid := Between(points[i-1], points[i])
+		if !valid {
+			continue
+		}
+		if !ok {
+			r, ok = cur, true
+			continue
+		}
+
+		r.ListensPerMinute = alpha*cur.ListensPerMinute + (1-alpha)*r.ListensPerMinute
+		r.NewUsersPerDay = alpha*cur.NewUsersPerDay + (1-alpha)*r.NewUsersPerDay
+	}
+
+	return r, ok
+}
@@ -0,0 +1,32 @@
+package growth
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBetween(t *testing.T) {
+	got, ok := Between(point(0, 100, 10), point(60, 700, 15))
+	assert.True(t, ok)
+	assert.Equal(t, Rates{ListensPerMinute: 10, NewUsersPerDay: 120}, got)
+
+	_, ok = Between(point(1, 0, 0), point(1, 10, 0))
+	assert.False(t, ok)
+}
+
+func TestSmooth(t *testing.T) {
+	points := []Point{point(0, 0, 0), point(1, 10, 0), point(2, 30, 0), point(3, 60, 0)}
+
+	got, ok := Smooth(points, 0.5)
+	assert.True(t, ok)
+	// 10, then 0.5*20+0.5*10 = 15, then 0.5*30+0.5*15 = 22.5
+	assert.InDelta(t, 22.5, got.ListensPerMinute, 1e-9)
+
+	got, ok = Smooth(points, 1)
+	assert.True(t, ok)
+	assert.InDelta(t, 30, got.ListensPerMinute, 1e-9)
+
+	_, ok = Smooth(points[:1], 0.5)
+	assert.False(t, ok)
+}
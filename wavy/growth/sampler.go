@@ -0,0 +1,214 @@
+// Package growth samples the global wavy.fm metrics into a time series and estimates how fast the platform grows.
+package growth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/OGKevin/go-wavy/internal/ndjson"
+	"github.com/OGKevin/go-wavy/internal/poll"
+	"github.com/OGKevin/go-wavy/wavy"
+	"github.com/hashicorp/go-hclog"
+)
+
+const (
+	// DefaultCapacity keeps a day of samples when sampling every minute.
+	DefaultCapacity = 1440
+	// DefaultSmoothing is the default weight of the most recent rate in the smoothed trend.
+	DefaultSmoothing = 0.3
+)
+
+// Options configures a Sampler.
+type Options struct {
+	// Capacity is the amount of points kept in the series, DefaultCapacity when 0.
+	Capacity int
+	// Smoothing is the alpha used for the smoothed trend, DefaultSmoothing when 0.
+	Smoothing float64
+	// Path optionally persists the series as newline-delimited JSON, so it survives restarts.
+	Path string
+}
+
+// Sampler polls GetTotalListens and GetTotalUsers into a Series.
+type Sampler struct {
+	c      wavy.Client
+	series *Series
+	opts   Options
+	logger hclog.Logger
+	now    func() time.Time
+
+	// fileMu guards the persisted series and lines, the amount of points in it.
+	fileMu sync.Mutex
+	lines  int
+}
+
+// NewSampler returns a Sampler. When opts.Path is set, the points persisted there are loaded.
+func NewSampler(c wavy.Client, logger hclog.Logger, opts Options) (*Sampler, error) {
+	if opts.Capacity == 0 {
+		opts.Capacity = DefaultCapacity
+	}
+	if opts.Smoothing <= 0 || opts.Smoothing > 1 {
+		opts.Smoothing = DefaultSmoothing
+	}
+	if logger == nil {
+		logger = hclog.NewNullLogger()
+	}
+
+	s := &Sampler{
+		c:      c,
+		series: NewSeries(opts.Capacity),
+		opts:   opts,
+		logger: logger.Named("growth-sampler"),
+		now:    time.Now,
+	}
+
+	if opts.Path != "" {
+		if err := s.load(); err != nil {
+			return nil, fmt.Errorf("%s: failed to load series from %q: %w", s.logger.Name(), opts.Path, err)
+		}
+	}
+
+	return s, nil
+}
+
+func (s *Sampler) load() error {
+	return ndjson.Read(s.opts.Path, func(p Point) {
+		s.series.Add(p)
+		s.lines++
+	})
+}
+
+// persist appends p to the persisted series. Once it holds twice the capacity, it is rewritten to only hold the series.
+func (s *Sampler) persist(p Point) error {
+	s.fileMu.Lock()
+	defer s.fileMu.Unlock()
+
+	if s.lines >= 2*s.series.Cap() {
+		return s.compact()
+	}
+
+	if err := ndjson.Append(s.opts.Path, p); err != nil {
+		return err
+	}
+
+	s.lines++
+	return nil
+}
+
+func (s *Sampler) compact() error {
+	points := s.series.Points()
+	if err := ndjson.Rewrite(s.opts.Path, points); err != nil {
+		return err
+	}
+
+	s.lines = len(points)
+	return nil
+}
+
+// Sample fetches the totals and adds them to the series.
+func (s *Sampler) Sample(ctx context.Context) (Point, error) {
+	s.logger.Trace("sampling global metrics")
+	defer s.logger.Trace("finished sampling global metrics")
+
+	listens, err := s.c.MetricsService().GetTotalListens(ctx)
+	if err != nil {
+		return Point{}, fmt.Errorf("%s: failed to fetch total listens: %w", s.logger.Name(), err)
+	}
+	users, err := s.c.MetricsService().GetTotalUsers(ctx)
+	if err != nil {
+		return Point{}, fmt.Errorf("%s: failed to fetch total users: %w", s.logger.Name(), err)
+	}
+
+	p := Point{Time: s.now(), TotalListens: listens.TotalListens, TotalUsers: users.TotalUsers}
+	if !s.series.Add(p) {
+		return p, nil
+	}
+
+	if s.opts.Path != "" {
+		if err := s.persist(p); err != nil {
+			return p, fmt.Errorf("%s: failed to persist point to %q: %w", s.logger.Name(), s.opts.Path, err)
+		}
+	}
+
+	return p, nil
+}
+
+// Run samples every interval until ctx is done. Failures are logged and do not stop the sampler.
+func (s *Sampler) Run(ctx context.Context, interval time.Duration) {
+	poll.Every(ctx, interval, func(ctx context.Context) {
+		if _, err := s.Sample(ctx); err != nil {
+			s.logger.Error("failed to sample", "error", err)
+		}
+	})
+}
+
+// Series returns the sampled series.
+func (s *Sampler) Series() *Series {
+	return s.series
+}
+
+// Rates returns the rates between the two most recent points. ok is false when there are less than two points.
+func (s *Sampler) Rates() (r Rates, ok bool) {
+	points := s.series.Points()
+	if len(points) < 2 {
+		return Rates{}, false
+	}
+	return Between(points[len(points)-2], points[len(points)-1])
+}
+
+// RatesOver returns the average rates over the window leading up to the most recent point.
+func (s *Sampler) RatesOver(window time.Duration) (r Rates, ok bool) {
+	latest, ok := s.series.Latest()
+	if !ok {
+		return Rates{}, false
+	}
+
+	points := s.series.Since(latest.Time.Add(-window))
+	if len(points) < 2 {
+		return Rates{}, false
+	}
+	return Between(points[0], latest)
+}
+
+// Trend returns the smoothed rates over the whole series.
+func (s *Sampler) Trend() (r Rates, ok bool) {
+	return Smooth(s.series.Points(), s.opts.Smoothing)
+}
+
+type seriesResponse struct {
+	Points []Point `json:"points"`
+	Rates  *Rates  `json:"rates"`
+	Trend  *Rates  `json:"trend"`
+}
+
+// ServeHTTP serves the series, the latest rates and the trend as JSON.
+// The since query parameter, an RFC 3339 timestamp, limits the points returned.
+func (s *Sampler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	res := seriesResponse{Points: s.series.Points()}
+
+	if raw := r.URL.Query().Get("since"); raw != "" {
+		since, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("invalid since: %s", err), http.StatusBadRequest)
+			return
+		}
+		res.Points = s.series.Since(since)
+	}
+	if res.Points == nil {
+		res.Points = []Point{}
+	}
+	if rates, ok := s.Rates(); ok {
+		res.Rates = &rates
+	}
+	if trend, ok := s.Trend(); ok {
+		res.Trend = &trend
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(res); err != nil {
+		s.logger.Warn("failed to write series response", "error", err)
+	}
+}
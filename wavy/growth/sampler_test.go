@@ -0,0 +1,106 @@
+package growth
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/OGKevin/go-wavy/wavy/wavytest"
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newSampler(t *testing.T, c *wavytest.Client, opts Options) (*Sampler, *time.Time) {
+	s, err := NewSampler(c, hclog.NewNullLogger(), opts)
+	require.NoError(t, err)
+
+	now := start
+	s.now = func() time.Time { return now }
+	return s, &now
+}
+
+func TestSampler_Sample(t *testing.T) {
+	ctx := context.Background()
+	c := wavytest.NewClient()
+	s, now := newSampler(t, c, Options{})
+
+	c.TotalListens, c.TotalUsers = 1000, 10
+	_, err := s.Sample(ctx)
+	require.NoError(t, err)
+	_, ok := s.Rates()
+	assert.False(t, ok)
+
+	*now = now.Add(time.Minute)
+	c.TotalListens, c.TotalUsers = 1060, 11
+	p, err := s.Sample(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, Point{Time: *now, TotalListens: 1060, TotalUsers: 11}, p)
+
+	rates, ok := s.Rates()
+	assert.True(t, ok)
+	assert.Equal(t, Rates{ListensPerMinute: 60, NewUsersPerDay: 1440}, rates)
+
+	rates, ok = s.RatesOver(time.Hour)
+	assert.True(t, ok)
+	assert.Equal(t, 60.0, rates.ListensPerMinute)
+
+	c.Err = assert.AnError
+	_, err = s.Sample(ctx)
+	assert.Error(t, err)
+	assert.Equal(t, 2, s.Series().Len())
+}
+
+func TestSampler_persist(t *testing.T) {
+	ctx := context.Background()
+	dir, err := ioutil.TempDir("", "go-wavy-growth")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "series.ndjson")
+
+	c := wavytest.NewClient()
+	s, now := newSampler(t, c, Options{Capacity: 2, Path: path})
+	for i := 0; i < 6; i++ {
+		*now = start.Add(time.Duration(i) * time.Minute)
+		c.TotalListens = int64(i)
+		_, err := s.Sample(ctx)
+		require.NoError(t, err)
+	}
+
+	// The file is compacted once it holds twice the capacity.
+	raw, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, 3, strings.Count(string(raw), "\n"))
+
+	s, _ = newSampler(t, c, Options{Capacity: 2, Path: path})
+	assert.Equal(t, []Point{point(4, 4, 0), point(5, 5, 0)}, s.Series().Points())
+}
+
+func TestSampler_ServeHTTP(t *testing.T) {
+	c := wavytest.NewClient()
+	s, _ := newSampler(t, c, Options{})
+	s.Series().Add(point(0, 0, 0))
+	s.Series().Add(point(1, 10, 0))
+	s.Series().Add(point(2, 30, 0))
+
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/?since="+point(1, 0, 0).Time.Format(time.RFC3339), nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var res seriesResponse
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&res))
+	assert.Len(t, res.Points, 2)
+	assert.Equal(t, 20.0, res.Rates.ListensPerMinute)
+	assert.NotNil(t, res.Trend)
+
+	rec = httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/?since=yesterday", nil))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
@@ -0,0 +1,104 @@
+package growth
+
+import (
+	"sync"
+	"time"
+)
+
+// Point is a single sample of the global wavy.fm metrics.
+type Point struct {
+	Time         time.Time `json:"time"`
+	TotalListens int64     `json:"total_listens"`
+	TotalUsers   int64     `json:"total_users"`
+}
+
+// Series is a fixed capacity ring buffer of points, ordered by time. Once full, the oldest point is overwritten.
+// A Series is safe for concurrent use.
+type Series struct {
+	mu     sync.RWMutex
+	points []Point
+	start  int
+	size   int
+}
+
+// NewSeries returns an empty series holding at most capacity points.
+func NewSeries(capacity int) *Series {
+	if capacity < 1 {
+		capacity = 1
+	}
+
+	return &Series{points: make([]Point, capacity)}
+}
+
+// Add appends a point and reports whether it was added.
+// Points older than the latest point are dropped, since samples are expected in order.
+func (s *Series) Add(p Point) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.size > 0 && p.Time.Before(s.at(s.size-1).Time) {
+		return false
+	}
+
+	if s.size < len(s.points) {
+		s.points[(s.start+s.size)%len(s.points)] = p
+		s.size++
+		return true
+	}
+
+	s.points[s.start] = p
+	s.start = (s.start + 1) % len(s.points)
+	return true
+}
+
+// at returns the i-th oldest point. s.mu must be held.
+func (s *Series) at(i int) Point {
+	return s.points[(s.start+i)%len(s.points)]
+}
+
+// Len returns the amount of points in the series.
+func (s *Series) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.size
+}
+
+// Cap returns the maximum amount of points in the series.
+func (s *Series) Cap() int {
+	return len(s.points)
+}
+
+// Points returns a copy of the points, oldest first.
+func (s *Series) Points() []Point {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	points := make([]Point, 0, s.size)
+	for i := 0; i < s.size; i++ {
+		points = append(points, s.at(i))
+	}
+	return points
+}
+
+// Since returns the points taken at or after t, oldest first.
+func (s *Series) Since(t time.Time) []Point {
+	var points []Point
+	for _, p := range s.Points() {
+		if !p.Time.Before(t) {
+			points = append(points, p)
+		}
+	}
+	return points
+}
+
+// Latest returns the most recent point. ok is false when the series is empty.
+func (s *Series) Latest() (p Point, ok bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.size == 0 {
+		return Point{}, false
+	}
+	return s.at(s.size - 1), true
+}
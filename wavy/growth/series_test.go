@@ -0,0 +1,34 @@
+package growth
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var start = time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC)
+
+func point(minutes int, listens, users int64) Point {
+	return Point{Time: start.Add(time.Duration(minutes) * time.Minute), TotalListens: listens, TotalUsers: users}
+}
+
+func TestSeries_Add(t *testing.T) {
+	s := NewSeries(3)
+
+	_, ok := s.Latest()
+	assert.False(t, ok)
+
+	for i := 0; i < 5; i++ {
+		assert.True(t, s.Add(point(i, int64(i), int64(i))))
+	}
+	assert.False(t, s.Add(point(1, 0, 0)), "points older than the latest must be dropped")
+
+	assert.Equal(t, 3, s.Len())
+	assert.Equal(t, []Point{point(2, 2, 2), point(3, 3, 3), point(4, 4, 4)}, s.Points())
+	assert.Equal(t, []Point{point(3, 3, 3), point(4, 4, 4)}, s.Since(point(3, 0, 0).Time))
+
+	latest, ok := s.Latest()
+	assert.True(t, ok)
+	assert.Equal(t, point(4, 4, 4), latest)
+}
@@ -0,0 +1,146 @@
+// Package lastfm converts wavy.fm history into Last.fm scrobbles and back.
+// The scrobble parameters are described at: https://www.last.fm/api/show/track.scrobble
+package lastfm
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/OGKevin/go-wavy/wavy"
+)
+
+// MaxBatchSize is the maximum amount of scrobbles Last.fm accepts in a single track.scrobble call.
+const MaxBatchSize = 50
+
+// ArtistSeparator joins the names of tracks with more than one artist.
+// Last.fm only has a single artist field, so the full credit is submitted.
+const ArtistSeparator = ", "
+
+// Scrobble holds the parameters of a single track.scrobble entry.
+type Scrobble struct {
+	Artist      string
+	Track       string
+	Album       string
+	AlbumArtist string
+	Timestamp   int64
+}
+
+// NowPlaying holds the parameters of a track.updateNowPlaying call.
+type NowPlaying struct {
+	Artist      string
+	Track       string
+	Album       string
+	AlbumArtist string
+}
+
+// FromItem converts a listen from the user's history.
+func FromItem(item wavy.Item) Scrobble {
+	np := FromCurrent(item.CurrentPlayingItem)
+
+	return Scrobble{
+		Artist:      np.Artist,
+		Track:       np.Track,
+		Album:       np.Album,
+		AlbumArtist: np.AlbumArtist,
+		Timestamp:   item.Date.Unix(),
+	}
+}
+
+// FromCurrent converts the item a user is currently listening to.
+// Local tracks convert like any other track since Last.fm only needs their names.
+func FromCurrent(item wavy.CurrentPlayingItem) NowPlaying {
+	names := make([]string, 0, len(item.Artists))
+	for _, artist := range item.Artists {
+		names = append(names, artist.Name)
+	}
+
+	return NowPlaying{
+		Artist: strings.Join(names, ArtistSeparator),
+		Track:  item.Song.Name,
+		Album:  item.Album.Name,
+	}
+}
+
+// ToItem converts a scrobble into a history item.
+// Scrobbles carry no source information, so the item has no sources, source urls or PlayID,
+// and the artist credit is kept as a single artist since names may contain the separator themselves.
+func ToItem(s Scrobble) wavy.Item {
+	item := wavy.Item{
+		CurrentPlayingItem: wavy.CurrentPlayingItem{
+			Song:  wavy.Song{Name: s.Track},
+			Album: wavy.Album{Name: s.Album},
+		},
+		Date: time.Unix(s.Timestamp, 0).UTC(),
+	}
+	if s.Artist != "" {
+		item.Artists = []wavy.Artists{{Name: s.Artist}}
+	}
+
+	return item
+}
+
+// Params returns the parameters for a track.updateNowPlaying call, excluding authentication.
+func (n NowPlaying) Params() url.Values {
+	v := url.Values{}
+	v.Set("artist", n.Artist)
+	v.Set("track", n.Track)
+	if n.Album != "" {
+		v.Set("album", n.Album)
+	}
+	if n.AlbumArtist != "" {
+		v.Set("albumArtist", n.AlbumArtist)
+	}
+
+	return v
+}
+
+// ScrobbleParams returns the parameters for a batched track.scrobble call, excluding authentication.
+func ScrobbleParams(scrobbles ...Scrobble) (url.Values, error) {
+	if len(scrobbles) > MaxBatchSize {
+		return nil, fmt.Errorf("lastfm: a batch holds at most %d scrobbles, got %d", MaxBatchSize, len(scrobbles))
+	}
+
+	v := url.Values{}
+	for i, s := range scrobbles {
+		v.Set(fmt.Sprintf("artist[%d]", i), s.Artist)
+		v.Set(fmt.Sprintf("track[%d]", i), s.Track)
+		v.Set(fmt.Sprintf("timestamp[%d]", i), strconv.FormatInt(s.Timestamp, 10))
+		if s.Album != "" {
+			v.Set(fmt.Sprintf("album[%d]", i), s.Album)
+		}
+		if s.AlbumArtist != "" {
+			v.Set(fmt.Sprintf("albumArtist[%d]", i), s.AlbumArtist)
+		}
+	}
+
+	return v, nil
+}
+
+// ParseScrobbleParams is the reverse of ScrobbleParams, for importing batched track.scrobble calls.
+func ParseScrobbleParams(v url.Values) ([]Scrobble, error) {
+	var scrobbles []Scrobble
+	for i := 0; i < MaxBatchSize; i++ {
+		track := v.Get(fmt.Sprintf("track[%d]", i))
+		if track == "" {
+			break
+		}
+
+		timestamp, err := strconv.ParseInt(v.Get(fmt.Sprintf("timestamp[%d]", i)), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("lastfm: failed to parse timestamp of scrobble %d: %w", i, err)
+		}
+
+		scrobbles = append(scrobbles, Scrobble{
+			Artist:      v.Get(fmt.Sprintf("artist[%d]", i)),
+			Track:       track,
+			Album:       v.Get(fmt.Sprintf("album[%d]", i)),
+			AlbumArtist: v.Get(fmt.Sprintf("albumArtist[%d]", i)),
+			Timestamp:   timestamp,
+		})
+	}
+
+	return scrobbles, nil
+}
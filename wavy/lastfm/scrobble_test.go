@@ -0,0 +1,114 @@
+package lastfm
+
+import (
+	"testing"
+	"time"
+
+	"github.com/OGKevin/go-wavy/wavy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var playedAt = time.Date(2021, 3, 1, 12, 30, 0, 0, time.UTC)
+
+func item(local bool, artists ...string) wavy.Item {
+	i := wavy.Item{
+		CurrentPlayingItem: wavy.CurrentPlayingItem{
+			Local: local,
+			Song:  wavy.Song{Source: "spotify", Name: "Song"},
+			Album: wavy.Album{Source: "spotify", Name: "Album"},
+		},
+		Date:   playedAt,
+		PlayID: "play",
+	}
+	if !local {
+		i.Song.SourceURL = "https://open.spotify.com/track/4uLU6hMCjMI75M1A2tKUQC"
+	}
+	for _, name := range artists {
+		i.Artists = append(i.Artists, wavy.Artists{Source: "spotify", Name: name})
+	}
+	return i
+}
+
+func TestFromItem(t *testing.T) {
+	type args struct {
+		item wavy.Item
+	}
+	tests := []struct {
+		name string
+		args args
+		want Scrobble
+	}{
+		{
+			name: "single artist",
+			args: args{item: item(false, "Rick Astley")},
+			want: Scrobble{Artist: "Rick Astley", Track: "Song", Album: "Album", Timestamp: playedAt.Unix()},
+		},
+		{
+			name: "multiple artists",
+			args: args{item: item(false, "Rick Astley", "Queen")},
+			want: Scrobble{Artist: "Rick Astley, Queen", Track: "Song", Album: "Album", Timestamp: playedAt.Unix()},
+		},
+		{
+			name: "local",
+			args: args{item: item(true, "Nobody")},
+			want: Scrobble{Artist: "Nobody", Track: "Song", Album: "Album", Timestamp: playedAt.Unix()},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, FromItem(tt.args.item))
+		})
+	}
+}
+
+func TestToItem_roundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		item wavy.Item
+	}{
+		{name: "single artist", item: item(false, "Rick Astley")},
+		{name: "multiple artists", item: item(false, "Rick Astley", "Queen")},
+		{name: "local", item: item(true, "Nobody")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params, err := ScrobbleParams(FromItem(tt.item))
+			require.NoError(t, err)
+			scrobbles, err := ParseScrobbleParams(params)
+			require.NoError(t, err)
+			require.Len(t, scrobbles, 1)
+
+			got := ToItem(scrobbles[0])
+			assert.Equal(t, tt.item.Song.Name, got.Song.Name)
+			assert.Equal(t, tt.item.Album.Name, got.Album.Name)
+			assert.Equal(t, tt.item.Date, got.Date)
+			assert.Equal(t, FromItem(tt.item), FromItem(got))
+		})
+	}
+}
+
+func TestScrobbleParams(t *testing.T) {
+	params, err := ScrobbleParams(
+		Scrobble{Artist: "A", Track: "One", Timestamp: 1},
+		Scrobble{Artist: "B", Track: "Two", Album: "Album", Timestamp: 2},
+	)
+	require.NoError(t, err)
+
+	assert.Equal(t, "A", params.Get("artist[0]"))
+	assert.Equal(t, "2", params.Get("timestamp[1]"))
+	assert.Equal(t, "Album", params.Get("album[1]"))
+	assert.NotContains(t, params, "album[0]")
+
+	_, err = ScrobbleParams(make([]Scrobble, MaxBatchSize+1)...)
+	assert.Error(t, err)
+}
+
+func TestNowPlaying_Params(t *testing.T) {
+	params := FromCurrent(item(false, "Rick Astley").CurrentPlayingItem).Params()
+
+	assert.Equal(t, "Rick Astley", params.Get("artist"))
+	assert.Equal(t, "Song", params.Get("track"))
+	assert.Equal(t, "Album", params.Get("album"))
+	assert.NotContains(t, params, "timestamp")
+}
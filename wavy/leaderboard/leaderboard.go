@@ -0,0 +1,129 @@
+// Package leaderboard tracks the wavy.fm user listens leaderboard over time.
+//
+// A Tracker periodically samples MetricsService.GetUserListensLeaderboard, keeps the snapshots in a
+// SnapshotStore and reports how the board changed between samples: users entering or leaving the board,
+// rank changes and the listen velocity of every user who stayed on it.
+package leaderboard
+
+import (
+	"sort"
+	"time"
+
+	"github.com/OGKevin/go-wavy/wavy"
+)
+
+// Snapshot is the leaderboard as it was at a point in time.
+type Snapshot struct {
+	Time    time.Time `json:"time"`
+	Entries []Entry   `json:"entries"`
+}
+
+// Entry is a single user on the leaderboard. Rank starts at 1.
+type Entry struct {
+	Rank     int    `json:"rank"`
+	UserID   string `json:"user_id"`
+	Username string `json:"username"`
+	Count    int64  `json:"count"`
+}
+
+// NewSnapshot converts a leaderboard response taken at t.
+func NewSnapshot(t time.Time, res *wavy.UserListensLeaderboardResponse) Snapshot {
+	s := Snapshot{Time: t, Entries: make([]Entry, 0, len(res.Entries))}
+	for _, e := range res.Entries {
+		s.Entries = append(s.Entries, Entry{
+			Rank:     e.Rank,
+			UserID:   e.UserID,
+			Username: e.Username,
+			Count:    e.Count,
+		})
+	}
+	return s
+}
+
+// EventType defines what changed on the leaderboard.
+type EventType string
+
+const (
+	EventEntered     EventType = "entered"
+	EventLeft        EventType = "left"
+	EventRankChanged EventType = "rank_changed"
+)
+
+// Event describes a change of a single user between two snapshots.
+// OldRank is 0 for EventEntered and NewRank is 0 for EventLeft.
+type Event struct {
+	Type     EventType `json:"type"`
+	Time     time.Time `json:"time"`
+	UserID   string    `json:"user_id"`
+	Username string    `json:"username"`
+	OldRank  int       `json:"old_rank"`
+	NewRank  int       `json:"new_rank"`
+}
+
+// Velocity is the rate at which a user gained listens between two snapshots.
+type Velocity struct {
+	UserID         string        `json:"user_id"`
+	Username       string        `json:"username"`
+	Listens        int64         `json:"listens"`
+	Over           time.Duration `json:"over"`
+	ListensPerHour float64       `json:"listens_per_hour"`
+}
+
+// Diff is the comparison of two snapshots.
+type Diff struct {
+	Previous   Snapshot   `json:"previous"`
+	Current    Snapshot   `json:"current"`
+	Events     []Event    `json:"events"`
+	Velocities []Velocity `json:"velocities"`
+}
+
+// Compare reports the changes from prev to cur. Users are matched by UserID.
+// Events are ordered by the rank they concern, velocities by listens per hour descending.
+func Compare(prev, cur Snapshot) Diff {
+	d := Diff{Previous: prev, Current: cur}
+
+	before := make(map[string]Entry, len(prev.Entries))
+	for _, e := range prev.Entries {
+		before[e.UserID] = e
+	}
+
+	elapsed := cur.Time.Sub(prev.Time)
+	for _, e := range cur.Entries {
+		old, ok := before[e.UserID]
+		delete(before, e.UserID)
+
+		if !ok {
+			d.Events = append(d.Events, Event{Type: EventEntered, Time: cur.Time, UserID: e.UserID, Username: e.Username, NewRank: e.Rank})
+			continue
+		}
+		if old.Rank != e.Rank {
+			d.Events = append(d.Events, Event{Type: EventRankChanged, Time: cur.Time, UserID: e.UserID, Username: e.Username, OldRank: old.Rank, NewRank: e.Rank})
+		}
+
+		v := Velocity{UserID: e.UserID, Username: e.Username, Listens: e.Count - old.Count, Over: elapsed}
+		if elapsed > 0 {
+			v.ListensPerHour = float64(v.Listens) / elapsed.Hours()
+		}
+		d.Velocities = append(d.Velocities, v)
+	}
+
+	for _, e := range before {
+		d.Events = append(d.Events, Event{Type: EventLeft, Time: cur.Time, UserID: e.UserID, Username: e.Username, OldRank: e.Rank})
+	}
+
+	sort.SliceStable(d.Events, func(i, j int) bool {
+		return eventRank(d.Events[i]) < eventRank(d.Events[j])
+	})
+	sort.SliceStable(d.Velocities, func(i, j int) bool {
+		return d.Velocities[i].ListensPerHour > d.Velocities[j].ListensPerHour
+	})
+
+	return d
+}
+
+func eventRank(e Event) int {
+	if e.NewRank != 0 {
+		return e.NewRank
+	}
+	return e.OldRank
+}
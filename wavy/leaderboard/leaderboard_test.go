@@ -0,0 +1,58 @@
+package leaderboard
+
+import (
+	"testing"
+	"time"
+
+	"github.com/OGKevin/go-wavy/wavy"
+	"github.com/stretchr/testify/assert"
+)
+
+var start = time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC)
+
+func TestNewSnapshot(t *testing.T) {
+	got := NewSnapshot(start, &wavy.UserListensLeaderboardResponse{Entries: []wavy.LeaderboardEntry{
+		{Rank: 1, Count: 20, Username: "a", UserID: "1"},
+		{Rank: 2, Count: 10, Username: "b", UserID: "2"},
+	}})
+
+	assert.Equal(t, Snapshot{Time: start, Entries: []Entry{
+		{Rank: 1, UserID: "1", Username: "a", Count: 20},
+		{Rank: 2, UserID: "2", Username: "b", Count: 10},
+	}}, got)
+}
+
+func TestCompare(t *testing.T) {
+	prev := Snapshot{Time: start, Entries: []Entry{
+		{Rank: 1, UserID: "1", Username: "a", Count: 300},
+		{Rank: 2, UserID: "2", Username: "b", Count: 200},
+		{Rank: 3, UserID: "3", Username: "c", Count: 100},
+	}}
+	cur := Snapshot{Time: start.Add(2 * time.Hour), Entries: []Entry{
+		{Rank: 1, UserID: "2", Username: "b", Count: 320},
+		{Rank: 2, UserID: "1", Username: "a", Count: 310},
+		{Rank: 3, UserID: "4", Username: "d", Count: 150},
+	}}
+
+	got := Compare(prev, cur)
+
+	assert.Equal(t, []Event{
+		{Type: EventRankChanged, Time: cur.Time, UserID: "2", Username: "b", OldRank: 2, NewRank: 1},
+		{Type: EventRankChanged, Time: cur.Time, UserID: "1", Username: "a", OldRank: 1, NewRank: 2},
+		{Type: EventEntered, Time: cur.Time, UserID: "4", Username: "d", NewRank: 3},
+		{Type: EventLeft, Time: cur.Time, UserID: "3", Username: "c", OldRank: 3},
+	}, got.Events)
+
+	assert.Equal(t, []Velocity{
+		{UserID: "2", Username: "b", Listens: 120, Over: 2 * time.Hour, ListensPerHour: 60},
+		{UserID: "1", Username: "a", Listens: 10, Over: 2 * time.Hour, ListensPerHour: 5},
+	}, got.Velocities)
+}
+
+func TestCompare_unchanged(t *testing.T) {
+	s := Snapshot{Time: start, Entries: []Entry{{Rank: 1, UserID: "1", Username: "a", Count: 1}}}
+
+	got := Compare(s, s)
+	assert.Empty(t, got.Events)
+	assert.Equal(t, []Velocity{{UserID: "1", Username: "a"}}, got.Velocities)
+}
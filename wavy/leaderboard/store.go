@@ -0,0 +1,112 @@
+package leaderboard
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/OGKevin/go-wavy/internal/ndjson"
+)
+
+// ErrNoSnapshot is returned by SnapshotStore.Latest when no snapshot was stored yet.
+var ErrNoSnapshot = errors.New("leaderboard: no snapshot stored")
+
+// SnapshotStore
+// This interface marks the contract for persisting leaderboard snapshots. Implementations must be safe for concurrent use.
+type SnapshotStore interface {
+	// Add stores a snapshot.
+	Add(ctx context.Context, s Snapshot) error
+	// Latest returns the most recent snapshot. ErrNoSnapshot is returned when nothing is stored yet.
+	Latest(ctx context.Context) (*Snapshot, error)
+	// Range returns the snapshots taken in [from, to), ordered by time ascending. A zero from or to leaves that side open.
+	Range(ctx context.Context, from, to time.Time) ([]Snapshot, error)
+}
+
+type memorySnapshotStore struct {
+	mu        sync.RWMutex
+	snapshots []Snapshot
+}
+
+// NewMemorySnapshotStore returns a SnapshotStore which keeps all snapshots in memory.
+func NewMemorySnapshotStore() SnapshotStore {
+	return &memorySnapshotStore{}
+}
+
+func (m *memorySnapshotStore) Add(ctx context.Context, s Snapshot) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.add(s)
+	return nil
+}
+
+func (m *memorySnapshotStore) add(s Snapshot) {
+	i := sort.Search(len(m.snapshots), func(i int) bool {
+		return m.snapshots[i].Time.After(s.Time)
+	})
+	m.snapshots = append(m.snapshots, Snapshot{})
+	copy(m.snapshots[i+1:], m.snapshots[i:])
+	m.snapshots[i] = s
+}
+
+func (m *memorySnapshotStore) Latest(ctx context.Context) (*Snapshot, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if len(m.snapshots) == 0 {
+		return nil, ErrNoSnapshot
+	}
+
+	latest := m.snapshots[len(m.snapshots)-1]
+	return &latest, nil
+}
+
+func (m *memorySnapshotStore) Range(ctx context.Context, from, to time.Time) ([]Snapshot, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var snapshots []Snapshot
+	for _, s := range m.snapshots {
+		if !from.IsZero() && s.Time.Before(from) {
+			continue
+		}
+		if !to.IsZero() && !s.Time.Before(to) {
+			continue
+		}
+		snapshots = append(snapshots, s)
+	}
+
+	return snapshots, nil
+}
+
+type fileSnapshotStore struct {
+	memorySnapshotStore
+
+	path string
+}
+
+// NewFileSnapshotStore returns a SnapshotStore which appends snapshots as newline-delimited JSON to the file at path.
+// Snapshots already in the file are loaded into memory.
+func NewFileSnapshotStore(path string) (SnapshotStore, error) {
+	f := &fileSnapshotStore{path: path}
+	if err := ndjson.Read(path, f.add); err != nil {
+		return nil, fmt.Errorf("leaderboard: failed to read snapshots %q: %w", path, err)
+	}
+
+	return f, nil
+}
+
+func (f *fileSnapshotStore) Add(ctx context.Context, s Snapshot) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := ndjson.Append(f.path, s); err != nil {
+		return fmt.Errorf("leaderboard: failed to write snapshot to %q: %w", f.path, err)
+	}
+
+	f.add(s)
+	return nil
+}
@@ -0,0 +1,38 @@
+package leaderboard
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewFileSnapshotStore(t *testing.T) {
+	ctx := context.Background()
+	dir, err := ioutil.TempDir("", "go-wavy-leaderboard")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "snapshots.ndjson")
+
+	s, err := NewFileSnapshotStore(path)
+	require.NoError(t, err)
+	require.NoError(t, s.Add(ctx, Snapshot{Time: start.Add(time.Hour), Entries: []Entry{{Rank: 1, UserID: "1"}}}))
+	require.NoError(t, s.Add(ctx, Snapshot{Time: start}))
+
+	s, err = NewFileSnapshotStore(path)
+	require.NoError(t, err)
+
+	latest, err := s.Latest(ctx)
+	require.NoError(t, err)
+	assert.True(t, start.Add(time.Hour).Equal(latest.Time))
+	assert.Equal(t, []Entry{{Rank: 1, UserID: "1"}}, latest.Entries)
+
+	snapshots, err := s.Range(ctx, time.Time{}, start.Add(time.Hour))
+	require.NoError(t, err)
+	assert.Len(t, snapshots, 1)
+}
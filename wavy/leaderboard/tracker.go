@@ -0,0 +1,96 @@
+package leaderboard
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/OGKevin/go-wavy/internal/poll"
+	"github.com/OGKevin/go-wavy/wavy"
+	"github.com/hashicorp/go-hclog"
+)
+
+// Tracker samples the leaderboard and reports how it changed.
+type Tracker struct {
+	c      wavy.Client
+	store  SnapshotStore
+	logger hclog.Logger
+	now    func() time.Time
+}
+
+// NewTracker returns a Tracker which keeps its snapshots in store.
+// When store is nil, snapshots are kept in memory.
+func NewTracker(c wavy.Client, store SnapshotStore, logger hclog.Logger) *Tracker {
+	if store == nil {
+		store = NewMemorySnapshotStore()
+	}
+	if logger == nil {
+		logger = hclog.NewNullLogger()
+	}
+
+	return &Tracker{
+		c:      c,
+		store:  store,
+		logger: logger.Named("leaderboard-tracker"),
+		now:    time.Now,
+	}
+}
+
+// Sample fetches and stores the current leaderboard.
+// The returned Diff compares it with the previously stored snapshot, and is nil for the very first sample.
+func (t *Tracker) Sample(ctx context.Context) (*Snapshot, *Diff, error) {
+	t.logger.Trace("sampling leaderboard")
+	defer t.logger.Trace("finished sampling leaderboard")
+
+	prev, err := t.store.Latest(ctx)
+	if err != nil && !errors.Is(err, ErrNoSnapshot) {
+		return nil, nil, fmt.Errorf("%s: failed to load previous snapshot: %w", t.logger.Name(), err)
+	}
+
+	res, err := t.c.MetricsService().GetUserListensLeaderboard(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: failed to fetch leaderboard: %w", t.logger.Name(), err)
+	}
+
+	cur := NewSnapshot(t.now(), res)
+	if err := t.store.Add(ctx, cur); err != nil {
+		return nil, nil, fmt.Errorf("%s: failed to store snapshot: %w", t.logger.Name(), err)
+	}
+
+	if prev == nil {
+		return &cur, nil, nil
+	}
+
+	d := Compare(*prev, cur)
+	return &cur, &d, nil
+}
+
+// Run samples the leaderboard every interval until ctx is done, calling fn with every Diff.
+// Sampling failures are logged and do not stop the tracker.
+func (t *Tracker) Run(ctx context.Context, interval time.Duration, fn func(Diff)) {
+	poll.Every(ctx, interval, func(ctx context.Context) {
+		_, d, err := t.Sample(ctx)
+		if err != nil {
+			t.logger.Error("failed to sample leaderboard", "error", err)
+		}
+		if d != nil && fn != nil {
+			fn(*d)
+		}
+	})
+}
+
+// Since compares the oldest snapshot taken at or after from with the latest snapshot,
+// e.g. to summarize the changes of the last day. ErrNoSnapshot is returned when there are no snapshots since from.
+func (t *Tracker) Since(ctx context.Context, from time.Time) (*Diff, error) {
+	snapshots, err := t.store.Range(ctx, from, time.Time{})
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to load snapshots: %w", t.logger.Name(), err)
+	}
+	if len(snapshots) == 0 {
+		return nil, ErrNoSnapshot
+	}
+
+	d := Compare(snapshots[0], snapshots[len(snapshots)-1])
+	return &d, nil
+}
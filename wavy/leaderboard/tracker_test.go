@@ -0,0 +1,64 @@
+package leaderboard
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/OGKevin/go-wavy/wavy"
+	"github.com/OGKevin/go-wavy/wavy/wavytest"
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTracker_Sample(t *testing.T) {
+	ctx := context.Background()
+	c := wavytest.NewClient()
+	tracker := NewTracker(c, nil, hclog.NewNullLogger())
+
+	now := start
+	tracker.now = func() time.Time { return now }
+
+	c.Leaderboard = []wavy.LeaderboardEntry{{Count: 10, Username: "a", UserID: "1"}}
+	_, d, err := tracker.Sample(ctx)
+	require.NoError(t, err)
+	assert.Nil(t, d)
+
+	now = now.Add(time.Hour)
+	c.Leaderboard = []wavy.LeaderboardEntry{
+		{Count: 30, Username: "b", UserID: "2"},
+		{Count: 20, Username: "a", UserID: "1"},
+	}
+	s, d, err := tracker.Sample(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, now, s.Time)
+	require.NotNil(t, d)
+	assert.Len(t, d.Events, 2)
+	assert.Equal(t, []Velocity{{UserID: "1", Username: "a", Listens: 10, Over: time.Hour, ListensPerHour: 10}}, d.Velocities)
+
+	now = now.Add(time.Hour)
+	c.Leaderboard = []wavy.LeaderboardEntry{{Count: 40, Username: "b", UserID: "2"}}
+	_, _, err = tracker.Sample(ctx)
+	require.NoError(t, err)
+
+	d, err = tracker.Since(ctx, start.Add(30*time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, start.Add(time.Hour), d.Previous.Time)
+	assert.Equal(t, []Event{{Type: EventLeft, Time: now, UserID: "1", Username: "a", OldRank: 2}}, d.Events)
+
+	_, err = tracker.Since(ctx, now.Add(time.Minute))
+	assert.Equal(t, ErrNoSnapshot, err)
+}
+
+func TestTracker_Sample_error(t *testing.T) {
+	c := wavytest.NewClient()
+	c.Err = assert.AnError
+	tracker := NewTracker(c, nil, nil)
+
+	_, _, err := tracker.Sample(context.Background())
+	assert.Error(t, err)
+
+	_, err = tracker.store.Latest(context.Background())
+	assert.Equal(t, ErrNoSnapshot, err)
+}
@@ -0,0 +1,108 @@
+package listenbrainz
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+)
+
+const (
+	// DefaultBaseURL points to the public ListenBrainz api.
+	DefaultBaseURL = "https://api.listenbrainz.org"
+	// MaxListensPerSubmission is the maximum amount of listens ListenBrainz accepts in a single import submission.
+	MaxListensPerSubmission = 1000
+)
+
+// Client
+// This interface marks the contract for submitting listens to a ListenBrainz compatible api.
+// You can implement this interface for usage in mock test.
+type Client interface {
+	// SubmitListens
+	// Submits the listens to /1/submit-listens.
+	SubmitListens(ctx context.Context, submission Submission) error
+}
+
+type client struct {
+	c       *http.Client
+	baseURL string
+	token   string
+	logger  hclog.Logger
+}
+
+// NewClient returns a Client for the ListenBrainz compatible api at baseURL, authenticating with the user token.
+// When httpClient is nil, http.DefaultClient is used. When baseURL is empty, DefaultBaseURL is used.
+func NewClient(httpClient *http.Client, logger hclog.Logger, baseURL, token string) Client {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	if logger == nil {
+		logger = hclog.NewNullLogger()
+	}
+
+	return &client{
+		c:       httpClient,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		token:   token,
+		logger:  logger.Named("listenbrainz"),
+	}
+}
+
+// ApiError defines the error object returned by the ListenBrainz api.
+type ApiError struct {
+	Code    int    `json:"code"`
+	Message string `json:"error"`
+	// RetryAfter is set from the X-RateLimit-Reset-In header when the request was rate limited.
+	RetryAfter time.Duration `json:"-"`
+}
+
+func (a *ApiError) Error() string {
+	return fmt.Sprintf("%d: %s", a.Code, a.Message)
+}
+
+// SubmitListens
+// Submits the listens to /1/submit-listens.
+func (c *client) SubmitListens(ctx context.Context, submission Submission) error {
+	c.logger.Trace("submitting listens", "type", submission.ListenType, "count", len(submission.Payload))
+	defer c.logger.Trace("finished submitting listens", "type", submission.ListenType)
+
+	body, err := json.Marshal(submission)
+	if err != nil {
+		return fmt.Errorf("%s: failed to encode submission: %w", c.logger.Name(), err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, c.baseURL+"/1/submit-listens", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("%s: failed to build request: %w", c.logger.Name(), err)
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Authorization", "Token "+c.token)
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := c.c.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s: failed to execute request: %w", c.logger.Name(), err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode > 399 {
+		apiErr := &ApiError{Code: res.StatusCode, Message: http.StatusText(res.StatusCode)}
+		// Compatible servers do not always answer with a json body, in which case the status text is kept.
+		_ = json.NewDecoder(res.Body).Decode(apiErr)
+		if resetIn, err := strconv.Atoi(res.Header.Get("X-RateLimit-Reset-In")); err == nil {
+			apiErr.RetryAfter = time.Duration(resetIn) * time.Second
+		}
+		return apiErr
+	}
+
+	return nil
+}
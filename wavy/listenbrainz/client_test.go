@@ -0,0 +1,78 @@
+package listenbrainz
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_client_SubmitListens(t *testing.T) {
+	var got Submission
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/1/submit-listens", r.URL.Path)
+		assert.Equal(t, "Token secret", r.Header.Get("Authorization"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+
+		w.Write([]byte(`{"status": "ok"}`))
+	}))
+	defer server.Close()
+
+	c := NewClient(server.Client(), hclog.NewNullLogger(), server.URL+"/", "secret")
+	err := c.SubmitListens(context.Background(), NewSubmission(ListenTypeSingle, FromItem(spotifyItem())))
+	require.NoError(t, err)
+
+	assert.Equal(t, ListenTypeSingle, got.ListenType)
+	assert.Equal(t, []Listen{FromItem(spotifyItem())}, got.Payload)
+}
+
+func Test_client_SubmitListens_error(t *testing.T) {
+	type args struct {
+		status int
+		header http.Header
+		body   string
+	}
+	tests := []struct {
+		name string
+		args args
+		want *ApiError
+	}{
+		{
+			name: "json error",
+			args: args{status: http.StatusBadRequest, body: `{"code": 400, "error": "JSON document may only contain listen_type and payload top level keys"}`},
+			want: &ApiError{Code: 400, Message: "JSON document may only contain listen_type and payload top level keys"},
+		},
+		{
+			name: "rate limited",
+			args: args{status: http.StatusTooManyRequests, header: http.Header{"X-Ratelimit-Reset-In": []string{"7"}}},
+			want: &ApiError{Code: 429, Message: "Too Many Requests", RetryAfter: 7 * time.Second},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				for k, v := range tt.args.header {
+					w.Header()[k] = v
+				}
+				w.WriteHeader(tt.args.status)
+				w.Write([]byte(tt.args.body))
+			}))
+			defer server.Close()
+
+			c := NewClient(server.Client(), nil, server.URL, "secret")
+			err := c.SubmitListens(context.Background(), NewSubmission(ListenTypeSingle, FromItem(spotifyItem())))
+
+			var apiErr *ApiError
+			require.True(t, errors.As(err, &apiErr))
+			assert.Equal(t, tt.want, apiErr)
+		})
+	}
+}
@@ -0,0 +1,172 @@
+// Package listenbrainz converts wavy.fm history into ListenBrainz listens and back, and submits them
+// to ListenBrainz compatible apis.
+// The payload format is described at: https://listenbrainz.readthedocs.io/en/latest/users/json.html
+package listenbrainz
+
+import (
+	"strings"
+	"time"
+
+	"github.com/OGKevin/go-wavy/wavy"
+)
+
+// SubmissionClient is reported as the submission_client of converted listens.
+const SubmissionClient = "go-wavy"
+
+// ListenType defines how ListenBrainz treats the listens of a submission.
+type ListenType string
+
+const (
+	ListenTypeSingle     ListenType = "single"
+	ListenTypeImport     ListenType = "import"
+	ListenTypePlayingNow ListenType = "playing_now"
+)
+
+// musicServices maps wavy sources onto the canonical music_service domains known by ListenBrainz.
+var musicServices = map[string]string{
+	"spotify": "spotify.com",
+}
+
+// Submission is the body of a POST to /1/submit-listens.
+type Submission struct {
+	ListenType ListenType `json:"listen_type"`
+	Payload    []Listen   `json:"payload"`
+}
+
+// NewSubmission wraps listens in a submission of the given type.
+func NewSubmission(listenType ListenType, listens ...Listen) Submission {
+	return Submission{
+		ListenType: listenType,
+		Payload:    listens,
+	}
+}
+
+type Listen struct {
+	// ListenedAt is the unix timestamp of the listen. It is left out for playing now submissions.
+	ListenedAt    int64         `json:"listened_at,omitempty"`
+	TrackMetadata TrackMetadata `json:"track_metadata"`
+}
+
+type TrackMetadata struct {
+	ArtistName     string         `json:"artist_name"`
+	TrackName      string         `json:"track_name"`
+	ReleaseName    string         `json:"release_name,omitempty"`
+	AdditionalInfo AdditionalInfo `json:"additional_info"`
+}
+
+type AdditionalInfo struct {
+	ArtistNames      []string `json:"artist_names,omitempty"`
+	MusicService     string   `json:"music_service,omitempty"`
+	MusicServiceName string   `json:"music_service_name,omitempty"`
+	OriginURL        string   `json:"origin_url,omitempty"`
+	SpotifyID        string   `json:"spotify_id,omitempty"`
+	SpotifyAlbumID   string   `json:"spotify_album_id,omitempty"`
+	SpotifyArtistIDs []string `json:"spotify_artist_ids,omitempty"`
+	SubmissionClient string   `json:"submission_client,omitempty"`
+}
+
+// FromItem converts a listen from the user's history.
+func FromItem(item wavy.Item) Listen {
+	l := FromCurrent(item.CurrentPlayingItem)
+	l.ListenedAt = item.Date.Unix()
+
+	return l
+}
+
+// FromCurrent converts the item a user is currently listening to. The returned listen has no timestamp,
+// which makes it suitable for a playing now submission.
+//
+// artist_name holds the full credit joined by ", ", and when there is more than one artist
+// they are also listed individually in artist_names.
+// Local tracks have no source urls, so only their names and music service are submitted.
+func FromCurrent(item wavy.CurrentPlayingItem) Listen {
+	names := make([]string, 0, len(item.Artists))
+	for _, artist := range item.Artists {
+		names = append(names, artist.Name)
+	}
+
+	info := AdditionalInfo{
+		SubmissionClient: SubmissionClient,
+	}
+	if len(names) > 1 {
+		info.ArtistNames = names
+	}
+
+	if service, ok := musicServices[item.Song.Source]; ok {
+		info.MusicService = service
+	} else {
+		info.MusicServiceName = item.Song.Source
+	}
+
+	if !item.Local {
+		info.OriginURL = item.Song.SourceURL
+	}
+
+	if !item.Local && item.Song.Source == "spotify" {
+		info.SpotifyID = item.Song.SourceURL
+		info.SpotifyAlbumID = item.Album.SourceURL
+
+		for _, artist := range item.Artists {
+			if artist.SourceURL == "" {
+				info.SpotifyArtistIDs = nil
+				break
+			}
+			info.SpotifyArtistIDs = append(info.SpotifyArtistIDs, artist.SourceURL)
+		}
+	}
+
+	return Listen{
+		TrackMetadata: TrackMetadata{
+			ArtistName:     strings.Join(names, ", "),
+			TrackName:      item.Song.Name,
+			ReleaseName:    item.Album.Name,
+			AdditionalInfo: info,
+		},
+	}
+}
+
+// ToItem converts a listen into a history item. The PlayID and album art are not part of a listen
+// and are left empty. A listen without an origin url or spotify id is treated as a local track.
+func ToItem(l Listen) wavy.Item {
+	info := l.TrackMetadata.AdditionalInfo
+
+	source := info.MusicServiceName
+	for wavySource, service := range musicServices {
+		if service == info.MusicService {
+			source = wavySource
+		}
+	}
+
+	songURL := info.OriginURL
+	if songURL == "" {
+		songURL = info.SpotifyID
+	}
+
+	names := info.ArtistNames
+	if len(names) == 0 && l.TrackMetadata.ArtistName != "" {
+		names = []string{l.TrackMetadata.ArtistName}
+	}
+
+	artists := make([]wavy.Artists, 0, len(names))
+	for i, name := range names {
+		artist := wavy.Artists{Source: source, Name: name}
+		if len(info.SpotifyArtistIDs) == len(names) {
+			artist.SourceURL = info.SpotifyArtistIDs[i]
+		}
+		artists = append(artists, artist)
+	}
+
+	item := wavy.Item{
+		CurrentPlayingItem: wavy.CurrentPlayingItem{
+			Local:   songURL == "",
+			Song:    wavy.Song{Source: source, SourceURL: songURL, Name: l.TrackMetadata.TrackName},
+			Album:   wavy.Album{Source: source, SourceURL: info.SpotifyAlbumID, Name: l.TrackMetadata.ReleaseName},
+			Artists: artists,
+		},
+	}
+	if l.ListenedAt != 0 {
+		item.Date = time.Unix(l.ListenedAt, 0).UTC()
+	}
+
+	return item
+}
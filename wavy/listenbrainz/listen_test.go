@@ -0,0 +1,168 @@
+package listenbrainz
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/OGKevin/go-wavy/wavy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var playedAt = time.Date(2021, 3, 1, 12, 30, 0, 0, time.UTC)
+
+func spotifyItem() wavy.Item {
+	return wavy.Item{
+		CurrentPlayingItem: wavy.CurrentPlayingItem{
+			Song:  wavy.Song{Source: "spotify", SourceURL: "https://open.spotify.com/track/4uLU6hMCjMI75M1A2tKUQC", Name: "Never Gonna Give You Up"},
+			Album: wavy.Album{Source: "spotify", SourceURL: "https://open.spotify.com/album/6XhjNHCyCDyyGJRM5mg40G", Name: "Whenever You Need Somebody"},
+			Artists: []wavy.Artists{
+				{Source: "spotify", SourceURL: "https://open.spotify.com/artist/0gxyHStUsqpMadRV0Di1Qt", Name: "Rick Astley"},
+			},
+		},
+		Date: playedAt,
+	}
+}
+
+func multiArtistItem() wavy.Item {
+	item := spotifyItem()
+	item.Song.Name = "Collab"
+	item.Artists = append(item.Artists, wavy.Artists{Source: "spotify", SourceURL: "https://open.spotify.com/artist/1dfeR4HaWDbWqFHLkxsg1d", Name: "Queen"})
+	return item
+}
+
+func localItem() wavy.Item {
+	return wavy.Item{
+		CurrentPlayingItem: wavy.CurrentPlayingItem{
+			Local:   true,
+			Song:    wavy.Song{Source: "spotify", Name: "Demo Tape"},
+			Album:   wavy.Album{Source: "spotify", Name: "Bedroom Recordings"},
+			Artists: []wavy.Artists{{Source: "spotify", Name: "Nobody"}},
+		},
+		Date: playedAt,
+	}
+}
+
+func TestFromItem(t *testing.T) {
+	type args struct {
+		item wavy.Item
+	}
+	tests := []struct {
+		name string
+		args args
+		want Listen
+	}{
+		{
+			name: "spotify",
+			args: args{item: spotifyItem()},
+			want: Listen{
+				ListenedAt: playedAt.Unix(),
+				TrackMetadata: TrackMetadata{
+					ArtistName:  "Rick Astley",
+					TrackName:   "Never Gonna Give You Up",
+					ReleaseName: "Whenever You Need Somebody",
+					AdditionalInfo: AdditionalInfo{
+						MusicService:     "spotify.com",
+						OriginURL:        "https://open.spotify.com/track/4uLU6hMCjMI75M1A2tKUQC",
+						SpotifyID:        "https://open.spotify.com/track/4uLU6hMCjMI75M1A2tKUQC",
+						SpotifyAlbumID:   "https://open.spotify.com/album/6XhjNHCyCDyyGJRM5mg40G",
+						SpotifyArtistIDs: []string{"https://open.spotify.com/artist/0gxyHStUsqpMadRV0Di1Qt"},
+						SubmissionClient: SubmissionClient,
+					},
+				},
+			},
+		},
+		{
+			name: "multiple artists",
+			args: args{item: multiArtistItem()},
+			want: Listen{
+				ListenedAt: playedAt.Unix(),
+				TrackMetadata: TrackMetadata{
+					ArtistName:  "Rick Astley, Queen",
+					TrackName:   "Collab",
+					ReleaseName: "Whenever You Need Somebody",
+					AdditionalInfo: AdditionalInfo{
+						ArtistNames:    []string{"Rick Astley", "Queen"},
+						MusicService:   "spotify.com",
+						OriginURL:      "https://open.spotify.com/track/4uLU6hMCjMI75M1A2tKUQC",
+						SpotifyID:      "https://open.spotify.com/track/4uLU6hMCjMI75M1A2tKUQC",
+						SpotifyAlbumID: "https://open.spotify.com/album/6XhjNHCyCDyyGJRM5mg40G",
+						SpotifyArtistIDs: []string{
+							"https://open.spotify.com/artist/0gxyHStUsqpMadRV0Di1Qt",
+							"https://open.spotify.com/artist/1dfeR4HaWDbWqFHLkxsg1d",
+						},
+						SubmissionClient: SubmissionClient,
+					},
+				},
+			},
+		},
+		{
+			name: "local",
+			args: args{item: localItem()},
+			want: Listen{
+				ListenedAt: playedAt.Unix(),
+				TrackMetadata: TrackMetadata{
+					ArtistName:  "Nobody",
+					TrackName:   "Demo Tape",
+					ReleaseName: "Bedroom Recordings",
+					AdditionalInfo: AdditionalInfo{
+						MusicService:     "spotify.com",
+						SubmissionClient: SubmissionClient,
+					},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, FromItem(tt.args.item))
+		})
+	}
+}
+
+func TestFromCurrent(t *testing.T) {
+	got := FromCurrent(spotifyItem().CurrentPlayingItem)
+	assert.Zero(t, got.ListenedAt)
+
+	raw, err := json.Marshal(NewSubmission(ListenTypePlayingNow, got))
+	require.NoError(t, err)
+	assert.NotContains(t, string(raw), "listened_at")
+	assert.Contains(t, string(raw), `"listen_type":"playing_now"`)
+}
+
+func TestToItem_roundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		item wavy.Item
+	}{
+		{name: "spotify", item: spotifyItem()},
+		{name: "multiple artists", item: multiArtistItem()},
+		{name: "local", item: localItem()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			raw, err := json.Marshal(FromItem(tt.item))
+			require.NoError(t, err)
+
+			var listen Listen
+			require.NoError(t, json.Unmarshal(raw, &listen))
+
+			assert.Equal(t, tt.item, ToItem(listen))
+		})
+	}
+}
+
+func TestToItem(t *testing.T) {
+	// A listen submitted by another client, without any of the fields written by FromItem.
+	raw := `{"listened_at": 1614601800, "track_metadata": {"artist_name": "Daft Punk", "track_name": "One More Time", "additional_info": {"music_service_name": "bandcamp", "origin_url": "https://daftpunk.bandcamp.com/track/one-more-time"}}}`
+
+	var listen Listen
+	require.NoError(t, json.Unmarshal([]byte(raw), &listen))
+
+	got := ToItem(listen)
+	assert.False(t, got.Local)
+	assert.Equal(t, wavy.Song{Source: "bandcamp", SourceURL: "https://daftpunk.bandcamp.com/track/one-more-time", Name: "One More Time"}, got.Song)
+	assert.Equal(t, []wavy.Artists{{Source: "bandcamp", Name: "Daft Punk"}}, got.Artists)
+	assert.Equal(t, playedAt, got.Date)
+}
@@ -0,0 +1,111 @@
+package wavy
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/hashicorp/go-hclog"
+)
+
+// Logger is the logging interface of the SDK. Args are alternating keys and values, as with hclog and slog.
+// Use NewHCLogLogger, NewSlogLogger or NopLogger, or implement it to adapt another logging library such as zap,
+// which has no adapter so the SDK does not depend on it.
+type Logger interface {
+	Trace(msg string, args ...interface{})
+	Debug(msg string, args ...interface{})
+	Info(msg string, args ...interface{})
+	Warn(msg string, args ...interface{})
+	Error(msg string, args ...interface{})
+
+	// With returns a Logger which adds args to every line.
+	With(args ...interface{}) Logger
+	// Named returns a Logger for a sub system, with name appended to the name of the Logger.
+	Named(name string) Logger
+	// Name returns the full name of the Logger, it prefixes the errors of the SDK.
+	Name() string
+}
+
+type hclogLogger struct {
+	l hclog.Logger
+}
+
+// NewHCLogLogger adapts an hclog.Logger.
+func NewHCLogLogger(l hclog.Logger) Logger {
+	return &hclogLogger{l: l}
+}
+
+func (h *hclogLogger) Trace(msg string, args ...interface{}) { h.l.Trace(msg, args...) }
+func (h *hclogLogger) Debug(msg string, args ...interface{}) { h.l.Debug(msg, args...) }
+func (h *hclogLogger) Info(msg string, args ...interface{})  { h.l.Info(msg, args...) }
+func (h *hclogLogger) Warn(msg string, args ...interface{})  { h.l.Warn(msg, args...) }
+func (h *hclogLogger) Error(msg string, args ...interface{}) { h.l.Error(msg, args...) }
+
+func (h *hclogLogger) With(args ...interface{}) Logger { return &hclogLogger{l: h.l.With(args...)} }
+func (h *hclogLogger) Named(name string) Logger        { return &hclogLogger{l: h.l.Named(name)} }
+func (h *hclogLogger) Name() string                    { return h.l.Name() }
+
+// LevelTrace is the slog level Trace lines are logged at by the Logger of NewSlogLogger.
+const LevelTrace = slog.LevelDebug - 4
+
+type slogLogger struct {
+	l    *slog.Logger
+	name string
+}
+
+// NewSlogLogger adapts a slog.Logger. Trace lines are logged at LevelTrace, and the name of the Logger is added
+// to every line as the "logger" attribute.
+func NewSlogLogger(l *slog.Logger) Logger {
+	return &slogLogger{l: l}
+}
+
+func (s *slogLogger) log(level slog.Level, msg string, args []interface{}) {
+	if s.name != "" {
+		args = append([]interface{}{"logger", s.name}, args...)
+	}
+	s.l.Log(context.Background(), level, msg, args...)
+}
+
+func (s *slogLogger) Trace(msg string, args ...interface{}) { s.log(LevelTrace, msg, args) }
+func (s *slogLogger) Debug(msg string, args ...interface{}) { s.log(slog.LevelDebug, msg, args) }
+func (s *slogLogger) Info(msg string, args ...interface{})  { s.log(slog.LevelInfo, msg, args) }
+func (s *slogLogger) Warn(msg string, args ...interface{})  { s.log(slog.LevelWarn, msg, args) }
+func (s *slogLogger) Error(msg string, args ...interface{}) { s.log(slog.LevelError, msg, args) }
+
+func (s *slogLogger) With(args ...interface{}) Logger {
+	return &slogLogger{l: s.l.With(args...), name: s.name}
+}
+
+func (s *slogLogger) Named(name string) Logger {
+	if s.name != "" {
+		name = s.name + "." + name
+	}
+	return &slogLogger{l: s.l, name: name}
+}
+
+func (s *slogLogger) Name() string { return s.name }
+
+type nopLogger struct {
+	name string
+}
+
+// NopLogger returns a Logger which discards every line.
+func NopLogger() Logger {
+	return nopLogger{}
+}
+
+func (nopLogger) Trace(string, ...interface{}) {}
+func (nopLogger) Debug(string, ...interface{}) {}
+func (nopLogger) Info(string, ...interface{})  {}
+func (nopLogger) Warn(string, ...interface{})  {}
+func (nopLogger) Error(string, ...interface{}) {}
+
+func (n nopLogger) With(...interface{}) Logger { return n }
+
+func (n nopLogger) Named(name string) Logger {
+	if n.name != "" {
+		name = n.name + "." + name
+	}
+	return nopLogger{name: name}
+}
+
+func (n nopLogger) Name() string { return n.name }
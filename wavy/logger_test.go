@@ -0,0 +1,92 @@
+package wavy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// jsonLines decodes the lines of a slog JSON handler.
+func jsonLines(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	var lines []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var fields map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(line), &fields))
+		lines = append(lines, fields)
+	}
+	return lines
+}
+
+func TestNewSlogLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewSlogLogger(slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: LevelTrace})))
+
+	named := logger.Named("go-wavy").Named("user-service")
+	assert.Equal(t, "go-wavy.user-service", named.Name())
+
+	named.With("endpoint", "user profile").Trace("fetching", "attempt", 1)
+	logger.Warn("plain")
+
+	lines := jsonLines(t, &buf)
+	require.Len(t, lines, 2)
+	assert.Equal(t, "DEBUG-4", lines[0]["level"])
+	assert.Equal(t, "go-wavy.user-service", lines[0]["logger"])
+	assert.Equal(t, "user profile", lines[0]["endpoint"])
+	assert.Equal(t, 1.0, lines[0]["attempt"])
+	assert.Equal(t, "WARN", lines[1]["level"])
+	assert.NotContains(t, lines[1], "logger")
+}
+
+func TestNewHCLogLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewHCLogLogger(hclog.New(&hclog.LoggerOptions{Name: "app", Output: &buf, Level: hclog.Trace}))
+
+	named := logger.Named("go-wavy")
+	assert.Equal(t, "app.go-wavy", named.Name())
+
+	named.With("endpoint", "total users").Debug("fetched", "status", 200)
+	assert.Contains(t, buf.String(), "[DEBUG] app.go-wavy: fetched: endpoint=\"total users\" status=200")
+}
+
+func TestNopLogger(t *testing.T) {
+	logger := NopLogger().Named("go-wavy").With("a", 1).Named("metrics-service")
+	assert.Equal(t, "go-wavy.metrics-service", logger.Name())
+	logger.Error("discarded")
+}
+
+func TestLogFields(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewSlogLogger(slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})))
+
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{}`))
+	}))
+	c.logger = logger.Named("go-wavy")
+
+	_, err := newUserService(c, c.logger).GetProfile(context.Background(), UserURI{Username: "OGKevin"})
+	require.NoError(t, err)
+
+	lines := jsonLines(t, &buf)
+	require.Len(t, lines, 2)
+	for _, line := range lines {
+		assert.Equal(t, "go-wavy.user-service", line["logger"])
+		assert.Equal(t, "user profile", line["endpoint"])
+		assert.Equal(t, "wavyfm:user:username:OGKevin", line["user_uri"])
+		assert.Equal(t, 200.0, line["status"])
+		assert.Contains(t, line, "duration")
+	}
+	assert.Equal(t, "request attempt finished", lines[0]["msg"])
+	assert.Equal(t, 1.0, lines[0]["attempt"])
+	assert.Equal(t, "finished fetching user profile", lines[1]["msg"])
+}
@@ -1,36 +1,27 @@
 package wavy
 
 import (
+	"bytes"
 	"context"
-	"encoding/json"
 	"fmt"
-	"io/ioutil"
+	"net/http"
 	"strconv"
-
-	"github.com/hashicorp/go-hclog"
+	"time"
 )
 
 // MetricsService
 // Reference for accessing global wavy.fm metrics.
 // https://wavy.fm/developers/docs/v1beta/metrics
 type MetricsService interface {
-	// GetTotalListens
-	// Retrieves the total amount of listens recorded on wavy.fm. Note that this value is cached for a few seconds.
-	GetTotalListens(ctx context.Context) (int, error)
-	// GetTotalUsers
-	// Retrieves the total amount of registered users on wavy.fm. Note that this value is cached for a few seconds.
-	GetTotalUsers(ctx context.Context) (int, error)
-	// GetUserListensLeaderboard
-	// Retrieves the leaderboard of the top 10 users by listen count. Note that this endpoint is cached for a few minutes.
-	GetUserListensLeaderboard(ctx context.Context) (UserListensLeaderboardResponse, error)
+	metricsServiceOperations
 }
 
 type metricsService struct {
 	c      *client
-	logger hclog.Logger
+	logger Logger
 }
 
-func newMetricsService(c *client, logger hclog.Logger) MetricsService {
+func newMetricsService(c *client, logger Logger) MetricsService {
 	logger = logger.Named("metrics-service")
 
 	return &metricsService{
@@ -41,74 +32,104 @@ func newMetricsService(c *client, logger hclog.Logger) MetricsService {
 
 // GetTotalListens
 // Retrieves the total amount of listens recorded on wavy.fm. Note that this value is cached for a few seconds.
-func (m *metricsService) GetTotalListens(ctx context.Context) (int, error) {
-	m.logger.Trace("fetching total listens")
-	defer m.logger.Trace("finished fetching total listens")
-
-	res, err := m.c.get("/metrics/total-listens")
-	if err != nil {
-		return 0, fmt.Errorf("%s: failed to request total listens: %w", m.logger.Name(), err)
-	}
-
-	rawBody, err := ioutil.ReadAll(res.Body)
-	if err != nil {
-		return 0, fmt.Errorf("%s: failed to parse response body: %w", m.logger.Name(), err)
-	}
-
-	totalListens, err := strconv.Atoi(string(rawBody))
+func (m *metricsService) GetTotalListens(ctx context.Context, opts ...CallOption) (*GetTotalListensResponse, error) {
+	totalListens, res, err := getTotalListens(ctx, m.c, m.logger, m.c.callOptions(opts))
 	if err != nil {
-		return 0, fmt.Errorf("%s: failed to parse response body to int: %w", m.logger.Name(), err)
+		return nil, err
 	}
 
-	return totalListens, nil
+	return &GetTotalListensResponse{
+		TotalListens: int64(*totalListens),
+		Metadata:     newMetricsMetadata(res),
+	}, nil
 }
 
 // GetTotalUsers
 // Retrieves the total amount of registered users on wavy.fm. Note that this value is cached for a few seconds.
-func (m *metricsService) GetTotalUsers(ctx context.Context) (int, error) {
-	m.logger.Trace("fetching total users")
-	defer m.logger.Trace("finished fetching total users")
-
-	res, err := m.c.get("/metrics/total-users")
+func (m *metricsService) GetTotalUsers(ctx context.Context, opts ...CallOption) (*GetTotalUsersResponse, error) {
+	totalUsers, res, err := getTotalUsers(ctx, m.c, m.logger, m.c.callOptions(opts))
 	if err != nil {
-		return 0, fmt.Errorf("%s: failed to request total users: %w", m.logger.Name(), err)
+		return nil, err
 	}
 
-	rawBody, err := ioutil.ReadAll(res.Body)
+	return &GetTotalUsersResponse{
+		TotalUsers: int64(*totalUsers),
+		Metadata:   newMetricsMetadata(res),
+	}, nil
+}
+
+// GetUserListensLeaderboard
+// Retrieves the leaderboard of the top 10 users by listen count. Note that this endpoint is cached for a few minutes.
+func (m *metricsService) GetUserListensLeaderboard(ctx context.Context, opts ...CallOption) (*UserListensLeaderboardResponse, error) {
+	entries, res, err := getUserListensLeaderboard(ctx, m.c, m.logger, m.c.callOptions(opts))
 	if err != nil {
-		return 0, fmt.Errorf("%s: failed to parse response body: %w", m.logger.Name(), err)
+		return nil, err
 	}
 
-	totalUsers, err := strconv.Atoi(string(rawBody))
-	if err != nil {
-		return 0, fmt.Errorf("%s: failed to parse response body to int: %w", m.logger.Name(), err)
+	for i := range *entries {
+		if (*entries)[i].Rank == 0 {
+			(*entries)[i].Rank = i + 1
+		}
 	}
 
-	return totalUsers, nil
+	return &UserListensLeaderboardResponse{
+		Entries:  *entries,
+		Metadata: newMetricsMetadata(res),
+	}, nil
 }
 
-// GetUserListensLeaderboard
-// Retrieves the leaderboard of the top 10 users by listen count. Note that this endpoint is cached for a few minutes.
-func (m *metricsService) GetUserListensLeaderboard(ctx context.Context) (UserListensLeaderboardResponse, error) {
-	m.logger.Trace("fetching user listen leaderboards")
-	defer m.logger.Trace("finished fetching user listen leaderboards")
+// count is a metrics body holding a single number, see parseCount.
+type count int64
 
-	res, err := m.c.get("/metrics/user-listens-leaderboard")
+func (c *count) UnmarshalJSON(data []byte) error {
+	n, err := parseCount(data)
 	if err != nil {
-		return nil, fmt.Errorf("%s: failed to request total users: %w", m.logger.Name(), err)
+		return err
 	}
+	*c = count(n)
+	return nil
+}
 
-	var leaderBoard UserListensLeaderboardResponse
-	err = json.NewDecoder(res.Body).Decode(&leaderBoard)
+// parseCount parses a metrics body holding a single plain number, e.g. 42.
+func parseCount(body []byte) (int64, error) {
+	count, err := strconv.ParseInt(string(bytes.TrimSpace(body)), 10, 64)
 	if err != nil {
-		return nil, fmt.Errorf("%s: failed to parse response body to struct: %w", m.logger.Name(), err)
+		return 0, fmt.Errorf("failed to parse count %q: %w", body, err)
 	}
+	return count, nil
+}
+
+// MetricsMetadata holds information about the response of a metrics endpoint.
+type MetricsMetadata struct {
+	// FetchedAt is the time the response was received.
+	FetchedAt time.Time
+	// CacheAge is how long the value was cached by wavy.fm before it was served, taken from the Age header.
+	// It is 0 when wavy.fm did not report it.
+	CacheAge time.Duration
+}
+
+func newMetricsMetadata(res *http.Response) MetricsMetadata {
+	meta := MetricsMetadata{FetchedAt: time.Now()}
+
+	if age, err := strconv.ParseInt(res.Header.Get("Age"), 10, 64); err == nil && age > 0 {
+		meta.CacheAge = time.Duration(age) * time.Second
+	}
+
+	return meta
+}
+
+type GetTotalListensResponse struct {
+	TotalListens int64
+	Metadata     MetricsMetadata
+}
 
-	return leaderBoard, nil
+type GetTotalUsersResponse struct {
+	TotalUsers int64
+	Metadata   MetricsMetadata
 }
 
-type UserListensLeaderboardResponse []struct {
-	Count    int    `json:"count"`
-	Username string `json:"username"`
-	UserID   string `json:"user_id"`
+type UserListensLeaderboardResponse struct {
+	// Entries holds the users ordered by rank.
+	Entries  []LeaderboardEntry
+	Metadata MetricsMetadata
 }
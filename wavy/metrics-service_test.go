@@ -2,11 +2,10 @@ package wavy
 
 import (
 	"context"
-	"os"
+	"net/http"
 	"testing"
 	"time"
 
-	"github.com/hashicorp/go-hclog"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -14,7 +13,7 @@ func Test_metricsService_GetTotalListens(t *testing.T) {
 	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
 	defer cancel()
 
-	c := NewClient(ctx, hclog.NewNullLogger(), os.Getenv("CLIENT_ID"), os.Getenv("CLIENT_SECRET"))
+	c := newRecordedClient(t, ctx)
 
 	type args struct {
 		ctx context.Context
@@ -40,7 +39,8 @@ func Test_metricsService_GetTotalListens(t *testing.T) {
 				return
 			}
 
-			assert.NotZero(t, got)
+			assert.NotZero(t, got.TotalListens)
+			assert.NotZero(t, got.Metadata.FetchedAt)
 		})
 	}
 }
@@ -49,7 +49,7 @@ func Test_metricsService_GetTotalUsers(t *testing.T) {
 	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
 	defer cancel()
 
-	c := NewClient(ctx, hclog.NewNullLogger(), os.Getenv("CLIENT_ID"), os.Getenv("CLIENT_SECRET"))
+	c := newRecordedClient(t, ctx)
 
 	type args struct {
 		ctx context.Context
@@ -75,7 +75,7 @@ func Test_metricsService_GetTotalUsers(t *testing.T) {
 				return
 			}
 
-			assert.NotZero(t, got)
+			assert.NotZero(t, got.TotalUsers)
 		})
 	}
 }
@@ -84,7 +84,7 @@ func Test_metricsService_GetUserListensLeaderboard(t *testing.T) {
 	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
 	defer cancel()
 
-	c := NewClient(ctx, hclog.NewNullLogger(), os.Getenv("CLIENT_ID"), os.Getenv("CLIENT_SECRET"))
+	c := newRecordedClient(t, ctx)
 
 	type args struct {
 		ctx context.Context
@@ -111,9 +111,68 @@ func Test_metricsService_GetUserListensLeaderboard(t *testing.T) {
 			}
 
 			assert.NotNil(t, got)
-			assert.NotZero(t, got[0].Count)
-			assert.NotZero(t, got[0].Username)
-			assert.NotZero(t, got[0].UserID)
+			assert.NotZero(t, got.Entries[0].Count)
+			assert.NotZero(t, got.Entries[0].Username)
+			assert.NotZero(t, got.Entries[0].UserID)
+			assert.Equal(t, 1, got.Entries[0].Rank)
 		})
 	}
 }
+
+func Test_parseCount(t *testing.T) {
+	type args struct {
+		body string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    int64
+		wantErr bool
+	}{
+		{
+			name: "plain text",
+			args: args{body: "1234567\n"},
+			want: 1234567,
+		},
+		{
+			name: "beyond 32 bit",
+			args: args{body: "4294967296"},
+			want: 4294967296,
+		},
+		{
+			name:    "json string",
+			args:    args{body: `"42"`},
+			wantErr: true,
+		},
+		{
+			name:    "json object",
+			args:    args{body: `{"count": 42}`},
+			wantErr: true,
+		},
+		{
+			name:    "not a number",
+			args:    args{body: "many"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseCount([]byte(tt.args.body))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parseCount() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_newMetricsMetadata(t *testing.T) {
+	got := newMetricsMetadata(&http.Response{Header: http.Header{"Age": []string{"12"}}})
+	assert.Equal(t, 12*time.Second, got.CacheAge)
+	assert.WithinDuration(t, time.Now(), got.FetchedAt, time.Minute)
+
+	got = newMetricsMetadata(&http.Response{Header: http.Header{}})
+	assert.Zero(t, got.CacheAge)
+}
@@ -0,0 +1,184 @@
+package wavy
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// SocialNetwork is a service a wavy.fm profile can link to.
+type SocialNetwork string
+
+const (
+	NetworkTwitter   SocialNetwork = "twitter"
+	NetworkInstagram SocialNetwork = "instagram"
+	NetworkSpotify   SocialNetwork = "spotify"
+	NetworkDiscord   SocialNetwork = "discord"
+)
+
+// socialNetwork describes the handles and profile urls of a SocialNetwork.
+type socialNetwork struct {
+	// handle matches valid handles.
+	handle *regexp.Regexp
+	// rule describes valid handles in errors.
+	rule string
+	// hosts serve the profile pages of the network, their urls are accepted as handle.
+	hosts []string
+	// prefixes are stripped from handles, such as @ or the path of profile urls.
+	prefixes []string
+	// url formats the canonical profile url of an escaped handle.
+	url string
+}
+
+var socialNetworks = map[SocialNetwork]socialNetwork{
+	NetworkTwitter: {
+		handle:   regexp.MustCompile(`^[A-Za-z0-9_]{1,15}$`),
+		rule:     "1 to 15 letters, digits or underscores",
+		hosts:    []string{"twitter.com", "www.twitter.com", "mobile.twitter.com", "x.com", "www.x.com"},
+		prefixes: []string{"@"},
+		url:      "https://twitter.com/%s",
+	},
+	NetworkInstagram: {
+		handle:   regexp.MustCompile(`^[A-Za-z0-9_](?:[A-Za-z0-9_]|\.[A-Za-z0-9_]){0,29}$`),
+		rule:     "1 to 30 letters, digits, underscores or single periods, not starting or ending with a period",
+		hosts:    []string{"instagram.com", "www.instagram.com"},
+		prefixes: []string{"@"},
+		url:      "https://www.instagram.com/%s/",
+	},
+	NetworkSpotify: {
+		handle:   regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`),
+		rule:     "1 to 64 letters, digits, periods, underscores or dashes",
+		hosts:    []string{"open.spotify.com"},
+		prefixes: []string{"spotify:user:", "user/"},
+		url:      "https://open.spotify.com/user/%s",
+	},
+	NetworkDiscord: {
+		handle:   regexp.MustCompile(`^[0-9]{17,20}$`),
+		rule:     "a numeric user id",
+		hosts:    []string{"discord.com", "discordapp.com"},
+		prefixes: []string{"users/"},
+		url:      "https://discord.com/users/%s",
+	},
+}
+
+// InvalidHandleError is returned for handles which are not valid on their network.
+type InvalidHandleError struct {
+	Network SocialNetwork
+	Handle  string
+	// Rule describes valid handles of the network.
+	Rule string
+}
+
+func (e *InvalidHandleError) Error() string {
+	return fmt.Sprintf("invalid %s handle %q: expected %s", e.Network, e.Handle, e.Rule)
+}
+
+// NormalizeHandle validates handle for network and returns it without decoration: a leading @,
+// spotify:user: uris and profile urls of the network are reduced to the handle, e.g. https://twitter.com/OGKevin
+// to OGKevin. Discord handles are user ids. Invalid handles fail with an *InvalidHandleError.
+func NormalizeHandle(network SocialNetwork, handle string) (string, error) {
+	n, ok := socialNetworks[network]
+	if !ok {
+		return "", fmt.Errorf("unknown social network %q", network)
+	}
+
+	normalized := strings.TrimSpace(handle)
+	if u, ok := n.profileURL(normalized); ok {
+		normalized = strings.Trim(u.Path, "/")
+	}
+	for _, prefix := range n.prefixes {
+		normalized = strings.TrimPrefix(normalized, prefix)
+	}
+
+	if !n.handle.MatchString(normalized) {
+		return "", &InvalidHandleError{Network: network, Handle: handle, Rule: n.rule}
+	}
+	return normalized, nil
+}
+
+// profileURL parses s as profile url of the network, with or without scheme.
+func (n socialNetwork) profileURL(s string) (*url.URL, bool) {
+	for _, candidate := range []string{s, "https://" + s} {
+		u, err := url.Parse(candidate)
+		if err != nil {
+			continue
+		}
+		for _, host := range n.hosts {
+			if strings.EqualFold(u.Host, host) {
+				return u, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// SocialURL returns the canonical profile url of handle on network, see NormalizeHandle.
+func SocialURL(network SocialNetwork, handle string) (string, error) {
+	normalized, err := NormalizeHandle(network, handle)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf(socialNetworks[network].url, url.PathEscape(normalized)), nil
+}
+
+// SocialLink is a link of a profile to an account on a social network.
+type SocialLink struct {
+	Network SocialNetwork
+	// Handle identifies the account, see NormalizeHandle.
+	Handle string
+	// DisplayName is the name of the account as known by wavy.fm, if any.
+	DisplayName string
+	// URL is the canonical profile url of the account.
+	URL string
+}
+
+// handles returns the handles of p keyed by network, empty when not linked.
+func (p Profile) handles() []SocialLink {
+	return []SocialLink{
+		{Network: NetworkTwitter, Handle: p.Twitter},
+		{Network: NetworkInstagram, Handle: p.Instagram},
+		{Network: NetworkSpotify, Handle: p.Spotify.ID, DisplayName: p.Spotify.DisplayName},
+		{Network: NetworkDiscord, Handle: p.Discord.ID, DisplayName: p.Discord.DisplayName},
+	}
+}
+
+// SocialLinks returns the accounts linked to p, in the order Twitter, Instagram, Spotify and Discord.
+// Links with invalid handles are left out, use NormalizeHandle to report them.
+func (p Profile) SocialLinks() []SocialLink {
+	var links []SocialLink
+	for _, link := range p.handles() {
+		if link.Handle == "" {
+			continue
+		}
+		handle, err := NormalizeHandle(link.Network, link.Handle)
+		if err != nil {
+			continue
+		}
+		link.Handle = handle
+		link.URL, _ = SocialURL(link.Network, handle)
+		links = append(links, link)
+	}
+	return links
+}
+
+// Integrations returns the networks p has linked an account of, valid or not.
+func (p Profile) Integrations() []SocialNetwork {
+	var networks []SocialNetwork
+	for _, link := range p.handles() {
+		if link.Handle != "" {
+			networks = append(networks, link.Network)
+		}
+	}
+	return networks
+}
+
+// Linked reports whether p has linked an account of network.
+func (p Profile) Linked(network SocialNetwork) bool {
+	for _, n := range p.Integrations() {
+		if n == network {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,100 @@
+package wavy
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeHandle(t *testing.T) {
+	tests := []struct {
+		network SocialNetwork
+		handle  string
+		want    string
+		wantErr bool
+	}{
+		{network: NetworkTwitter, handle: "OGKevin", want: "OGKevin"},
+		{network: NetworkTwitter, handle: " @OGKevin ", want: "OGKevin"},
+		{network: NetworkTwitter, handle: "https://twitter.com/OGKevin?s=20", want: "OGKevin"},
+		{network: NetworkTwitter, handle: "x.com/OGKevin/", want: "OGKevin"},
+		{network: NetworkTwitter, handle: "https://example.com/OGKevin", wantErr: true},
+		{network: NetworkTwitter, handle: "a_handle_which_is_too_long", wantErr: true},
+		{network: NetworkTwitter, handle: "OG Kevin", wantErr: true},
+		{network: NetworkInstagram, handle: "og.kevin", want: "og.kevin"},
+		{network: NetworkInstagram, handle: "https://www.instagram.com/og.kevin/", want: "og.kevin"},
+		{network: NetworkInstagram, handle: "og..kevin", wantErr: true},
+		{network: NetworkInstagram, handle: "ogkevin.", wantErr: true},
+		{network: NetworkSpotify, handle: "spotify:user:ogkevin", want: "ogkevin"},
+		{network: NetworkSpotify, handle: "https://open.spotify.com/user/ogkevin", want: "ogkevin"},
+		{network: NetworkSpotify, handle: "og/kevin", wantErr: true},
+		{network: NetworkDiscord, handle: "123456789012345678", want: "123456789012345678"},
+		{network: NetworkDiscord, handle: "https://discord.com/users/123456789012345678", want: "123456789012345678"},
+		{network: NetworkDiscord, handle: "OGKevin#1234", wantErr: true},
+		{network: "myspace", handle: "OGKevin", wantErr: true},
+		{network: NetworkTwitter, handle: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.network)+"/"+tt.handle, func(t *testing.T) {
+			got, err := NormalizeHandle(tt.network, tt.handle)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestNormalizeHandle_error(t *testing.T) {
+	_, err := NormalizeHandle(NetworkDiscord, "OGKevin")
+
+	var handleErr *InvalidHandleError
+	require.True(t, errors.As(err, &handleErr))
+	assert.Equal(t, NetworkDiscord, handleErr.Network)
+	assert.Equal(t, `invalid discord handle "OGKevin": expected a numeric user id`, err.Error())
+}
+
+func TestSocialURL(t *testing.T) {
+	tests := []struct {
+		network SocialNetwork
+		handle  string
+		want    string
+	}{
+		{network: NetworkTwitter, handle: "@OGKevin", want: "https://twitter.com/OGKevin"},
+		{network: NetworkInstagram, handle: "og.kevin", want: "https://www.instagram.com/og.kevin/"},
+		{network: NetworkSpotify, handle: "spotify:user:ogkevin", want: "https://open.spotify.com/user/ogkevin"},
+		{network: NetworkDiscord, handle: "123456789012345678", want: "https://discord.com/users/123456789012345678"},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.network), func(t *testing.T) {
+			got, err := SocialURL(tt.network, tt.handle)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	_, err := SocialURL(NetworkTwitter, "not a handle")
+	assert.Error(t, err)
+}
+
+func TestProfile_SocialLinks(t *testing.T) {
+	p := Profile{
+		Twitter:   "@OGKevin",
+		Instagram: "not..valid",
+		Spotify:   Spotify{ID: "ogkevin", DisplayName: "Kevin"},
+	}
+
+	assert.Equal(t, []SocialLink{
+		{Network: NetworkTwitter, Handle: "OGKevin", URL: "https://twitter.com/OGKevin"},
+		{Network: NetworkSpotify, Handle: "ogkevin", DisplayName: "Kevin", URL: "https://open.spotify.com/user/ogkevin"},
+	}, p.SocialLinks())
+	assert.Equal(t, []SocialNetwork{NetworkTwitter, NetworkInstagram, NetworkSpotify}, p.Integrations())
+	assert.True(t, p.Linked(NetworkInstagram))
+	assert.False(t, p.Linked(NetworkDiscord))
+
+	assert.Empty(t, Profile{}.SocialLinks())
+	assert.Empty(t, Profile{}.Integrations())
+}
@@ -0,0 +1,158 @@
+package recorder
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Redacted replaces scrubbed values in cassettes.
+const Redacted = "[REDACTED]"
+
+// Cassette is a recorded list of interactions, stored as JSON.
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Interaction is a single request and the response it received.
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+type Request struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+}
+
+type Response struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body"`
+}
+
+// LoadCassette reads the cassette at path.
+func LoadCassette(path string) (*Cassette, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("recorder: failed to read cassette: %w", err)
+	}
+
+	var c Cassette
+	if err := json.Unmarshal(b, &c); err != nil {
+		return nil, fmt.Errorf("recorder: failed to parse cassette %s: %w", path, err)
+	}
+	return &c, nil
+}
+
+// Save writes c to path, creating its directory.
+func (c *Cassette) Save(path string) error {
+	b, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("recorder: failed to encode cassette: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("recorder: failed to create cassette dir: %w", err)
+	}
+	if err := ioutil.WriteFile(path, append(b, '\n'), 0644); err != nil {
+		return fmt.Errorf("recorder: failed to write cassette: %w", err)
+	}
+	return nil
+}
+
+var (
+	// scrubbedHeaders are removed from recorded requests and responses.
+	scrubbedHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie", "X-Api-Key"}
+	// scrubbedFields are query parameters, form fields and JSON fields whose values are replaced by Redacted.
+	scrubbedFields = map[string]bool{
+		"access_token":  true,
+		"refresh_token": true,
+		"id_token":      true,
+		"client_id":     true,
+		"client_secret": true,
+		"token":         true,
+		"api_key":       true,
+	}
+	// scrubbedGrantFields are query parameters and form fields of OAuth redirects and token requests whose values
+	// are replaced by Redacted. JSON fields of the same name, such as the code of api errors, are kept.
+	scrubbedGrantFields = map[string]bool{
+		"code":          true,
+		"code_verifier": true,
+	}
+)
+
+// Scrub removes credentials from i: authorization headers and cookies, and tokens and client secrets in
+// query strings, form bodies and JSON bodies. It is applied to every recorded interaction.
+func Scrub(i *Interaction) {
+	for _, key := range scrubbedHeaders {
+		i.Request.Header.Del(key)
+		i.Response.Header.Del(key)
+	}
+
+	if u, err := url.Parse(i.Request.URL); err == nil && u.RawQuery != "" {
+		u.RawQuery = scrubValues(u.Query()).Encode()
+		i.Request.URL = u.String()
+	}
+
+	i.Request.Body = scrubBody(i.Request.Header.Get("Content-Type"), i.Request.Body)
+	i.Response.Body = scrubBody(i.Response.Header.Get("Content-Type"), i.Response.Body)
+}
+
+func scrubBody(contentType, body string) string {
+	if body == "" {
+		return body
+	}
+
+	switch {
+	case strings.HasPrefix(contentType, "application/x-www-form-urlencoded"):
+		if values, err := url.ParseQuery(body); err == nil {
+			return scrubValues(values).Encode()
+		}
+	case strings.HasPrefix(contentType, "application/json"), json.Valid([]byte(body)):
+		var v interface{}
+		if err := json.Unmarshal([]byte(body), &v); err == nil && scrubJSON(v) {
+			if b, err := json.Marshal(v); err == nil {
+				return string(b)
+			}
+		}
+	}
+	return body
+}
+
+func scrubValues(values url.Values) url.Values {
+	for key := range values {
+		if scrubbedFields[strings.ToLower(key)] || scrubbedGrantFields[strings.ToLower(key)] {
+			values.Set(key, Redacted)
+		}
+	}
+	return values
+}
+
+// scrubJSON replaces the scrubbed fields in v, and reports whether it changed anything.
+func scrubJSON(v interface{}) bool {
+	changed := false
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for key, value := range v {
+			if scrubbedFields[strings.ToLower(key)] {
+				v[key] = Redacted
+				changed = true
+				continue
+			}
+			changed = scrubJSON(value) || changed
+		}
+	case []interface{}:
+		for _, value := range v {
+			changed = scrubJSON(value) || changed
+		}
+	}
+	return changed
+}
@@ -0,0 +1,47 @@
+package recorder
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScrub(t *testing.T) {
+	i := Interaction{
+		Request: Request{
+			Method: "POST",
+			URL:    "https://wavy.fm/oauth/token?client_id=app&page=2",
+			Header: http.Header{
+				"Authorization": {"Basic c2VjcmV0"},
+				"Content-Type":  {"application/x-www-form-urlencoded"},
+			},
+			Body: "code=abc&code_verifier=xyz&grant_type=authorization_code",
+		},
+		Response: Response{
+			StatusCode: 200,
+			Header:     http.Header{"Content-Type": {"application/json"}, "Set-Cookie": {"session=secret"}},
+			Body:       `{"access_token":"a","refresh_token":"r","user":{"token":"t","name":"OGKevin"}}`,
+		},
+	}
+
+	Scrub(&i)
+
+	assert.Equal(t, "https://wavy.fm/oauth/token?client_id=%5BREDACTED%5D&page=2", i.Request.URL)
+	assert.Empty(t, i.Request.Header.Get("Authorization"))
+	assert.Equal(t, "application/x-www-form-urlencoded", i.Request.Header.Get("Content-Type"))
+	assert.Equal(t, "code=%5BREDACTED%5D&code_verifier=%5BREDACTED%5D&grant_type=authorization_code", i.Request.Body)
+	assert.Empty(t, i.Response.Header.Get("Set-Cookie"))
+	assert.JSONEq(t, `{"access_token":"[REDACTED]","refresh_token":"[REDACTED]","user":{"token":"[REDACTED]","name":"OGKevin"}}`, i.Response.Body)
+}
+
+func TestScrub_untouchedBody(t *testing.T) {
+	i := Interaction{Response: Response{Body: `{"b": 1,  "a": 2}`}}
+	Scrub(&i)
+	assert.Equal(t, `{"b": 1,  "a": 2}`, i.Response.Body, "bodies without secrets keep their formatting")
+
+	// The code of api errors is not an authorization code.
+	i = Interaction{Response: Response{Body: `{"status": 404, "code": "user_not_found"}`}}
+	Scrub(&i)
+	assert.Equal(t, `{"status": 404, "code": "user_not_found"}`, i.Response.Body)
+}
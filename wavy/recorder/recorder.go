@@ -0,0 +1,270 @@
+// Package recorder records the http interactions of a client to cassette files and replays them later,
+// so tests of code using wavy.fm run offline and deterministically.
+//
+// Pass a Recorder as transport, e.g. with wavy.WithTransport:
+//
+//	rec, err := recorder.New("testdata/cassettes/profile.json", recorder.ModeReplay, recorder.Options{})
+//	...
+//	defer rec.Stop()
+//	c := wavy.NewClient(ctx, logger, clientID, clientSecret, wavy.WithTransport(rec))
+//
+// Credentials are scrubbed from recorded interactions, see Scrub.
+package recorder
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+)
+
+// Mode defines what a Recorder does with requests.
+type Mode int
+
+const (
+	// ModeReplay answers requests from the cassette, without touching the network.
+	ModeReplay Mode = iota
+	// ModeRecord sends requests and stores the interactions in the cassette on Stop.
+	ModeRecord
+	// ModePassthrough sends requests without touching the cassette.
+	ModePassthrough
+)
+
+// ModeEnv is the environment variable read by ModeFromEnv.
+const ModeEnv = "WAVY_RECORDER_MODE"
+
+func (m Mode) String() string {
+	switch m {
+	case ModeReplay:
+		return "replay"
+	case ModeRecord:
+		return "record"
+	case ModePassthrough:
+		return "passthrough"
+	}
+	return fmt.Sprintf("Mode(%d)", int(m))
+}
+
+// ParseMode parses "replay", "record" or "passthrough".
+func ParseMode(s string) (Mode, error) {
+	for _, m := range []Mode{ModeReplay, ModeRecord, ModePassthrough} {
+		if m.String() == s {
+			return m, nil
+		}
+	}
+	return 0, fmt.Errorf("recorder: unknown mode %q", s)
+}
+
+// ModeFromEnv parses the ModeEnv environment variable, fallback is returned when it is not set.
+func ModeFromEnv(fallback Mode) (Mode, error) {
+	s := os.Getenv(ModeEnv)
+	if s == "" {
+		return fallback, nil
+	}
+	return ParseMode(s)
+}
+
+// MatchFunc reports whether a recorded request answers r.
+type MatchFunc func(r *http.Request, recorded Request) bool
+
+// DefaultMatcher matches on method, url without query, and query parameters in any order.
+// A recorded parameter holding Redacted matches any value.
+func DefaultMatcher(r *http.Request, recorded Request) bool {
+	u, err := url.Parse(recorded.URL)
+	if err != nil || r.Method != recorded.Method {
+		return false
+	}
+	if r.URL.Scheme != u.Scheme || r.URL.Host != u.Host || r.URL.EscapedPath() != u.EscapedPath() {
+		return false
+	}
+
+	got, want := r.URL.Query(), u.Query()
+	if len(got) != len(want) {
+		return false
+	}
+	for key, values := range want {
+		if len(got[key]) != len(values) {
+			return false
+		}
+		for i, value := range values {
+			if value != Redacted && got[key][i] != value {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Options configures a Recorder.
+type Options struct {
+	// Transport sends requests in ModeRecord and ModePassthrough, defaults to http.DefaultTransport.
+	Transport http.RoundTripper
+	// Matcher defaults to DefaultMatcher.
+	Matcher MatchFunc
+	// Scrub is applied to recorded interactions after Scrub, e.g. to remove personal data.
+	Scrub func(i *Interaction)
+}
+
+// NoMatchError is returned in ModeReplay for requests the cassette has no interaction for.
+type NoMatchError struct {
+	Cassette string
+	Method   string
+	URL      string
+}
+
+func (e *NoMatchError) Error() string {
+	return fmt.Sprintf("recorder: cassette %s holds no interaction for %s %s", e.Cassette, e.Method, e.URL)
+}
+
+// Recorder is a http.RoundTripper recording to or replaying from a cassette.
+type Recorder struct {
+	path string
+	mode Mode
+	opts Options
+
+	mu       sync.Mutex
+	cassette *Cassette
+	used     []bool
+}
+
+// New returns a Recorder for the cassette at path. In ModeReplay the cassette must exist.
+func New(path string, mode Mode, opts Options) (*Recorder, error) {
+	if opts.Transport == nil {
+		opts.Transport = http.DefaultTransport
+	}
+	if opts.Matcher == nil {
+		opts.Matcher = DefaultMatcher
+	}
+
+	r := &Recorder{path: path, mode: mode, opts: opts, cassette: &Cassette{}}
+	if mode == ModeReplay {
+		c, err := LoadCassette(path)
+		if err != nil {
+			return nil, err
+		}
+		r.cassette = c
+		r.used = make([]bool, len(c.Interactions))
+	}
+	return r, nil
+}
+
+// Mode returns the mode of r.
+func (r *Recorder) Mode() Mode {
+	return r.mode
+}
+
+// RoundTrip implements http.RoundTripper.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	switch r.mode {
+	case ModeReplay:
+		return r.replay(req)
+	case ModeRecord:
+		return r.record(req)
+	}
+	return r.opts.Transport.RoundTrip(req)
+}
+
+// replay answers req with the first unused matching interaction. Once every match was used, the last match
+// answers again, so repeated requests such as token fetches keep working.
+func (r *Recorder) replay(req *http.Request) (*http.Response, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	match := -1
+	for i, interaction := range r.cassette.Interactions {
+		if !r.opts.Matcher(req, interaction.Request) {
+			continue
+		}
+		match = i
+		if !r.used[i] {
+			break
+		}
+	}
+	if match == -1 {
+		return nil, &NoMatchError{Cassette: r.path, Method: req.Method, URL: req.URL.String()}
+	}
+	r.used[match] = true
+
+	recorded := r.cassette.Interactions[match].Response
+	header := recorded.Header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", recorded.StatusCode, http.StatusText(recorded.StatusCode)),
+		StatusCode:    recorded.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          ioutil.NopCloser(bytes.NewBufferString(recorded.Body)),
+		ContentLength: int64(len(recorded.Body)),
+		Request:       req,
+	}, nil
+}
+
+func (r *Recorder) record(req *http.Request) (*http.Response, error) {
+	var reqBody []byte
+	if req.Body != nil {
+		var err error
+		if reqBody, err = ioutil.ReadAll(req.Body); err != nil {
+			return nil, fmt.Errorf("recorder: failed to read request body: %w", err)
+		}
+		req.Body.Close()
+		req.Body = ioutil.NopCloser(bytes.NewReader(reqBody))
+	}
+
+	res, err := r.opts.Transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	resBody, err := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("recorder: failed to read response body: %w", err)
+	}
+	res.Body = ioutil.NopCloser(bytes.NewReader(resBody))
+
+	i := Interaction{
+		Request: Request{
+			Method: req.Method,
+			URL:    req.URL.String(),
+			Header: req.Header.Clone(),
+			Body:   string(reqBody),
+		},
+		Response: Response{
+			StatusCode: res.StatusCode,
+			Header:     res.Header.Clone(),
+			Body:       string(resBody),
+		},
+	}
+	Scrub(&i)
+	if r.opts.Scrub != nil {
+		r.opts.Scrub(&i)
+	}
+
+	r.mu.Lock()
+	r.cassette.Interactions = append(r.cassette.Interactions, i)
+	r.mu.Unlock()
+
+	return res, nil
+}
+
+// Stop saves the cassette in ModeRecord. It is a no-op in the other modes.
+func (r *Recorder) Stop() error {
+	if r.mode != ModeRecord {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.cassette.Interactions) == 0 {
+		return errors.New("recorder: nothing was recorded")
+	}
+	return r.cassette.Save(r.path)
+}
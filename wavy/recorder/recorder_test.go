@@ -0,0 +1,143 @@
+package recorder
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMode(t *testing.T) {
+	for _, m := range []Mode{ModeReplay, ModeRecord, ModePassthrough} {
+		got, err := ParseMode(m.String())
+		require.NoError(t, err)
+		assert.Equal(t, m, got)
+	}
+
+	_, err := ParseMode("rewind")
+	assert.Error(t, err)
+}
+
+func TestModeFromEnv(t *testing.T) {
+	t.Setenv(ModeEnv, "")
+	got, err := ModeFromEnv(ModeReplay)
+	require.NoError(t, err)
+	assert.Equal(t, ModeReplay, got)
+
+	t.Setenv(ModeEnv, "record")
+	got, err = ModeFromEnv(ModeReplay)
+	require.NoError(t, err)
+	assert.Equal(t, ModeRecord, got)
+}
+
+func get(t *testing.T, c *http.Client, url string) (int, string) {
+	t.Helper()
+
+	res, err := c.Get(url)
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	b, err := ioutil.ReadAll(res.Body)
+	require.NoError(t, err)
+	return res.StatusCode, string(b)
+}
+
+func TestRecorder_recordAndReplay(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Set-Cookie", "session=secret")
+		w.Write([]byte(`{"path": "` + r.URL.Path + `", "access_token": "secret"}`))
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "cassettes", "test.json")
+
+	rec, err := New(path, ModeRecord, Options{})
+	require.NoError(t, err)
+	c := &http.Client{Transport: rec}
+
+	req, err := http.NewRequest("GET", server.URL+"/users?b=2&a=1", nil)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer secret")
+	res, err := c.Do(req)
+	require.NoError(t, err)
+	b, err := ioutil.ReadAll(res.Body)
+	require.NoError(t, err)
+	res.Body.Close()
+	assert.Contains(t, string(b), "secret", "the caller receives the unscrubbed response")
+	require.NoError(t, rec.Stop())
+
+	raw, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(raw), "secret")
+
+	rec, err = New(path, ModeReplay, Options{})
+	require.NoError(t, err)
+	c = &http.Client{Transport: rec}
+
+	status, body := get(t, c, server.URL+"/users?a=1&b=2")
+	assert.Equal(t, http.StatusOK, status)
+	assert.JSONEq(t, `{"path": "/users", "access_token": "[REDACTED]"}`, body)
+	assert.Equal(t, 1, calls, "replay must not hit the server")
+
+	_, err = c.Get(server.URL + "/users?a=1")
+	var noMatch *NoMatchError
+	require.True(t, errors.As(err, &noMatch), "got %v", err)
+	assert.Equal(t, "GET", noMatch.Method)
+}
+
+func TestRecorder_replayOrder(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.json")
+	c := &Cassette{Interactions: []Interaction{
+		{Request: Request{Method: "GET", URL: "https://wavy.fm/api/count"}, Response: Response{StatusCode: 200, Body: "1"}},
+		{Request: Request{Method: "GET", URL: "https://wavy.fm/api/count"}, Response: Response{StatusCode: 200, Body: "2"}},
+		{Request: Request{Method: "GET", URL: "https://wavy.fm/api/search?token=" + Redacted}, Response: Response{StatusCode: 404, Body: "{}"}},
+	}}
+	require.NoError(t, c.Save(path))
+
+	rec, err := New(path, ModeReplay, Options{})
+	require.NoError(t, err)
+	client := &http.Client{Transport: rec}
+
+	for _, want := range []string{"1", "2", "2"} {
+		_, body := get(t, client, "https://wavy.fm/api/count")
+		assert.Equal(t, want, body)
+	}
+
+	status, _ := get(t, client, "https://wavy.fm/api/search?token=anything")
+	assert.Equal(t, http.StatusNotFound, status, "redacted parameters match any value")
+
+	_, err = client.Post("https://wavy.fm/api/count", "text/plain", strings.NewReader(""))
+	assert.Error(t, err)
+}
+
+func TestRecorder_passthrough(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("live"))
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "test.json")
+	rec, err := New(path, ModePassthrough, Options{})
+	require.NoError(t, err)
+
+	_, body := get(t, &http.Client{Transport: rec}, server.URL)
+	assert.Equal(t, "live", body)
+	require.NoError(t, rec.Stop())
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err), "passthrough must not write the cassette")
+}
+
+func TestNew_missingCassette(t *testing.T) {
+	_, err := New(filepath.Join(t.TempDir(), "missing.json"), ModeReplay, Options{})
+	assert.Error(t, err)
+}
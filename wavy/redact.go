@@ -0,0 +1,196 @@
+package wavy
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// Redacted replaces masked values.
+const Redacted = "[REDACTED]"
+
+var (
+	// redactedHeaders are always masked, next to RedactionPolicy.Headers.
+	redactedHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie", "X-Api-Key"}
+	// redactedFields are credentials which are always masked as query and form parameters and as JSON fields, next
+	// to RedactionPolicy.Parameters.
+	redactedFields = []string{"access_token", "refresh_token", "id_token", "client_secret", "api_key"}
+	// redactedGrantParameters are always masked as query and form parameters, as sent in OAuth redirects and token
+	// requests. JSON fields of the same name, such as the code of an ApiError, are kept.
+	redactedGrantParameters = []string{"code", "code_verifier", "token"}
+
+	bearerPattern = regexp.MustCompile(`(?i)\b(bearer|basic)\s+[A-Za-z0-9\-._~+/]+=*`)
+	// userURIPattern matches user URIs in escaped urls and paths, where the identifier ends at a path separator.
+	userURIPattern = regexp.MustCompile(`(wavyfm(?::|%3A)user(?::|%3A)(?:id|username|discord)(?::|%3A))[^/?#&\s"\\]+`)
+	// quotedUserURIPattern matches user URIs quoted with %q, such as in errors, where the identifier ends at the
+	// closing quote and may contain slashes.
+	quotedUserURIPattern = regexp.MustCompile(`("wavyfm:user:(?:id|username|discord):)(?:[^"\\]|\\.)*"`)
+	// wholeUserURIPattern matches a single user URI, which may contain slashes.
+	wholeUserURIPattern = regexp.MustCompile(`(?s)^(wavyfm:user:(?:id|username|discord):).+$`)
+
+	// defaultParameterPatterns are the parameterPatterns of policies without Parameters.
+	defaultParameterPatterns = compileParameterPatterns(nil)
+)
+
+// RedactionPolicy defines what is masked in the log lines, errors and dumps of a Client.
+// Credentials, such as bearer tokens, the Authorization header and token parameters, are always masked.
+type RedactionPolicy struct {
+	// UserIdentifiers masks the username, id or Discord id of user URIs, e.g. wavyfm:user:username:[REDACTED].
+	UserIdentifiers bool
+	// Headers lists additional headers to mask in dumps.
+	Headers []string
+	// Parameters lists additional query parameters and JSON fields to mask.
+	Parameters []string
+
+	// patterns are compiled from Parameters by WithRedaction.
+	patterns *parameterPatterns
+}
+
+// WithRedaction masks values according to policy. By default only credentials are masked.
+func WithRedaction(policy RedactionPolicy) ClientOption {
+	return func(c *client) {
+		policy.patterns = compileParameterPatterns(policy.Parameters)
+		c.redaction = policy
+	}
+}
+
+// String masks credentials and, if enabled, user identifiers in s.
+func (p RedactionPolicy) String(s string) string {
+	s = bearerPattern.ReplaceAllString(s, "$1 "+Redacted)
+	s = p.parameterPatterns().redact(s)
+	if p.UserIdentifiers {
+		s = quotedUserURIPattern.ReplaceAllString(s, `${1}`+Redacted+`"`)
+		s = userURIPattern.ReplaceAllString(s, "${1}"+Redacted)
+	}
+	return s
+}
+
+// UserURI masks the identifier of uri if UserIdentifiers is set.
+func (p RedactionPolicy) UserURI(uri string) string {
+	if !p.UserIdentifiers {
+		return uri
+	}
+	return wholeUserURIPattern.ReplaceAllString(uri, "${1}"+Redacted)
+}
+
+// URL masks the credential parameters of u and, if enabled, the user identifiers in its path.
+func (p RedactionPolicy) URL(u *url.URL) string {
+	masked := *u
+	if masked.User != nil {
+		masked.User = url.User(Redacted)
+	}
+
+	query := masked.Query()
+	for _, param := range queryParameters(p.Parameters) {
+		for key := range query {
+			if strings.EqualFold(key, param) {
+				query.Set(key, Redacted)
+			}
+		}
+	}
+	if len(query) > 0 {
+		masked.RawQuery = query.Encode()
+	}
+
+	s := masked.String()
+	if p.UserIdentifiers {
+		s = userURIPattern.ReplaceAllString(s, "${1}"+Redacted)
+	}
+	return s
+}
+
+// Header returns a copy of h with credentials and the configured headers masked.
+func (p RedactionPolicy) Header(h http.Header) http.Header {
+	masked := h.Clone()
+	for _, key := range append(append([]string{}, redactedHeaders...), p.Headers...) {
+		if _, ok := masked[http.CanonicalHeaderKey(key)]; ok {
+			masked.Set(key, Redacted)
+		}
+	}
+	return masked
+}
+
+// Error masks the message of err. The returned error still unwraps to err, so errors.Is and errors.As work
+// as before, but the unwrapped errors are not masked.
+func (p RedactionPolicy) Error(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &redactedError{msg: p.String(err.Error()), err: err}
+}
+
+// queryParameters returns the query and form parameters masked next to extra.
+func queryParameters(extra []string) []string {
+	return append(append(append([]string{}, redactedFields...), redactedGrantParameters...), extra...)
+}
+
+// parameterPatterns match the masked parameters in query strings, form bodies and JSON objects.
+type parameterPatterns struct {
+	query, field *regexp.Regexp
+}
+
+func compileParameterPatterns(extra []string) *parameterPatterns {
+	return &parameterPatterns{
+		query: regexp.MustCompile(`(?im)((?:^|[?&])(?:` + alternation(queryParameters(extra)) + `)=)[^&\s"]*`),
+		field: regexp.MustCompile(`(?i)("(?:` + alternation(append(append([]string{}, redactedFields...), extra...)) + `)"\s*:\s*)"(?:[^"\\]|\\.)*"`),
+	}
+}
+
+// alternation returns a pattern matching any of names.
+func alternation(names []string) string {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = regexp.QuoteMeta(name)
+	}
+	return strings.Join(quoted, "|")
+}
+
+// parameterPatterns returns the patterns of p, as compiled by WithRedaction. Policies which did not go through
+// WithRedaction compile their Parameters on every call.
+func (p RedactionPolicy) parameterPatterns() *parameterPatterns {
+	switch {
+	case p.patterns != nil:
+		return p.patterns
+	case len(p.Parameters) == 0:
+		return defaultParameterPatterns
+	}
+	return compileParameterPatterns(p.Parameters)
+}
+
+// redact masks the values of the parameters in s.
+func (pp *parameterPatterns) redact(s string) string {
+	s = pp.query.ReplaceAllString(s, "${1}"+Redacted)
+	return pp.field.ReplaceAllString(s, `${1}"`+Redacted+`"`)
+}
+
+type redactedError struct {
+	msg string
+	err error
+}
+
+func (e *redactedError) Error() string { return e.msg }
+func (e *redactedError) Unwrap() error { return e.err }
+
+// dump writes the request and response of a call to w, masked according to p.
+func (p RedactionPolicy) dump(w io.Writer, req *http.Request, res *http.Response) {
+	out := req.Clone(req.Context())
+	out.Header = p.Header(req.Header)
+	if b, err := httputil.DumpRequestOut(out, false); err == nil {
+		fmt.Fprintf(w, "%s\n", p.String(string(b)))
+	}
+
+	if res == nil {
+		return
+	}
+	header := res.Header
+	res.Header = p.Header(header)
+	b, err := httputil.DumpResponse(res, true)
+	res.Header = header
+	if err == nil {
+		fmt.Fprintf(w, "%s\n", p.String(string(b)))
+	}
+}
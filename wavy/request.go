@@ -0,0 +1,114 @@
+package wavy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/OGKevin/go-wavy/wavy/schema"
+)
+
+// endpoint declares a wavy.fm endpoint fetched by getJSON.
+type endpoint struct {
+	// name describes the endpoint in log lines and errors, e.g. "total listens".
+	name string
+	// user is the uri of the user the endpoint concerns, if any.
+	user string
+	// route is the path template of the api description, such as /users/{user_uri}.
+	route string
+	// path is the path relative to the base url, built with pathf.
+	path string
+	// query is sent as query string, it may be nil.
+	query url.Values
+}
+
+// pathf formats a path from format, escaping every segment so it stays a single path segment.
+// A UserURI is passed as its String value, e.g. pathf("/users/%s", uri.String()).
+func pathf(format string, segments ...string) string {
+	escaped := make([]interface{}, len(segments))
+	for i, segment := range segments {
+		escaped[i] = url.PathEscape(segment)
+	}
+	return fmt.Sprintf(format, escaped...)
+}
+
+// url returns the path and query of e, relative to the base url.
+func (e endpoint) url() string {
+	if len(e.query) == 0 {
+		return e.path
+	}
+	return e.path + "?" + e.query.Encode()
+}
+
+// describe returns the name of e, including its user.
+func (e endpoint) describe() string {
+	if e.user == "" {
+		return e.name
+	}
+	return fmt.Sprintf("%s of %q", e.name, e.user)
+}
+
+// fields returns the structured log fields describing e, with the user masked according to p.
+func (e endpoint) fields(p RedactionPolicy) []interface{} {
+	fields := []interface{}{"endpoint", e.name}
+	if e.user != "" {
+		fields = append(fields, "user_uri", p.UserURI(e.user))
+	}
+	return fields
+}
+
+// getJSON requests e and decodes the JSON body into a T. Unknown fields are ignored, unless the call was
+// made WithStrictDecoding, which also validates the body against the vendored api schema. The returned response is only meant for its status and headers, its body is closed.
+// Errors are prefixed with the name of logger, the logger of the calling service, and masked according to the
+// RedactionPolicy of c.
+func getJSON[T any](ctx context.Context, c *client, logger Logger, e endpoint, o *callOptions) (*T, *http.Response, error) {
+	// Every line logged for the call, also by client.do, carries the fields of the endpoint.
+	callLogger := o.loggerOr(logger).With(e.fields(c.redaction)...)
+	o.logger = callLogger
+	o.route = e.route
+
+	start := time.Now()
+	callLogger.Trace("fetching " + e.name)
+
+	res, err := c.get(ctx, e.url(), o)
+	if err != nil {
+		callLogger.Debug("failed fetching "+e.name, "duration", time.Since(start), "error", c.redaction.Error(err))
+		return nil, nil, c.redaction.Error(fmt.Errorf("%s: failed to request %s: %w", logger.Name(), e.describe(), err))
+	}
+	defer res.Body.Close()
+	defer func() {
+		callLogger.Debug("finished fetching "+e.name, "status", res.StatusCode, "duration", time.Since(start))
+	}()
+
+	var body io.Reader = res.Body
+	if o.strict {
+		raw, err := ioutil.ReadAll(res.Body)
+		if err != nil {
+			return nil, res, c.redaction.Error(fmt.Errorf("%s: failed to read response body of %s: %w", logger.Name(), e.describe(), err))
+		}
+		if _, _, described := schema.V1Beta().Lookup(e.path); described {
+			if err := schema.V1Beta().ValidateResponse(e.path, raw); err != nil {
+				return nil, res, c.redaction.Error(fmt.Errorf("%s: response body of %s does not match the api schema: %w", logger.Name(), e.describe(), err))
+			}
+		}
+		body = bytes.NewReader(raw)
+	}
+
+	dec := json.NewDecoder(body)
+	if o.strict {
+		dec.DisallowUnknownFields()
+	}
+
+	var v T
+	if err := dec.Decode(&v); err != nil {
+		return nil, res, c.redaction.Error(fmt.Errorf("%s: failed to parse response body of %s: %w", logger.Name(), e.describe(), err))
+	}
+
+	return &v, res, nil
+}
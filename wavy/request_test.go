@@ -0,0 +1,86 @@
+package wavy
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/OGKevin/go-wavy/wavy/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_pathf(t *testing.T) {
+	tests := []struct {
+		name     string
+		format   string
+		segments []string
+		want     string
+	}{
+		{name: "plain", format: "/users/%s", segments: []string{"wavyfm:user:username:OGKevin"}, want: "/users/wavyfm:user:username:OGKevin"},
+		{name: "slash", format: "/users/%s/history", segments: []string{"wavyfm:user:username:a/b"}, want: "/users/wavyfm:user:username:a%2Fb/history"},
+		{name: "query", format: "/users/%s", segments: []string{"wavyfm:user:username:a?b#c"}, want: "/users/wavyfm:user:username:a%3Fb%23c"},
+		{name: "space", format: "/users/%s", segments: []string{"wavyfm:user:username:a b"}, want: "/users/wavyfm:user:username:a%20b"},
+		{name: "percent", format: "/users/%s", segments: []string{"wavyfm:user:username:a%2Fb"}, want: "/users/wavyfm:user:username:a%252Fb"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, pathf(tt.format, tt.segments...))
+		})
+	}
+}
+
+func Test_getJSON(t *testing.T) {
+	var gotPath string
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.EscapedPath()
+		w.Write([]byte(`{"total_listens": 3, "total_artists": 2, "top_genre": "vaporwave"}`))
+	}))
+	uri := UserURI{Username: "a/b"}
+
+	got, err := newUserService(c, c.logger).HistroyService(uri).GetStats(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, &GetHistroyStatsResponse{TotalListens: 3, TotalArtists: 2}, got)
+	assert.Equal(t, "/users/wavyfm:user:username:a%2Fb/history/stats", gotPath)
+
+	_, err = newUserService(c, c.logger).HistroyService(uri).GetStats(context.Background(), WithStrictDecoding())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `response body of history stats of "wavyfm:user:username:a/b" does not match the api schema`)
+	assert.Contains(t, err.Error(), "$.top_genre: unknown field")
+
+	var validationErr *schema.ValidationError
+	assert.True(t, errors.As(err, &validationErr))
+}
+
+func Test_getJSON_strictUndescribed(t *testing.T) {
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"total_listens": 3, "total_artists": 2, "top_genre": "vaporwave"}`))
+	}))
+
+	// Endpoints missing from the api description still reject unknown fields.
+	_, _, err := getJSON[GetHistroyStatsResponse](context.Background(), c, c.logger, endpoint{name: "stats", path: "/stats"},
+		newCallOptions([]CallOption{WithStrictDecoding()}))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to parse response body of stats")
+}
+
+func Test_getJSON_errors(t *testing.T) {
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(`{"status": 500, "name": "Internal Server Error"}`))
+	}))
+	m := newMetricsService(c, c.logger)
+
+	_, err := m.GetTotalListens(context.Background())
+	assert.Contains(t, err.Error(), "failed to request total listens")
+
+	_, err = m.GetTotalUsers(context.Background())
+	assert.Contains(t, err.Error(), "failed to request total users")
+
+	_, err = m.GetUserListensLeaderboard(context.Background())
+	assert.Contains(t, err.Error(), "failed to request user listens leaderboard")
+
+	var apiErr *ApiError
+	assert.True(t, errors.As(err, &apiErr))
+}
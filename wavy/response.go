@@ -0,0 +1,69 @@
+package wavy
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Response holds the http details of a call, next to the decoded response returned by the service method.
+// Pass WithResponse to a service method to receive it.
+type Response struct {
+	StatusCode int
+	Header     http.Header
+	// RequestID is taken from the X-Request-Id header, it is empty when wavy.fm did not send one.
+	RequestID string
+	// ServerTiming holds the raw Server-Timing header.
+	ServerTiming string
+	RateLimit    RateLimit
+	// Deprecation holds the deprecation notice of the endpoint, from the Deprecation, Sunset and Link headers.
+	Deprecation Deprecation
+}
+
+// RateLimit holds the rate limit state reported by wavy.fm. Fields are zero when the header was not sent.
+type RateLimit struct {
+	// Limit is the amount of requests allowed in the current window, from X-RateLimit-Limit.
+	Limit int
+	// Remaining is the amount of requests left in the current window, from X-RateLimit-Remaining.
+	Remaining int
+	// Reset is when the current window ends, from X-RateLimit-Reset.
+	Reset time.Time
+	// RetryAfter is how long to wait before retrying a rate limited request, from Retry-After.
+	RetryAfter time.Duration
+}
+
+func newResponse(res *http.Response) *Response {
+	r := &Response{
+		StatusCode:   res.StatusCode,
+		Header:       res.Header,
+		RequestID:    res.Header.Get("X-Request-Id"),
+		ServerTiming: res.Header.Get("Server-Timing"),
+		Deprecation:  newDeprecation(res.Header),
+	}
+
+	if limit, err := strconv.Atoi(res.Header.Get("X-RateLimit-Limit")); err == nil {
+		r.RateLimit.Limit = limit
+	}
+	if remaining, err := strconv.Atoi(res.Header.Get("X-RateLimit-Remaining")); err == nil {
+		r.RateLimit.Remaining = remaining
+	}
+	if reset, err := strconv.ParseInt(res.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+		r.RateLimit.Reset = parseRateLimitReset(reset, time.Now())
+	}
+	if retryAfter, err := strconv.Atoi(res.Header.Get("Retry-After")); err == nil {
+		r.RateLimit.RetryAfter = time.Duration(retryAfter) * time.Second
+	} else if at, err := http.ParseTime(res.Header.Get("Retry-After")); err == nil {
+		r.RateLimit.RetryAfter = time.Until(at)
+	}
+
+	return r
+}
+
+// parseRateLimitReset interprets a reset header, which is either a unix timestamp or the seconds until the reset.
+func parseRateLimitReset(reset int64, now time.Time) time.Time {
+	// Anything beyond a year of seconds can only be a timestamp.
+	if reset > 365*24*60*60 {
+		return time.Unix(reset, 0)
+	}
+	return now.Add(time.Duration(reset) * time.Second)
+}
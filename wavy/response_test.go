@@ -0,0 +1,59 @@
+package wavy
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithResponse(t *testing.T) {
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Request-Id", "req-1")
+		w.Header().Set("Server-Timing", "db;dur=53")
+		w.Header().Set("X-RateLimit-Limit", "60")
+		w.Header().Set("X-RateLimit-Remaining", "59")
+		w.Header().Set("X-RateLimit-Reset", "1614600000")
+		w.Write([]byte("42"))
+	}))
+
+	var res Response
+	got, err := newMetricsService(c, c.logger).GetTotalListens(context.Background(), WithResponse(&res))
+	require.NoError(t, err)
+	assert.Equal(t, int64(42), got.TotalListens)
+
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, "req-1", res.RequestID)
+	assert.Equal(t, "db;dur=53", res.ServerTiming)
+	assert.Equal(t, RateLimit{Limit: 60, Remaining: 59, Reset: time.Unix(1614600000, 0)}, res.RateLimit)
+}
+
+func TestWithResponse_error(t *testing.T) {
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Request-Id", "req-2")
+		w.Header().Set("Retry-After", "30")
+		w.WriteHeader(http.StatusTooManyRequests)
+		w.Write([]byte(`{"status": 429, "code": "rate_limited", "name": "Too Many Requests", "detail": "slow down"}`))
+	}))
+
+	var res Response
+	_, err := newUserService(c, c.logger).GetProfile(context.Background(), UserURI{Username: "OGKevin"}, WithResponse(&res))
+
+	var apiErr *ApiError
+	require.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, "rate_limited", apiErr.Code)
+	assert.Equal(t, http.StatusTooManyRequests, res.StatusCode)
+	assert.Equal(t, "req-2", res.RequestID)
+	assert.Equal(t, 30*time.Second, res.RateLimit.RetryAfter)
+}
+
+func Test_parseRateLimitReset(t *testing.T) {
+	now := time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, now.Add(time.Minute), parseRateLimitReset(60, now))
+	assert.True(t, time.Unix(1614600000, 0).Equal(parseRateLimitReset(1614600000, now)))
+}
@@ -0,0 +1,73 @@
+package wavy
+
+import (
+	"net/http"
+	"time"
+)
+
+const (
+	// minRetryBackoff is the wait after the first attempt of policies without MinBackoff.
+	minRetryBackoff = 100 * time.Millisecond
+	// maxRetryBackoff caps the waits of policies without MaxBackoff.
+	maxRetryBackoff = time.Minute
+)
+
+// DefaultRetryPolicy retries failed requests, rate limited requests and server errors up to 3 times.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	MinBackoff:  500 * time.Millisecond,
+	MaxBackoff:  10 * time.Second,
+}
+
+// RetryPolicy defines if and how a call is retried. Pass it with WithRetryPolicy.
+type RetryPolicy struct {
+	// MaxAttempts is the total amount of attempts, including the first one.
+	MaxAttempts int
+	// MinBackoff is the wait after the first attempt, it doubles for every following attempt up to MaxBackoff.
+	// A Retry-After header sent by wavy.fm takes precedence, up to MaxBackoff as well. Zero values wait 100ms at
+	// least and a minute at most. Calls are not retried when the wait would exceed the deadline of their context.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+	// ShouldRetry decides whether an attempt is retried, res is nil when err is set.
+	// When nil, failed requests, 429 and 5xx responses are retried.
+	ShouldRetry func(res *http.Response, err error) bool
+}
+
+func (p *RetryPolicy) shouldRetry(res *http.Response, err error) bool {
+	if p.ShouldRetry != nil {
+		return p.ShouldRetry(res, err)
+	}
+	if err != nil {
+		return true
+	}
+	return res.StatusCode == http.StatusTooManyRequests || res.StatusCode >= 500
+}
+
+// backoff returns the wait before the next attempt, after attempt attempts.
+func (p *RetryPolicy) backoff(attempt int, res *http.Response) time.Duration {
+	max := p.MaxBackoff
+	if max <= 0 {
+		max = maxRetryBackoff
+	}
+
+	if res != nil {
+		if retryAfter := newResponse(res).RateLimit.RetryAfter; retryAfter > 0 {
+			if retryAfter > max {
+				return max
+			}
+			return retryAfter
+		}
+	}
+
+	wait := p.MinBackoff
+	if wait <= 0 {
+		wait = minRetryBackoff
+	}
+	for i := 1; i < attempt && wait < max; i++ {
+		wait *= 2
+	}
+	if wait > max {
+		return max
+	}
+	return wait
+}
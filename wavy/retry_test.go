@@ -0,0 +1,118 @@
+package wavy
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithRetryPolicy(t *testing.T) {
+	calls := 0
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte(`{"status": 503, "name": "Service Unavailable"}`))
+			return
+		}
+		w.Write([]byte("42"))
+	}))
+
+	got, err := newMetricsService(c, c.logger).GetTotalListens(context.Background(),
+		WithRetryPolicy(RetryPolicy{MaxAttempts: 3, MinBackoff: time.Millisecond}))
+	require.NoError(t, err)
+	assert.Equal(t, int64(42), got.TotalListens)
+	assert.Equal(t, 3, calls)
+}
+
+func TestWithRetryPolicy_exhausted(t *testing.T) {
+	calls := 0
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusBadGateway)
+		w.Write([]byte(`{"status": 502, "name": "Bad Gateway"}`))
+	}))
+
+	_, err := newMetricsService(c, c.logger).GetTotalListens(context.Background(),
+		WithRetryPolicy(RetryPolicy{MaxAttempts: 2, MinBackoff: time.Millisecond}))
+	require.Error(t, err)
+	assert.Equal(t, 2, calls)
+}
+
+func TestWithRetryPolicy_clientError(t *testing.T) {
+	calls := 0
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"status": 404, "name": "Not Found"}`))
+	}))
+
+	_, err := newUserService(c, c.logger).GetProfile(context.Background(), UserURI{Username: "nobody"},
+		WithRetryPolicy(RetryPolicy{MaxAttempts: 3, MinBackoff: time.Millisecond}))
+	require.Error(t, err)
+	assert.Equal(t, 1, calls)
+}
+
+func TestWithRetryPolicy_canceled(t *testing.T) {
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+		w.Write([]byte(`{"status": 429, "name": "Too Many Requests"}`))
+	}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := newMetricsService(c, c.logger).GetTotalListens(ctx,
+		WithRetryPolicy(RetryPolicy{MaxAttempts: 3, MinBackoff: time.Minute}))
+	require.Error(t, err)
+	assert.Less(t, int64(time.Since(start)), int64(time.Second))
+}
+
+func TestRetryPolicy_backoff(t *testing.T) {
+	p := RetryPolicy{MinBackoff: time.Second, MaxBackoff: 3 * time.Second}
+
+	assert.Equal(t, time.Second, p.backoff(1, nil))
+	assert.Equal(t, 2*time.Second, p.backoff(2, nil))
+	assert.Equal(t, 3*time.Second, p.backoff(3, nil))
+
+	res := &http.Response{Header: http.Header{"Retry-After": []string{"2"}}}
+	assert.Equal(t, 2*time.Second, p.backoff(1, res))
+	// Retry-After does not exceed MaxBackoff.
+	res = &http.Response{Header: http.Header{"Retry-After": []string{"3600"}}}
+	assert.Equal(t, 3*time.Second, p.backoff(1, res))
+
+	var zero RetryPolicy
+	assert.Equal(t, minRetryBackoff, zero.backoff(1, nil))
+	assert.Equal(t, 2*minRetryBackoff, zero.backoff(2, nil))
+	assert.Equal(t, maxRetryBackoff, zero.backoff(100, nil))
+	assert.Equal(t, maxRetryBackoff, zero.backoff(1, res))
+}
+
+func TestWithRetryPolicy_deadline(t *testing.T) {
+	calls := 0
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Retry-After", "30")
+		w.WriteHeader(http.StatusTooManyRequests)
+		w.Write([]byte(`{"status": 429, "name": "Too Many Requests"}`))
+	}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// The wait exceeds the deadline, so the rate limited response is returned right away.
+	start := time.Now()
+	_, err := newMetricsService(c, c.logger).GetTotalListens(ctx, WithRetryPolicy(RetryPolicy{MaxAttempts: 3}))
+	require.Error(t, err)
+	var apiErr *ApiError
+	require.True(t, errors.As(err, &apiErr), err)
+	assert.Equal(t, http.StatusTooManyRequests, apiErr.Status)
+	assert.Equal(t, 1, calls)
+	assert.Less(t, int64(time.Since(start)), int64(time.Second))
+}
@@ -0,0 +1,314 @@
+// Package schema holds the OpenAPI description of the wavy.fm api the response models of the wavy package follow,
+// and validates response bodies against it. It backs the contract tests of the wavy package,
+// wavy.WithStrictDecoding and the code generated by internal/cmd/wavy-gen.
+//
+// Besides standard OpenAPI, the description uses these extensions for code generation:
+//
+//	x-name         on operations, the name of the endpoint used in log lines and errors, e.g. "user profile"
+//	x-go-method    on operations, the name of the method of the service interface requesting the operation
+//	x-go-response  on operations, the Go type the method returns when it wraps the response model
+//	x-go-service   on tags, the service interface of package wavy holding the methods of the tagged operations
+//	x-bind-user    on tags, the service is bound to a user, so its methods take no x-user parameter
+//	x-user         on parameters, the parameter is the uri of the user the operation concerns
+//	x-go-name      on schemas, the name of the generated Go type when it differs from the schema name
+//	x-go-type      on schemas, existing Go types to use instead of generating one, keyed by Go package name
+//	x-go-embed     on schemas, a schema whose fields are embedded instead of generated
+package schema
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+//go:embed openapi-v1beta.json
+var v1beta []byte
+
+// Document is the subset of an OpenAPI 3 document used by this package.
+type Document struct {
+	OpenAPI    string               `json:"openapi"`
+	Info       Info                 `json:"info"`
+	Tags       []Tag                `json:"tags"`
+	Paths      map[string]*PathItem `json:"paths"`
+	Components Components           `json:"components"`
+}
+
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description"`
+}
+
+// Tag groups operations, in the order of the document.
+type Tag struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	GoService   string `json:"x-go-service"`
+	BindUser    bool   `json:"x-bind-user"`
+}
+
+type Components struct {
+	Schemas map[string]*Schema `json:"schemas"`
+}
+
+// PathItem holds the operations of a path, keyed by lower case http method.
+type PathItem struct {
+	Get *Operation `json:"get"`
+}
+
+type Operation struct {
+	OperationID string               `json:"operationId"`
+	Name        string               `json:"x-name"`
+	GoMethod    string               `json:"x-go-method"`
+	GoResponse  string               `json:"x-go-response"`
+	Summary     string               `json:"summary"`
+	Description string               `json:"description"`
+	Tags        []string             `json:"tags"`
+	Parameters  []Parameter          `json:"parameters"`
+	Responses   map[string]*Response `json:"responses"`
+}
+
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Required    bool    `json:"required"`
+	User        bool    `json:"x-user"`
+	Description string  `json:"description"`
+	Schema      *Schema `json:"schema"`
+}
+
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Schema is the subset of a JSON schema used by the wavy.fm description.
+type Schema struct {
+	Ref         string             `json:"$ref,omitempty"`
+	Description string             `json:"description,omitempty"`
+	Type        string             `json:"type,omitempty"`
+	Format      string             `json:"format,omitempty"`
+	Nullable    bool               `json:"nullable,omitempty"`
+	Properties  map[string]*Schema `json:"properties,omitempty"`
+	Required    []string           `json:"required,omitempty"`
+	// AdditionalProperties allows fields which are not listed in Properties when nil or true.
+	AdditionalProperties *bool   `json:"additionalProperties,omitempty"`
+	Items                *Schema `json:"items,omitempty"`
+
+	GoName  string            `json:"x-go-name,omitempty"`
+	GoType  map[string]string `json:"x-go-type,omitempty"`
+	GoEmbed string            `json:"x-go-embed,omitempty"`
+
+	// order holds the names of Properties in the order of the document.
+	order []string
+}
+
+func (s *Schema) UnmarshalJSON(b []byte) error {
+	type plain Schema
+	if err := json.Unmarshal(b, (*plain)(s)); err != nil {
+		return err
+	}
+
+	var raw struct {
+		Properties json.RawMessage `json:"properties"`
+	}
+	if err := json.Unmarshal(b, &raw); err != nil || len(raw.Properties) == 0 {
+		return err
+	}
+	order, err := objectKeys(raw.Properties)
+	if err != nil {
+		return err
+	}
+	s.order = order
+	return nil
+}
+
+// objectKeys returns the keys of the JSON object b in order.
+func objectKeys(b []byte) ([]string, error) {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	if _, err := dec.Token(); err != nil {
+		return nil, err
+	}
+
+	var keys []string
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key.(string))
+
+		var skip json.RawMessage
+		if err := dec.Decode(&skip); err != nil {
+			return nil, err
+		}
+	}
+	return keys, nil
+}
+
+// PropertyNames returns the names of the properties of s in the order of the document.
+func (s *Schema) PropertyNames() []string {
+	if len(s.order) == len(s.Properties) {
+		return s.order
+	}
+
+	names := make([]string, 0, len(s.Properties))
+	for name := range s.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// IsRequired reports whether the property name of s is required.
+func (s *Schema) IsRequired(name string) bool {
+	for _, required := range s.Required {
+		if required == name {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	v1betaOnce sync.Once
+	v1betaDoc  *Document
+)
+
+// V1Beta returns the vendored description of the v1beta api. The document is shared, don't modify it.
+func V1Beta() *Document {
+	v1betaOnce.Do(func() {
+		doc, err := Parse(v1beta)
+		if err != nil {
+			panic(err)
+		}
+		v1betaDoc = doc
+	})
+	return v1betaDoc
+}
+
+// Parse parses an OpenAPI document and checks that its references resolve.
+func Parse(b []byte) (*Document, error) {
+	var doc Document
+	if err := json.Unmarshal(b, &doc); err != nil {
+		return nil, fmt.Errorf("schema: failed to parse document: %w", err)
+	}
+
+	for _, name := range doc.SchemaNames() {
+		if err := doc.checkRefs(doc.Components.Schemas[name]); err != nil {
+			return nil, fmt.Errorf("schema: %s: %w", name, err)
+		}
+	}
+	for _, path := range doc.PathNames() {
+		if op := doc.Paths[path].Get; op != nil {
+			if err := doc.checkRefs(op.ResponseSchema()); err != nil {
+				return nil, fmt.Errorf("schema: GET %s: %w", path, err)
+			}
+		}
+	}
+	return &doc, nil
+}
+
+func (d *Document) checkRefs(s *Schema) error {
+	if s == nil {
+		return nil
+	}
+	if s.Ref != "" {
+		_, err := d.Resolve(s)
+		return err
+	}
+	for _, p := range s.Properties {
+		if err := d.checkRefs(p); err != nil {
+			return err
+		}
+	}
+	return d.checkRefs(s.Items)
+}
+
+const refPrefix = "#/components/schemas/"
+
+// Resolve follows the reference of s, if any.
+func (d *Document) Resolve(s *Schema) (*Schema, error) {
+	for s != nil && s.Ref != "" {
+		if !strings.HasPrefix(s.Ref, refPrefix) {
+			return nil, fmt.Errorf("unsupported reference %q", s.Ref)
+		}
+		resolved, ok := d.Components.Schemas[strings.TrimPrefix(s.Ref, refPrefix)]
+		if !ok {
+			return nil, fmt.Errorf("unknown reference %q", s.Ref)
+		}
+		s = resolved
+	}
+	return s, nil
+}
+
+// RefName returns the component name s refers to, or "" when it is not a reference.
+func RefName(s *Schema) string {
+	return strings.TrimPrefix(s.Ref, refPrefix)
+}
+
+// SchemaNames returns the names of the component schemas, sorted.
+func (d *Document) SchemaNames() []string {
+	names := make([]string, 0, len(d.Components.Schemas))
+	for name := range d.Components.Schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// PathNames returns the path templates of the document, sorted.
+func (d *Document) PathNames() []string {
+	paths := make([]string, 0, len(d.Paths))
+	for path := range d.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// ResponseSchema returns the schema of the successful JSON response of o.
+func (o *Operation) ResponseSchema() *Schema {
+	if res, ok := o.Responses["200"]; ok {
+		return res.Content["application/json"].Schema
+	}
+	return nil
+}
+
+// Lookup returns the GET operation matching path, which is relative to the server url and may be escaped,
+// e.g. /users/wavyfm:user:username:OGKevin/history/stats. The returned template is the path it matched.
+func (d *Document) Lookup(path string) (template string, op *Operation, ok bool) {
+	if i := strings.IndexAny(path, "?#"); i >= 0 {
+		path = path[:i]
+	}
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+
+	for _, template := range d.PathNames() {
+		item := d.Paths[template]
+		if item.Get != nil && matchTemplate(strings.Split(strings.Trim(template, "/"), "/"), segments) {
+			return template, item.Get, true
+		}
+	}
+	return "", nil, false
+}
+
+func matchTemplate(template, segments []string) bool {
+	if len(template) != len(segments) {
+		return false
+	}
+	for i, t := range template {
+		isParam := strings.HasPrefix(t, "{") && strings.HasSuffix(t, "}")
+		if (isParam && segments[i] == "") || (!isParam && t != segments[i]) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,58 @@
+package schema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestV1Beta(t *testing.T) {
+	doc := V1Beta()
+	assert.Equal(t, "v1beta", doc.Info.Version)
+
+	for _, path := range doc.PathNames() {
+		op := doc.Paths[path].Get
+		require.NotNil(t, op, path)
+		assert.NotEmpty(t, op.OperationID, path)
+		assert.NotNil(t, op.ResponseSchema(), path)
+	}
+}
+
+func TestParse_unknownReference(t *testing.T) {
+	_, err := Parse([]byte(`{"components": {"schemas": {"A": {"$ref": "#/components/schemas/B"}}}}`))
+	assert.Error(t, err)
+}
+
+func TestDocument_Lookup(t *testing.T) {
+	doc := V1Beta()
+
+	tests := []struct {
+		path     string
+		template string
+		ok       bool
+	}{
+		{path: "/metrics/total-listens", template: "/metrics/total-listens", ok: true},
+		{path: "/users/wavyfm:user:username:OGKevin", template: "/users/{user_uri}", ok: true},
+		{path: "/users/wavyfm:user:username:OGKevin/history/stats?x=1", template: "/users/{user_uri}/history/stats", ok: true},
+		{path: "/users/wavyfm:user:username:a%2Fb/history/recent", template: "/users/{user_uri}/history/recent", ok: true},
+		{path: "/users//history/recent"},
+		{path: "/users/OGKevin/history"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			template, _, ok := doc.Lookup(tt.path)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.template, template)
+		})
+	}
+}
+
+func TestSchema_PropertyNames(t *testing.T) {
+	profile := V1Beta().Components.Schemas["UserProfile"]
+	assert.Equal(t, []string{"uri", "id", "username", "join_time", "profile"}, profile.PropertyNames())
+	assert.True(t, profile.IsRequired("join_time"))
+
+	s := &Schema{Properties: map[string]*Schema{"b": {}, "a": {}}}
+	assert.Equal(t, []string{"a", "b"}, s.PropertyNames(), "schemas built in code are sorted")
+}
@@ -0,0 +1,183 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Problem is a difference between a JSON value and its schema.
+type Problem struct {
+	// Path locates the value, e.g. $.items[0].song.
+	Path string
+	// Message describes the difference, e.g. "unknown field".
+	Message string
+}
+
+func (p Problem) String() string {
+	return p.Path + ": " + p.Message
+}
+
+// ValidationError lists the problems found by Validate.
+type ValidationError struct {
+	// Schema names the schema the value was validated against.
+	Schema   string
+	Problems []Problem
+}
+
+func (e *ValidationError) Error() string {
+	problems := make([]string, len(e.Problems))
+	for i, p := range e.Problems {
+		problems[i] = p.String()
+	}
+	return fmt.Sprintf("schema: value does not match %s: %s", e.Schema, strings.Join(problems, "; "))
+}
+
+// Validate validates the JSON body against s, reporting unknown fields, missing required fields and values of the
+// wrong type. The returned error is a *ValidationError unless body is not JSON.
+func (d *Document) Validate(s *Schema, body []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return fmt.Errorf("schema: failed to parse body: %w", err)
+	}
+
+	name := RefName(s)
+	if name == "" {
+		name = "schema"
+	}
+
+	problems := d.validate(s, v, "$")
+	if len(problems) > 0 {
+		return &ValidationError{Schema: name, Problems: problems}
+	}
+	return nil
+}
+
+// ValidateResponse validates the JSON body served for the GET request of path, see Lookup.
+func (d *Document) ValidateResponse(path string, body []byte) error {
+	template, op, ok := d.Lookup(path)
+	if !ok {
+		return fmt.Errorf("schema: %s is not described", path)
+	}
+	s := op.ResponseSchema()
+	if s == nil {
+		return fmt.Errorf("schema: GET %s has no JSON response", template)
+	}
+	return d.Validate(s, body)
+}
+
+func (d *Document) validate(s *Schema, v interface{}, path string) []Problem {
+	s, err := d.Resolve(s)
+	if err != nil {
+		return []Problem{{Path: path, Message: err.Error()}}
+	}
+	if s == nil {
+		return nil
+	}
+
+	if v == nil {
+		if s.Nullable {
+			return nil
+		}
+		return []Problem{{Path: path, Message: "unexpected null"}}
+	}
+
+	switch s.Type {
+	case "object":
+		object, ok := v.(map[string]interface{})
+		if !ok {
+			return typeProblem(path, s.Type, v)
+		}
+		return d.validateObject(s, object, path)
+	case "array":
+		array, ok := v.([]interface{})
+		if !ok {
+			return typeProblem(path, s.Type, v)
+		}
+		var problems []Problem
+		for i, item := range array {
+			problems = append(problems, d.validate(s.Items, item, fmt.Sprintf("%s[%d]", path, i))...)
+		}
+		return problems
+	case "string":
+		str, ok := v.(string)
+		if !ok {
+			return typeProblem(path, s.Type, v)
+		}
+		if s.Format == "date-time" {
+			if _, err := time.Parse(time.RFC3339, str); err != nil {
+				return []Problem{{Path: path, Message: fmt.Sprintf("%q is not a date-time", str)}}
+			}
+		}
+	case "integer":
+		n, ok := v.(json.Number)
+		if !ok {
+			return typeProblem(path, s.Type, v)
+		}
+		if _, err := n.Int64(); err != nil {
+			return []Problem{{Path: path, Message: fmt.Sprintf("%s is not an integer", n)}}
+		}
+	case "number":
+		if _, ok := v.(json.Number); !ok {
+			return typeProblem(path, s.Type, v)
+		}
+	case "boolean":
+		if _, ok := v.(bool); !ok {
+			return typeProblem(path, s.Type, v)
+		}
+	}
+	return nil
+}
+
+func (d *Document) validateObject(s *Schema, object map[string]interface{}, path string) []Problem {
+	var problems []Problem
+	for _, name := range s.Required {
+		if _, ok := object[name]; !ok {
+			problems = append(problems, Problem{Path: path + "." + name, Message: "missing required field"})
+		}
+	}
+
+	names := make([]string, 0, len(object))
+	for name := range object {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		property, ok := s.Properties[name]
+		if !ok {
+			if s.AdditionalProperties != nil && !*s.AdditionalProperties {
+				problems = append(problems, Problem{Path: path + "." + name, Message: "unknown field"})
+			}
+			continue
+		}
+		problems = append(problems, d.validate(property, object[name], path+"."+name)...)
+	}
+	return problems
+}
+
+func typeProblem(path, want string, v interface{}) []Problem {
+	return []Problem{{Path: path, Message: fmt.Sprintf("expected %s, got %s", want, jsonType(v))}}
+}
+
+func jsonType(v interface{}) string {
+	switch v.(type) {
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case json.Number:
+		return "number"
+	case bool:
+		return "boolean"
+	}
+	return "null"
+}
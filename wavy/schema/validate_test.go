@@ -0,0 +1,97 @@
+package schema
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDocument_ValidateResponse(t *testing.T) {
+	doc := V1Beta()
+
+	tests := []struct {
+		name     string
+		path     string
+		body     string
+		problems []Problem
+	}{
+		{
+			name: "plain count",
+			path: "/metrics/total-listens",
+			body: `42`,
+		},
+		{
+			name:     "count object",
+			path:     "/metrics/total-users",
+			body:     `{"count": 42}`,
+			problems: []Problem{{Path: "$", Message: "expected integer, got object"}},
+		},
+		{
+			name:     "count string",
+			path:     "/metrics/total-users",
+			body:     `"42"`,
+			problems: []Problem{{Path: "$", Message: "expected integer, got string"}},
+		},
+		{
+			name: "stats",
+			path: "/users/wavyfm:user:username:OGKevin/history/stats",
+			body: `{"total_listens": 1, "total_artists": 2}`,
+		},
+		{
+			name: "drifted stats",
+			path: "/users/wavyfm:user:username:OGKevin/history/stats",
+			body: `{"total_listens": "1", "total_albums": 2}`,
+			problems: []Problem{
+				{Path: "$.total_artists", Message: "missing required field"},
+				{Path: "$.total_albums", Message: "unknown field"},
+				{Path: "$.total_listens", Message: "expected integer, got string"},
+			},
+		},
+		{
+			name: "nested",
+			path: "/users/wavyfm:user:username:OGKevin/history/recent",
+			body: `{"items": [{"local": false, "song": {"source": "spotify", "source_url": "", "name": null},
+				"album": {"source": "", "source_url": "", "name": "", "art_url": ""}, "artists": [],
+				"date": "yesterday", "play_id": "1"}]}`,
+			problems: []Problem{
+				{Path: "$.items[0].date", Message: `"yesterday" is not a date-time`},
+				{Path: "$.items[0].song.name", Message: "unexpected null"},
+			},
+		},
+		{
+			name: "leaderboard without rank",
+			path: "/metrics/user-listens-leaderboard",
+			body: `[{"count": 1, "username": "OGKevin", "user_id": "1"}]`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := doc.ValidateResponse(tt.path, []byte(tt.body))
+			if tt.problems == nil {
+				assert.NoError(t, err)
+				return
+			}
+
+			var validationErr *ValidationError
+			require.True(t, errors.As(err, &validationErr), "got %v", err)
+			assert.Equal(t, tt.problems, validationErr.Problems)
+		})
+	}
+}
+
+func TestDocument_ValidateResponse_errors(t *testing.T) {
+	doc := V1Beta()
+
+	assert.Error(t, doc.ValidateResponse("/unknown", []byte(`{}`)))
+	assert.Error(t, doc.ValidateResponse("/metrics/total-users", []byte(`{`)))
+}
+
+func TestValidationError_Error(t *testing.T) {
+	err := &ValidationError{Schema: "HistoryStats", Problems: []Problem{
+		{Path: "$.total_artists", Message: "missing required field"},
+		{Path: "$.total_albums", Message: "unknown field"},
+	}}
+	assert.Equal(t, "schema: value does not match HistoryStats: $.total_artists: missing required field; $.total_albums: unknown field", err.Error())
+}
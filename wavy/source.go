@@ -0,0 +1,219 @@
+package wavy
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ErrUnknownSource is returned when a source url does not belong to any registered provider.
+var ErrUnknownSource = errors.New("unknown source provider")
+
+// SourceKind defines what a SourceRef points to.
+type SourceKind string
+
+const (
+	SourceKindTrack  SourceKind = "track"
+	SourceKindAlbum  SourceKind = "album"
+	SourceKindArtist SourceKind = "artist"
+)
+
+// SourceRef is the structured form of the SourceURL of a Song, Album or Artists.
+type SourceRef struct {
+	Provider string
+	Kind     SourceKind
+	ID       string
+}
+
+// URI returns the reference as provider:kind:id, which for spotify is a valid spotify uri like spotify:track:4uLU6hMCjMI75M1A2tKUQC.
+func (s SourceRef) URI() string {
+	return fmt.Sprintf("%s:%s:%s", s.Provider, s.Kind, s.ID)
+}
+
+// SourceURLParser parses the source urls of a single provider. ok is false when the url does not belong to the provider.
+type SourceURLParser func(u *url.URL) (ref *SourceRef, ok bool)
+
+var (
+	sourceProvidersMu sync.RWMutex
+	sourceProviders   = map[string]SourceURLParser{
+		"spotify":    parseSpotifyURL,
+		"deezer":     parseDeezerURL,
+		"youtube":    parseYoutubeURL,
+		"applemusic": parseAppleMusicURL,
+	}
+)
+
+// RegisterSourceProvider registers the parser for the source urls of a provider, replacing any parser
+// previously registered under the same name. The name should match the Source field reported by wavy.
+func RegisterSourceProvider(provider string, parser SourceURLParser) {
+	sourceProvidersMu.Lock()
+	defer sourceProvidersMu.Unlock()
+
+	sourceProviders[provider] = parser
+}
+
+// ParseSourceURL parses a source url with the registered providers.
+// The provider named by source is tried first, source may be empty when unknown.
+// ErrUnknownSource is returned when no provider recognizes the url.
+func ParseSourceURL(source, sourceURL string) (*SourceRef, error) {
+	if sourceURL == "" {
+		return nil, fmt.Errorf("failed to parse source url: %w: no source url set", ErrUnknownSource)
+	}
+
+	u, err := url.Parse(sourceURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse source url %q: %w", sourceURL, err)
+	}
+
+	sourceProvidersMu.RLock()
+	defer sourceProvidersMu.RUnlock()
+
+	if parser, ok := sourceProviders[source]; ok {
+		if ref, ok := parser(u); ok {
+			return ref, nil
+		}
+	}
+
+	names := make([]string, 0, len(sourceProviders))
+	for name := range sourceProviders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if ref, ok := sourceProviders[name](u); ok {
+			return ref, nil
+		}
+	}
+
+	return nil, fmt.Errorf("failed to parse source url %q: %w", sourceURL, ErrUnknownSource)
+}
+
+// SourceRef parses the SourceURL of the song. Local songs have no source url and return ErrUnknownSource.
+func (s Song) SourceRef() (*SourceRef, error) {
+	return ParseSourceURL(s.Source, s.SourceURL)
+}
+
+// SourceRef parses the SourceURL of the album.
+func (a Album) SourceRef() (*SourceRef, error) {
+	return ParseSourceURL(a.Source, a.SourceURL)
+}
+
+// SourceRef parses the SourceURL of the artist.
+func (a Artists) SourceRef() (*SourceRef, error) {
+	return ParseSourceURL(a.Source, a.SourceURL)
+}
+
+var spotifyID = regexp.MustCompile(`^[0-9A-Za-z]{22}$`)
+
+// parseSpotifyURL parses urls like https://open.spotify.com/track/4uLU6hMCjMI75M1A2tKUQC and spotify:track:4uLU6hMCjMI75M1A2tKUQC.
+func parseSpotifyURL(u *url.URL) (*SourceRef, bool) {
+	var pieces []string
+	switch {
+	case u.Scheme == "spotify":
+		pieces = strings.Split(u.Opaque, ":")
+	case u.Host == "open.spotify.com" || u.Host == "play.spotify.com":
+		pieces = strings.Split(strings.Trim(u.Path, "/"), "/")
+		// Localized links are prefixed, e.g. /intl-de/track/...
+		if len(pieces) > 0 && strings.HasPrefix(pieces[0], "intl-") {
+			pieces = pieces[1:]
+		}
+	default:
+		return nil, false
+	}
+
+	if len(pieces) != 2 || !spotifyID.MatchString(pieces[1]) {
+		return nil, false
+	}
+
+	kind := SourceKind(pieces[0])
+	switch kind {
+	case SourceKindTrack, SourceKindAlbum, SourceKindArtist:
+	default:
+		return nil, false
+	}
+
+	return &SourceRef{Provider: "spotify", Kind: kind, ID: pieces[1]}, true
+}
+
+// parseDeezerURL parses urls like https://www.deezer.com/en/track/3135556.
+func parseDeezerURL(u *url.URL) (*SourceRef, bool) {
+	if u.Host != "www.deezer.com" && u.Host != "deezer.com" {
+		return nil, false
+	}
+
+	pieces := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if len(pieces) == 3 {
+		// Drop the language prefix.
+		pieces = pieces[1:]
+	}
+	if len(pieces) != 2 || pieces[1] == "" {
+		return nil, false
+	}
+
+	kind := SourceKind(pieces[0])
+	switch kind {
+	case SourceKindTrack, SourceKindAlbum, SourceKindArtist:
+	default:
+		return nil, false
+	}
+
+	return &SourceRef{Provider: "deezer", Kind: kind, ID: pieces[1]}, true
+}
+
+// parseYoutubeURL parses video urls like https://music.youtube.com/watch?v=dQw4w9WgXcQ and https://youtu.be/dQw4w9WgXcQ.
+// Only tracks can be referenced.
+func parseYoutubeURL(u *url.URL) (*SourceRef, bool) {
+	var id string
+	switch u.Host {
+	case "youtu.be":
+		id = strings.Trim(u.Path, "/")
+	case "www.youtube.com", "youtube.com", "music.youtube.com", "m.youtube.com":
+		if u.Path != "/watch" {
+			return nil, false
+		}
+		id = u.Query().Get("v")
+	default:
+		return nil, false
+	}
+
+	if id == "" || strings.Contains(id, "/") {
+		return nil, false
+	}
+
+	return &SourceRef{Provider: "youtube", Kind: SourceKindTrack, ID: id}, true
+}
+
+// parseAppleMusicURL parses urls like https://music.apple.com/us/album/never-gonna-give-you-up/1558533900?i=1558534271,
+// where the i query parameter selects a track on the album.
+func parseAppleMusicURL(u *url.URL) (*SourceRef, bool) {
+	if u.Host != "music.apple.com" {
+		return nil, false
+	}
+
+	pieces := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if len(pieces) < 3 {
+		return nil, false
+	}
+	// Drop the storefront.
+	pieces = pieces[1:]
+	id := pieces[len(pieces)-1]
+
+	switch pieces[0] {
+	case "album":
+		if track := u.Query().Get("i"); track != "" {
+			return &SourceRef{Provider: "applemusic", Kind: SourceKindTrack, ID: track}, true
+		}
+		return &SourceRef{Provider: "applemusic", Kind: SourceKindAlbum, ID: id}, true
+	case "song":
+		return &SourceRef{Provider: "applemusic", Kind: SourceKindTrack, ID: id}, true
+	case "artist":
+		return &SourceRef{Provider: "applemusic", Kind: SourceKindArtist, ID: id}, true
+	}
+
+	return nil, false
+}
@@ -0,0 +1,112 @@
+package wavy
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSourceURL(t *testing.T) {
+	type args struct {
+		source    string
+		sourceURL string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    *SourceRef
+		wantErr bool
+	}{
+		{
+			name: "spotify track",
+			args: args{source: "spotify", sourceURL: "https://open.spotify.com/track/4uLU6hMCjMI75M1A2tKUQC"},
+			want: &SourceRef{Provider: "spotify", Kind: SourceKindTrack, ID: "4uLU6hMCjMI75M1A2tKUQC"},
+		},
+		{
+			name: "spotify localized album with query",
+			args: args{source: "spotify", sourceURL: "https://open.spotify.com/intl-de/album/6XhjNHCyCDyyGJRM5mg40G?si=abc"},
+			want: &SourceRef{Provider: "spotify", Kind: SourceKindAlbum, ID: "6XhjNHCyCDyyGJRM5mg40G"},
+		},
+		{
+			name: "spotify uri without source",
+			args: args{sourceURL: "spotify:artist:0gxyHStUsqpMadRV0Di1Qt"},
+			want: &SourceRef{Provider: "spotify", Kind: SourceKindArtist, ID: "0gxyHStUsqpMadRV0Di1Qt"},
+		},
+		{
+			name:    "spotify playlist",
+			args:    args{source: "spotify", sourceURL: "https://open.spotify.com/playlist/37i9dQZF1DXcBWIGoYBM5M"},
+			wantErr: true,
+		},
+		{
+			name:    "spotify invalid id",
+			args:    args{source: "spotify", sourceURL: "https://open.spotify.com/track/short"},
+			wantErr: true,
+		},
+		{
+			name: "deezer",
+			args: args{source: "deezer", sourceURL: "https://www.deezer.com/en/track/3135556"},
+			want: &SourceRef{Provider: "deezer", Kind: SourceKindTrack, ID: "3135556"},
+		},
+		{
+			name: "youtube music",
+			args: args{sourceURL: "https://music.youtube.com/watch?v=dQw4w9WgXcQ&feature=share"},
+			want: &SourceRef{Provider: "youtube", Kind: SourceKindTrack, ID: "dQw4w9WgXcQ"},
+		},
+		{
+			name: "apple music track on album",
+			args: args{sourceURL: "https://music.apple.com/us/album/whenever-you-need-somebody/1558533900?i=1558534271"},
+			want: &SourceRef{Provider: "applemusic", Kind: SourceKindTrack, ID: "1558534271"},
+		},
+		{
+			name:    "unknown provider",
+			args:    args{source: "tidal", sourceURL: "https://tidal.com/browse/track/1234"},
+			wantErr: true,
+		},
+		{
+			name:    "local",
+			args:    args{source: "spotify"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseSourceURL(tt.args.source, tt.args.sourceURL)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseSourceURL() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err != nil {
+				assert.True(t, errors.Is(err, ErrUnknownSource))
+				return
+			}
+
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestSourceRef_URI(t *testing.T) {
+	ref, err := Song{Source: "spotify", SourceURL: "https://open.spotify.com/track/4uLU6hMCjMI75M1A2tKUQC"}.SourceRef()
+	assert.NoError(t, err)
+	assert.Equal(t, "spotify:track:4uLU6hMCjMI75M1A2tKUQC", ref.URI())
+}
+
+func TestRegisterSourceProvider(t *testing.T) {
+	RegisterSourceProvider("tidal", func(u *url.URL) (*SourceRef, bool) {
+		if u.Host != "tidal.com" {
+			return nil, false
+		}
+		return &SourceRef{Provider: "tidal", Kind: SourceKindTrack, ID: u.Path[len("/browse/track/"):]}, true
+	})
+	defer func() {
+		sourceProvidersMu.Lock()
+		delete(sourceProviders, "tidal")
+		sourceProvidersMu.Unlock()
+	}()
+
+	got, err := Artists{Source: "tidal", SourceURL: "https://tidal.com/browse/track/1234"}.SourceRef()
+	assert.NoError(t, err)
+	assert.Equal(t, &SourceRef{Provider: "tidal", Kind: SourceKindTrack, ID: "1234"}, got)
+}
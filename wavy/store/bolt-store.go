@@ -0,0 +1,174 @@
+package store
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/OGKevin/go-wavy/wavy"
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	boltItemsBucket = []byte("items")
+	boltPlaysBucket = []byte("plays")
+)
+
+type boltStore struct {
+	db *bolt.DB
+}
+
+// NewBoltStore returns a HistoryStore backed by an embedded bbolt key-value database at path.
+// The file is created if it does not exist yet.
+//
+// Every user gets a bucket with two sub buckets: items, keyed by date and PlayID so range scans are
+// a single cursor walk, and plays, keyed by PlayID to make appends idempotent.
+func NewBoltStore(path string) (HistoryStore, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("store: failed to open bolt database %q: %w", path, err)
+	}
+
+	return &boltStore{db: db}, nil
+}
+
+// boltItemKey builds a key which sorts by date first. The date is stored as seconds with their sign bit flipped,
+// followed by the nanoseconds, so dates before 1970 and the zero time sort before later dates.
+func boltItemKey(date time.Time, playID string) []byte {
+	key := make([]byte, 12, 12+len(playID))
+	binary.BigEndian.PutUint64(key, uint64(date.Unix())^1<<63)
+	binary.BigEndian.PutUint32(key[8:], uint32(date.Nanosecond()))
+	return append(key, playID...)
+}
+
+func boltDateKey(date time.Time) []byte {
+	return boltItemKey(date, "")
+}
+
+func (b *boltStore) Append(ctx context.Context, uri wavy.UserURI, items ...wavy.Item) (int, error) {
+	added := 0
+	err := b.db.Update(func(tx *bolt.Tx) error {
+		user, err := tx.CreateBucketIfNotExists([]byte(userKey(uri)))
+		if err != nil {
+			return err
+		}
+		itemsBucket, err := user.CreateBucketIfNotExists(boltItemsBucket)
+		if err != nil {
+			return err
+		}
+		playsBucket, err := user.CreateBucketIfNotExists(boltPlaysBucket)
+		if err != nil {
+			return err
+		}
+
+		for _, item := range items {
+			if playsBucket.Get([]byte(item.PlayID)) != nil {
+				continue
+			}
+
+			value, err := json.Marshal(item)
+			if err != nil {
+				return fmt.Errorf("failed to encode item %q: %w", item.PlayID, err)
+			}
+
+			key := boltItemKey(item.Date, item.PlayID)
+			if err := itemsBucket.Put(key, value); err != nil {
+				return err
+			}
+			if err := playsBucket.Put([]byte(item.PlayID), key); err != nil {
+				return err
+			}
+			added++
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("store: failed to append history for %q: %w", userKey(uri), err)
+	}
+
+	return added, nil
+}
+
+func (b *boltStore) Checkpoint(ctx context.Context, uri wavy.UserURI) (*Checkpoint, error) {
+	var checkpoint *Checkpoint
+	err := b.db.View(func(tx *bolt.Tx) error {
+		itemsBucket := b.itemsBucket(tx, uri)
+		if itemsBucket == nil {
+			return nil
+		}
+
+		_, value := itemsBucket.Cursor().Last()
+		if value == nil {
+			return nil
+		}
+
+		var item wavy.Item
+		if err := json.Unmarshal(value, &item); err != nil {
+			return err
+		}
+		checkpoint = &Checkpoint{PlayID: item.PlayID, Date: item.Date}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("store: failed to read checkpoint for %q: %w", userKey(uri), err)
+	}
+	if checkpoint == nil {
+		return nil, ErrNoCheckpoint
+	}
+
+	return checkpoint, nil
+}
+
+func (b *boltStore) Range(ctx context.Context, uri wavy.UserURI, from, to time.Time) ([]wavy.Item, error) {
+	var items []wavy.Item
+	err := b.db.View(func(tx *bolt.Tx) error {
+		itemsBucket := b.itemsBucket(tx, uri)
+		if itemsBucket == nil {
+			return nil
+		}
+
+		c := itemsBucket.Cursor()
+		var key, value []byte
+		if from.IsZero() {
+			key, value = c.First()
+		} else {
+			key, value = c.Seek(boltDateKey(from))
+		}
+
+		for ; key != nil; key, value = c.Next() {
+			if !to.IsZero() && bytes.Compare(key, boltDateKey(to)) >= 0 {
+				break
+			}
+
+			var item wavy.Item
+			if err := json.Unmarshal(value, &item); err != nil {
+				return err
+			}
+			items = append(items, item)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("store: failed to range history for %q: %w", userKey(uri), err)
+	}
+
+	return items, nil
+}
+
+func (b *boltStore) itemsBucket(tx *bolt.Tx, uri wavy.UserURI) *bolt.Bucket {
+	user := tx.Bucket([]byte(userKey(uri)))
+	if user == nil {
+		return nil
+	}
+	return user.Bucket(boltItemsBucket)
+}
+
+func (b *boltStore) Close() error {
+	return b.db.Close()
+}
@@ -0,0 +1,57 @@
+package store_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/OGKevin/go-wavy/wavy"
+	"github.com/OGKevin/go-wavy/wavy/store"
+	"github.com/OGKevin/go-wavy/wavy/store/storetest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func tempDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "go-wavy-store")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		os.RemoveAll(dir)
+	})
+	return dir
+}
+
+func TestNewBoltStore(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) store.HistoryStore {
+		s, err := store.NewBoltStore(filepath.Join(tempDir(t), "history.db"))
+		require.NoError(t, err)
+		return s
+	})
+}
+
+func TestNewBoltStore_reopen(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(tempDir(t), "history.db")
+	uri := wavy.UserURI{Username: "OGKevin"}
+
+	s, err := store.NewBoltStore(path)
+	require.NoError(t, err)
+	_, err = s.Append(ctx, uri, storetest.Item("a", 1), storetest.Item("b", 2))
+	require.NoError(t, err)
+	require.NoError(t, s.Close())
+
+	s, err = store.NewBoltStore(path)
+	require.NoError(t, err)
+	defer s.Close()
+
+	added, err := s.Append(ctx, uri, storetest.Item("b", 2))
+	require.NoError(t, err)
+	assert.Equal(t, 0, added)
+
+	items, err := s.Range(ctx, uri, time.Time{}, time.Time{})
+	require.NoError(t, err)
+	assert.Len(t, items, 2)
+}
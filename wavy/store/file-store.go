@@ -0,0 +1,125 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/OGKevin/go-wavy/internal/ndjson"
+	"github.com/OGKevin/go-wavy/wavy"
+)
+
+type fileStore struct {
+	dir string
+
+	mu    sync.Mutex
+	users map[string]*memoryHistory
+}
+
+// NewFileStore returns a HistoryStore which writes every user's history as newline-delimited JSON
+// to its own file inside dir. The directory is created if it does not exist yet.
+//
+// A user's file is read into memory the first time the user is accessed, after which appends only
+// write the new lines.
+func NewFileStore(dir string) (HistoryStore, error) {
+	err := os.MkdirAll(dir, 0700)
+	if err != nil {
+		return nil, fmt.Errorf("store: failed to create directory %q: %w", dir, err)
+	}
+
+	return &fileStore{
+		dir:   dir,
+		users: make(map[string]*memoryHistory),
+	}, nil
+}
+
+func (f *fileStore) path(uri wavy.UserURI) string {
+	return filepath.Join(f.dir, url.QueryEscape(userKey(uri))+".ndjson")
+}
+
+// load returns the history of the user, reading it from disk if needed. f.mu must be held.
+func (f *fileStore) load(uri wavy.UserURI) (*memoryHistory, error) {
+	if h, ok := f.users[userKey(uri)]; ok {
+		return h, nil
+	}
+
+	h := &memoryHistory{plays: make(map[string]struct{})}
+
+	var items []wavy.Item
+	err := ndjson.Read(f.path(uri), func(item wavy.Item) {
+		items = append(items, item)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %q: %w", f.path(uri), err)
+	}
+
+	h.append(items)
+	f.users[userKey(uri)] = h
+
+	return h, nil
+}
+
+func (f *fileStore) Append(ctx context.Context, uri wavy.UserURI, items ...wavy.Item) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	h, err := f.load(uri)
+	if err != nil {
+		return 0, fmt.Errorf("store: failed to load history for %q: %w", userKey(uri), err)
+	}
+
+	var fresh []wavy.Item
+	seen := make(map[string]struct{})
+	for _, item := range items {
+		if _, ok := h.plays[item.PlayID]; ok {
+			continue
+		}
+		if _, ok := seen[item.PlayID]; ok {
+			continue
+		}
+		seen[item.PlayID] = struct{}{}
+		fresh = append(fresh, item)
+	}
+	if len(fresh) == 0 {
+		return 0, nil
+	}
+
+	// Write to disk before updating the in memory copy so both stay in sync when writing fails.
+	if err := ndjson.Append(f.path(uri), fresh...); err != nil {
+		return 0, fmt.Errorf("store: failed to write history for %q: %w", userKey(uri), err)
+	}
+
+	return h.append(fresh), nil
+}
+
+func (f *fileStore) Checkpoint(ctx context.Context, uri wavy.UserURI) (*Checkpoint, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	h, err := f.load(uri)
+	if err != nil {
+		return nil, fmt.Errorf("store: failed to load history for %q: %w", userKey(uri), err)
+	}
+
+	return h.checkpoint()
+}
+
+func (f *fileStore) Range(ctx context.Context, uri wavy.UserURI, from, to time.Time) ([]wavy.Item, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	h, err := f.load(uri)
+	if err != nil {
+		return nil, fmt.Errorf("store: failed to load history for %q: %w", userKey(uri), err)
+	}
+
+	return h.between(from, to), nil
+}
+
+func (f *fileStore) Close() error {
+	return nil
+}
@@ -0,0 +1,53 @@
+package store_test
+
+import (
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/OGKevin/go-wavy/wavy"
+	"github.com/OGKevin/go-wavy/wavy/store"
+	"github.com/OGKevin/go-wavy/wavy/store/storetest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewFileStore(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) store.HistoryStore {
+		s, err := store.NewFileStore(tempDir(t))
+		require.NoError(t, err)
+		return s
+	})
+}
+
+func TestNewFileStore_reopen(t *testing.T) {
+	ctx := context.Background()
+	dir := tempDir(t)
+	uri := wavy.UserURI{Username: "OGKevin"}
+
+	s, err := store.NewFileStore(dir)
+	require.NoError(t, err)
+	_, err = s.Append(ctx, uri, storetest.Item("a", 1), storetest.Item("b", 2))
+	require.NoError(t, err)
+	require.NoError(t, s.Close())
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.ndjson"))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	raw, err := ioutil.ReadFile(files[0])
+	require.NoError(t, err)
+	assert.Contains(t, string(raw), `"play_id":"a"`)
+
+	s, err = store.NewFileStore(dir)
+	require.NoError(t, err)
+	defer s.Close()
+
+	checkpoint, err := s.Checkpoint(ctx, uri)
+	require.NoError(t, err)
+	assert.Equal(t, "b", checkpoint.PlayID)
+
+	added, err := s.Append(ctx, uri, storetest.Item("a", 1), storetest.Item("c", 3))
+	require.NoError(t, err)
+	assert.Equal(t, 1, added)
+}
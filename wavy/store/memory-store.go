@@ -0,0 +1,118 @@
+package store
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/OGKevin/go-wavy/wavy"
+)
+
+type memoryStore struct {
+	mu    sync.RWMutex
+	users map[string]*memoryHistory
+}
+
+type memoryHistory struct {
+	// items is kept sorted by date and PlayID ascending.
+	items []wavy.Item
+	plays map[string]struct{}
+}
+
+// NewMemoryStore returns a HistoryStore which keeps all history in memory.
+// Its contents are lost once the process exits.
+func NewMemoryStore() HistoryStore {
+	return &memoryStore{
+		users: make(map[string]*memoryHistory),
+	}
+}
+
+func (m *memoryStore) Append(ctx context.Context, uri wavy.UserURI, items ...wavy.Item) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	h, ok := m.users[userKey(uri)]
+	if !ok {
+		h = &memoryHistory{plays: make(map[string]struct{})}
+		m.users[userKey(uri)] = h
+	}
+
+	return h.append(items), nil
+}
+
+func (h *memoryHistory) append(items []wavy.Item) int {
+	added := 0
+	for _, item := range items {
+		if _, ok := h.plays[item.PlayID]; ok {
+			continue
+		}
+		h.plays[item.PlayID] = struct{}{}
+
+		i := sort.Search(len(h.items), func(i int) bool {
+			return itemAfter(h.items[i], item)
+		})
+		h.items = append(h.items, wavy.Item{})
+		copy(h.items[i+1:], h.items[i:])
+		h.items[i] = item
+		added++
+	}
+
+	return added
+}
+
+// itemAfter reports whether a sorts after b: by date, and by PlayID for listens of the same date.
+func itemAfter(a, b wavy.Item) bool {
+	if !a.Date.Equal(b.Date) {
+		return a.Date.After(b.Date)
+	}
+	return a.PlayID > b.PlayID
+}
+
+func (m *memoryStore) Checkpoint(ctx context.Context, uri wavy.UserURI) (*Checkpoint, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	h, ok := m.users[userKey(uri)]
+	if !ok {
+		return nil, ErrNoCheckpoint
+	}
+
+	return h.checkpoint()
+}
+
+func (h *memoryHistory) checkpoint() (*Checkpoint, error) {
+	if len(h.items) == 0 {
+		return nil, ErrNoCheckpoint
+	}
+
+	last := h.items[len(h.items)-1]
+	return &Checkpoint{PlayID: last.PlayID, Date: last.Date}, nil
+}
+
+func (m *memoryStore) Range(ctx context.Context, uri wavy.UserURI, from, to time.Time) ([]wavy.Item, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	h, ok := m.users[userKey(uri)]
+	if !ok {
+		return nil, nil
+	}
+
+	return h.between(from, to), nil
+}
+
+func (h *memoryHistory) between(from, to time.Time) []wavy.Item {
+	var items []wavy.Item
+	for _, item := range h.items {
+		if inRange(item.Date, from, to) {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
+
+func (m *memoryStore) Close() error {
+	return nil
+}
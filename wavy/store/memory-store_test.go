@@ -0,0 +1,14 @@
+package store_test
+
+import (
+	"testing"
+
+	"github.com/OGKevin/go-wavy/wavy/store"
+	"github.com/OGKevin/go-wavy/wavy/store/storetest"
+)
+
+func TestNewMemoryStore(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) store.HistoryStore {
+		return store.NewMemoryStore()
+	})
+}
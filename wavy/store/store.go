@@ -0,0 +1,59 @@
+// Package store provides pluggable storage for synced wavy.fm listening history.
+//
+// A HistoryStore keeps the listens of one or more users, keyed by their UserURI.
+// Items are appended idempotently by PlayID, so syncing the same page of
+// history twice never produces duplicates.
+package store
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/OGKevin/go-wavy/wavy"
+)
+
+// ErrNoCheckpoint is returned by HistoryStore.Checkpoint when nothing has been stored for a user yet.
+var ErrNoCheckpoint = errors.New("store: no checkpoint for user")
+
+// HistoryStore
+// This interface marks the contract for persisting synced history. Implementations must be safe for concurrent use.
+// The storetest package contains a conformance suite which any implementation can run.
+type HistoryStore interface {
+	// Append
+	// Stores the given items for the user. Items with a PlayID that is already stored for the user are ignored.
+	// Returns the amount of items that were newly stored.
+	Append(ctx context.Context, uri wavy.UserURI, items ...wavy.Item) (int, error)
+	// Checkpoint
+	// Returns the most recent item stored for the user, the one with the greatest PlayID among items of the same
+	// date. ErrNoCheckpoint is returned when nothing is stored yet.
+	Checkpoint(ctx context.Context, uri wavy.UserURI) (*Checkpoint, error)
+	// Range
+	// Returns the items of the user with a date in [from, to), ordered by date and then PlayID ascending.
+	// A zero from or to leaves that side of the range open.
+	Range(ctx context.Context, uri wavy.UserURI, from, to time.Time) ([]wavy.Item, error)
+	// Close releases any resources held by the store.
+	Close() error
+}
+
+// Checkpoint marks the most recent listen stored for a user.
+type Checkpoint struct {
+	PlayID string    `json:"play_id"`
+	Date   time.Time `json:"date"`
+}
+
+// inRange reports whether t lies in [from, to), treating zero bounds as open.
+func inRange(t, from, to time.Time) bool {
+	if !from.IsZero() && t.Before(from) {
+		return false
+	}
+	if !to.IsZero() && !t.Before(to) {
+		return false
+	}
+	return true
+}
+
+// userKey returns the key under which a user's history is stored.
+func userKey(uri wavy.UserURI) string {
+	return uri.String()
+}
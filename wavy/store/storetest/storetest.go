@@ -0,0 +1,256 @@
+// Package storetest provides a conformance suite for store.HistoryStore implementations.
+//
+// Third party implementations can run it from their own tests:
+//
+//	func TestMyStore(t *testing.T) {
+//		storetest.Run(t, func(t *testing.T) store.HistoryStore {
+//			return NewMyStore()
+//		})
+//	}
+package storetest
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/OGKevin/go-wavy/wavy"
+	"github.com/OGKevin/go-wavy/wavy/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Factory returns a new, empty store for a single sub test. The suite closes the store when the sub test is done.
+type Factory func(t *testing.T) store.HistoryStore
+
+var (
+	userA = wavy.UserURI{Username: "OGKevin"}
+	userB = wavy.UserURI{UserID: "5c7ec4f2-2c6e-4d7d-9b1b-8f1f1a7c0e2d"}
+
+	epoch = time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC)
+)
+
+// Item returns a history item played minutes after a fixed point in time.
+func Item(playID string, minutes int) wavy.Item {
+	return wavy.Item{
+		CurrentPlayingItem: wavy.CurrentPlayingItem{
+			Song:    wavy.Song{Source: "spotify", Name: fmt.Sprintf("Song %s", playID)},
+			Album:   wavy.Album{Source: "spotify", Name: "Album"},
+			Artists: []wavy.Artists{{Source: "spotify", Name: "Artist"}},
+		},
+		Date:   epoch.Add(time.Duration(minutes) * time.Minute),
+		PlayID: playID,
+	}
+}
+
+// Run runs the conformance suite against the stores returned by newStore.
+func Run(t *testing.T, newStore Factory) {
+	tests := []struct {
+		name string
+		test func(t *testing.T, s store.HistoryStore)
+	}{
+		{name: "empty checkpoint", test: testEmptyCheckpoint},
+		{name: "append", test: testAppend},
+		{name: "append is idempotent", test: testAppendIdempotent},
+		{name: "checkpoint", test: testCheckpoint},
+		{name: "range", test: testRange},
+		{name: "old dates", test: testOldDates},
+		{name: "same dates", test: testSameDates},
+		{name: "users are isolated", test: testUsersIsolated},
+		{name: "concurrent appends", test: testConcurrentAppend},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newStore(t)
+			defer func() {
+				assert.NoError(t, s.Close())
+			}()
+
+			tt.test(t, s)
+		})
+	}
+}
+
+func playIDs(items []wavy.Item) []string {
+	ids := make([]string, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.PlayID)
+	}
+	return ids
+}
+
+func testEmptyCheckpoint(t *testing.T, s store.HistoryStore) {
+	_, err := s.Checkpoint(context.Background(), userA)
+	assert.Equal(t, store.ErrNoCheckpoint, err)
+
+	items, err := s.Range(context.Background(), userA, time.Time{}, time.Time{})
+	require.NoError(t, err)
+	assert.Empty(t, items)
+}
+
+func testAppend(t *testing.T, s store.HistoryStore) {
+	ctx := context.Background()
+
+	added, err := s.Append(ctx, userA, Item("b", 2), Item("a", 1), Item("c", 3))
+	require.NoError(t, err)
+	assert.Equal(t, 3, added)
+
+	items, err := s.Range(ctx, userA, time.Time{}, time.Time{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b", "c"}, playIDs(items))
+
+	want := Item("a", 1)
+	assert.True(t, want.Date.Equal(items[0].Date))
+	assert.Equal(t, want.Song, items[0].Song)
+	assert.Equal(t, want.Album, items[0].Album)
+	assert.Equal(t, want.Artists, items[0].Artists)
+}
+
+func testAppendIdempotent(t *testing.T, s store.HistoryStore) {
+	ctx := context.Background()
+
+	added, err := s.Append(ctx, userA, Item("a", 1), Item("b", 2))
+	require.NoError(t, err)
+	assert.Equal(t, 2, added)
+
+	added, err = s.Append(ctx, userA, Item("a", 1), Item("b", 2), Item("c", 3), Item("c", 3))
+	require.NoError(t, err)
+	assert.Equal(t, 1, added)
+
+	added, err = s.Append(ctx, userA)
+	require.NoError(t, err)
+	assert.Equal(t, 0, added)
+
+	items, err := s.Range(ctx, userA, time.Time{}, time.Time{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b", "c"}, playIDs(items))
+}
+
+func testCheckpoint(t *testing.T, s store.HistoryStore) {
+	ctx := context.Background()
+
+	_, err := s.Append(ctx, userA, Item("b", 2), Item("c", 3))
+	require.NoError(t, err)
+	// An older item synced late must not move the checkpoint back.
+	_, err = s.Append(ctx, userA, Item("a", 1))
+	require.NoError(t, err)
+
+	checkpoint, err := s.Checkpoint(ctx, userA)
+	require.NoError(t, err)
+	assert.Equal(t, "c", checkpoint.PlayID)
+	assert.True(t, Item("c", 3).Date.Equal(checkpoint.Date))
+}
+
+func testOldDates(t *testing.T, s store.HistoryStore) {
+	ctx := context.Background()
+
+	zero := Item("zero", 0)
+	zero.Date = time.Time{}
+	old := Item("old", 0)
+	old.Date = time.Date(1969, 7, 20, 20, 17, 0, 0, time.UTC)
+
+	_, err := s.Append(ctx, userA, Item("a", 1), old, zero)
+	require.NoError(t, err)
+
+	items, err := s.Range(ctx, userA, time.Time{}, time.Time{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"zero", "old", "a"}, playIDs(items))
+
+	items, err = s.Range(ctx, userA, old.Date, epoch)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"old"}, playIDs(items))
+
+	checkpoint, err := s.Checkpoint(ctx, userA)
+	require.NoError(t, err)
+	assert.Equal(t, "a", checkpoint.PlayID)
+}
+
+func testSameDates(t *testing.T, s store.HistoryStore) {
+	ctx := context.Background()
+
+	// Listens of the same date are ordered by PlayID, whatever order they are appended in.
+	_, err := s.Append(ctx, userA, Item("b", 1), Item("z", 0))
+	require.NoError(t, err)
+	_, err = s.Append(ctx, userA, Item("c", 1), Item("a", 1))
+	require.NoError(t, err)
+
+	items, err := s.Range(ctx, userA, time.Time{}, time.Time{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"z", "a", "b", "c"}, playIDs(items))
+
+	items, err = s.Range(ctx, userA, Item("", 1).Date, time.Time{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b", "c"}, playIDs(items))
+
+	checkpoint, err := s.Checkpoint(ctx, userA)
+	require.NoError(t, err)
+	assert.Equal(t, "c", checkpoint.PlayID)
+}
+
+func testRange(t *testing.T, s store.HistoryStore) {
+	ctx := context.Background()
+
+	_, err := s.Append(ctx, userA, Item("a", 1), Item("b", 2), Item("c", 3), Item("d", 4))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		from, to time.Time
+		want     []string
+	}{
+		{name: "open", want: []string{"a", "b", "c", "d"}},
+		{name: "from is inclusive", from: Item("", 2).Date, want: []string{"b", "c", "d"}},
+		{name: "to is exclusive", to: Item("", 3).Date, want: []string{"a", "b"}},
+		{name: "bounded", from: Item("", 2).Date, to: Item("", 4).Date, want: []string{"b", "c"}},
+		{name: "empty", from: Item("", 10).Date, want: []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			items, err := s.Range(ctx, userA, tt.from, tt.to)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, playIDs(items))
+		})
+	}
+}
+
+func testUsersIsolated(t *testing.T, s store.HistoryStore) {
+	ctx := context.Background()
+
+	_, err := s.Append(ctx, userA, Item("a", 1))
+	require.NoError(t, err)
+	// The same PlayID for another user is a separate listen.
+	added, err := s.Append(ctx, userB, Item("a", 1), Item("b", 2))
+	require.NoError(t, err)
+	assert.Equal(t, 2, added)
+
+	items, err := s.Range(ctx, userA, time.Time{}, time.Time{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a"}, playIDs(items))
+
+	checkpoint, err := s.Checkpoint(ctx, userB)
+	require.NoError(t, err)
+	assert.Equal(t, "b", checkpoint.PlayID)
+}
+
+func testConcurrentAppend(t *testing.T, s store.HistoryStore) {
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	for worker := 0; worker < 4; worker++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 25; i++ {
+				_, err := s.Append(ctx, userA, Item(fmt.Sprintf("%03d", i), i))
+				assert.NoError(t, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	items, err := s.Range(ctx, userA, time.Time{}, time.Time{})
+	require.NoError(t, err)
+	assert.Len(t, items, 25)
+}
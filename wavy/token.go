@@ -0,0 +1,57 @@
+package wavy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"golang.org/x/oauth2"
+)
+
+// TokenError is returned when the client failed to obtain an access token, before wavy.fm was asked for
+// the requested resource. Unlike an ApiError, it concerns the credentials of the client or the token endpoint.
+type TokenError struct {
+	// StatusCode is the status of the token endpoint, it is 0 when the token endpoint did not respond.
+	StatusCode int
+	Err        error
+}
+
+func (e *TokenError) Error() string {
+	return fmt.Sprintf("failed to obtain access token: %s", e.Err)
+}
+
+func (e *TokenError) Unwrap() error {
+	return e.Err
+}
+
+// tokenErrorSource turns the errors of src into TokenErrors.
+type tokenErrorSource struct {
+	src oauth2.TokenSource
+}
+
+func (s *tokenErrorSource) Token() (*oauth2.Token, error) {
+	token, err := s.src.Token()
+	if err != nil {
+		tokenErr := &TokenError{Err: err}
+
+		var retrieveErr *oauth2.RetrieveError
+		if errors.As(err, &retrieveErr) && retrieveErr.Response != nil {
+			tokenErr.StatusCode = retrieveErr.Response.StatusCode
+		}
+		return nil, tokenErr
+	}
+	return token, nil
+}
+
+// tokenSourceFunc fetches a new token on every call.
+type tokenSourceFunc func() (*oauth2.Token, error)
+
+func (f tokenSourceFunc) Token() (*oauth2.Token, error) {
+	return f()
+}
+
+// newTokenClient returns a http.Client authorizing its requests with the tokens of src.
+func newTokenClient(ctx context.Context, src oauth2.TokenSource) *http.Client {
+	return oauth2.NewClient(ctx, &tokenErrorSource{src: src})
+}
@@ -0,0 +1,75 @@
+package wavy
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/OGKevin/go-wavy/wavy/auth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTokenServer serves the total listens metric, guarded by a client credentials token endpoint
+// which responds with status when it is not 200.
+func newTokenServer(t *testing.T, status int, tokens *int32) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1beta/token", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(tokens, 1)
+		w.Header().Set("Content-Type", "application/json")
+		if status != http.StatusOK {
+			w.WriteHeader(status)
+			w.Write([]byte(`{"error": "invalid_client"}`))
+			return
+		}
+		w.Write([]byte(`{"access_token": "app-token", "token_type": "Bearer", "expires_in": 3600}`))
+	})
+	mux.HandleFunc("/api/v1beta/metrics/total-listens", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer app-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"status": 401, "name": "Unauthorized"}`))
+			return
+		}
+		w.Write([]byte("42"))
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestWithTokenCache(t *testing.T) {
+	var tokens int32
+	server := newTokenServer(t, http.StatusOK, &tokens)
+	cache := auth.NewMemoryCache()
+
+	for i := 0; i < 3; i++ {
+		c := NewClient(context.Background(), NopLogger(), "id", "secret",
+			WithBaseURL(server.URL+"/api/v1beta"), WithTokenCache(cache))
+
+		got, err := c.MetricsService().GetTotalListens(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, int64(42), got.TotalListens)
+	}
+
+	assert.Equal(t, int32(1), tokens)
+}
+
+func TestTokenError(t *testing.T) {
+	var tokens int32
+	server := newTokenServer(t, http.StatusUnauthorized, &tokens)
+
+	c := NewClient(context.Background(), NopLogger(), "id", "wrong",
+		WithBaseURL(server.URL+"/api/v1beta"))
+	_, err := c.MetricsService().GetTotalListens(context.Background())
+
+	var tokenErr *TokenError
+	require.True(t, errors.As(err, &tokenErr), err.Error())
+	assert.Equal(t, http.StatusUnauthorized, tokenErr.StatusCode)
+
+	var apiErr *ApiError
+	assert.False(t, errors.As(err, &apiErr))
+}
@@ -0,0 +1,29 @@
+package wavy
+
+import (
+	"net/url"
+	"strings"
+)
+
+// joinURL resolves ref, an escaped path with an optional query, against base. The path of base, such as
+// /api/v1beta, is kept as prefix of the path of ref, and the queries of both are merged.
+func joinURL(base string, ref *url.URL) (*url.URL, error) {
+	u, err := url.Parse(base)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := strings.TrimSuffix(u.EscapedPath(), "/")
+	u.Path = strings.TrimSuffix(u.Path, "/") + ref.Path
+	u.RawPath = prefix + ref.EscapedPath()
+
+	query := u.Query()
+	for key, values := range ref.Query() {
+		for _, value := range values {
+			query.Add(key, value)
+		}
+	}
+	u.RawQuery = query.Encode()
+
+	return u, nil
+}
@@ -0,0 +1,60 @@
+package wavy
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_joinURL(t *testing.T) {
+	tests := []struct {
+		name string
+		base string
+		ref  string
+		want string
+	}{
+		{name: "prefix", base: "https://wavy.fm/api/v1beta", ref: "/metrics/total-users", want: "https://wavy.fm/api/v1beta/metrics/total-users"},
+		{name: "trailing slash", base: "https://wavy.fm/api/v1beta/", ref: "/metrics/total-users", want: "https://wavy.fm/api/v1beta/metrics/total-users"},
+		{name: "no prefix", base: "http://127.0.0.1:8080", ref: "/metrics/total-users", want: "http://127.0.0.1:8080/metrics/total-users"},
+		{name: "escaped", base: "https://wavy.fm/api/v1beta", ref: "/users/wavyfm:user:username:a%2Fb/history", want: "https://wavy.fm/api/v1beta/users/wavyfm:user:username:a%2Fb/history"},
+		{name: "escaped prefix", base: "https://proxy.example/wavy%2Fapi", ref: "/users/wavyfm:user:username:a%20b", want: "https://proxy.example/wavy%2Fapi/users/wavyfm:user:username:a%20b"},
+		{name: "query", base: "https://wavy.fm/api/v1beta", ref: "/users/x/history/recent?limit=5", want: "https://wavy.fm/api/v1beta/users/x/history/recent?limit=5"},
+		{name: "merged query", base: "https://proxy.example/wavy?key=k", ref: "/metrics/total-users?a=b%26c", want: "https://proxy.example/wavy/metrics/total-users?a=b%26c&key=k"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ref, err := url.Parse(tt.ref)
+			require.NoError(t, err)
+
+			got, err := joinURL(tt.base, ref)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got.String())
+		})
+	}
+}
+
+func TestGetProfile_trickyUsernames(t *testing.T) {
+	usernames := []string{"a/b", "a?b", "a#b", "a b", "a%2Fb", "a+b", "..", "ünïcødé", "a;b,c"}
+
+	var got []string
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = append(got, r.URL.Path)
+		w.Write([]byte(`{}`))
+	}))
+	c.baseURL += "/api/v1beta/"
+
+	for _, username := range usernames {
+		_, err := newUserService(c, c.logger).GetProfile(context.Background(), UserURI{Username: username})
+		require.NoError(t, err, username)
+	}
+
+	want := make([]string, len(usernames))
+	for i, username := range usernames {
+		want[i] = "/api/v1beta/users/wavyfm:user:username:" + username
+	}
+	assert.Equal(t, want, got)
+}
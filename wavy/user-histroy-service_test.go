@@ -2,11 +2,9 @@ package wavy
 
 import (
 	"context"
-	"os"
 	"testing"
 	"time"
 
-	"github.com/hashicorp/go-hclog"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -14,7 +12,7 @@ func Test_userHistroyService_GetStats(t *testing.T) {
 	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
 	defer cancel()
 
-	c := NewClient(ctx, hclog.NewNullLogger(), os.Getenv("CLIENT_ID"), os.Getenv("CLIENT_SECRET"))
+	c := newRecordedClient(t, ctx)
 
 	type args struct {
 		ctx     context.Context
@@ -55,7 +53,7 @@ func Test_userHistroyService_GetCurrent(t *testing.T) {
 	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
 	defer cancel()
 
-	c := NewClient(ctx, hclog.NewNullLogger(), os.Getenv("CLIENT_ID"), os.Getenv("CLIENT_SECRET"))
+	c := newRecordedClient(t, ctx)
 
 	type args struct {
 		ctx     context.Context
@@ -94,7 +92,7 @@ func Test_userHistroyService_GetRecent(t *testing.T) {
 	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
 	defer cancel()
 
-	c := NewClient(ctx, hclog.NewNullLogger(), os.Getenv("CLIENT_ID"), os.Getenv("CLIENT_SECRET"))
+	c := newRecordedClient(t, ctx)
 
 	type args struct {
 		ctx     context.Context
@@ -2,30 +2,31 @@ package wavy
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
+	"image"
 	"strings"
-	"time"
-
-	"github.com/hashicorp/go-hclog"
 )
 
 // UserService Reference for accessing public user profiles.
 // https://wavy.fm/developers/docs/v1beta/users
 type UserService interface {
-	// GetProfile
-	// Retrieves the public profile of a wavy.fm user. Note that private profiles will not be returned at all by this endpoint, regardless of authorization scopes.
-	GetProfile(ctx context.Context, uri UserURI) (*GetUserProfileResponse, error)
+	userServiceOperations
 	// HistroyService this service gives access to the /history endpoints
 	HistroyService(uri UserURI) UserHistoryService
+	// GetAvatar
+	// Fetches the avatar of profile, scaled and cropped to size x size pixels. A size of 0 returns the avatar as served.
+	// The request goes through the transport and rate limit of the client, without its token, headers or retry policy.
+	// Avatars are cached by the client, see WithAvatarCache. Sizes above MaxAvatarSize are rejected.
+	// The returned image is not shared with the cache, so callers may modify it.
+	GetAvatar(ctx context.Context, profile Profile, size int, opts ...CallOption) (image.Image, error)
 }
 
 type userService struct {
 	c      *client
-	logger hclog.Logger
+	logger Logger
 }
 
-func newUserService(c *client, logger hclog.Logger) UserService {
+func newUserService(c *client, logger Logger) UserService {
 	subLogger := logger.Named("user-service")
 
 	return &userService{
@@ -36,22 +37,9 @@ func newUserService(c *client, logger hclog.Logger) UserService {
 
 // GetProfile
 // Retrieves the public profile of a wavy.fm user. Note that private profiles will not be returned at all by this endpoint, regardless of authorization scopes.
-func (u *userService) GetProfile(ctx context.Context, uri UserURI) (*GetUserProfileResponse, error) {
-	u.logger.Trace("fetching user profile")
-	defer u.logger.Trace("finished fetching user profile")
-
-	res, err := u.c.get(fmt.Sprintf("/users/%s", uri.String()))
-	if err != nil {
-		return nil, fmt.Errorf("%s: failed to get user profile for %q: %w", u.logger.Name(), uri.String(), err)
-	}
-
-	var userProfile GetUserProfileResponse
-	err = json.NewDecoder(res.Body).Decode(&userProfile)
-	if err != nil {
-		return nil, fmt.Errorf("%s: failed to parse response body of user profile: %w", u.logger.Name(), err)
-	}
-
-	return &userProfile, nil
+func (u *userService) GetProfile(ctx context.Context, uri UserURI, opts ...CallOption) (*GetUserProfileResponse, error) {
+	profile, _, err := getUserProfile(ctx, u.c, u.logger, uri, u.c.callOptions(opts))
+	return profile, err
 }
 
 func (u *userService) HistroyService(uri UserURI) UserHistoryService {
@@ -66,11 +54,12 @@ type UserURI struct {
 	DiscordID string
 }
 
+// UnmarshalBinary parses a uri such as wavyfm:user:username:OGKevin. The identifier may contain colons.
 func (u *UserURI) UnmarshalBinary(data []byte) error {
 	dataString := string(data)
-	pieces := strings.Split(dataString, ":")
+	pieces := strings.SplitN(dataString, ":", 4)
 
-	if len(pieces) < 3 {
+	if len(pieces) < 4 || pieces[0] != "wavyfm" || pieces[1] != "user" || pieces[3] == "" {
 		return fmt.Errorf("failed to parse UserURI: %q", dataString)
 	}
 
@@ -114,32 +103,22 @@ func ParseUserURI(uri string) (*UserURI, error) {
 	return r, nil
 }
 
-type GetUserProfileResponse struct {
-	URI      string    `json:"uri"`
-	ID       string    `json:"id"`
-	Username string    `json:"username"`
-	JoinTime time.Time `json:"join_time"`
-	Profile  Profile   `json:"profile"`
-}
-
-type Spotify struct {
-	ID          string `json:"id"`
-	DisplayName string `json:"display_name"`
-}
-
-type Discord struct {
-	ID          string `json:"id"`
-	DisplayName string `json:"display_name"`
-}
+// ParseUserURIs parses a comma separated list of uris, such as the value of a command line flag.
+// Empty entries are skipped, so an empty list returns no uris.
+func ParseUserURIs(list string) ([]UserURI, error) {
+	var uris []UserURI
+	for _, raw := range strings.Split(list, ",") {
+		raw = strings.TrimSpace(raw)
+		if raw == "" {
+			continue
+		}
+
+		uri, err := ParseUserURI(raw)
+		if err != nil {
+			return nil, err
+		}
+		uris = append(uris, *uri)
+	}
 
-type Profile struct {
-	URL         string  `json:"url"`
-	Avatar      string  `json:"avatar"`
-	AvatarSmall string  `json:"avatar_small"`
-	Country     string  `json:"country"`
-	Biography   string  `json:"biography"`
-	Twitter     string  `json:"twitter"`
-	Instagram   string  `json:"instagram"`
-	Spotify     Spotify `json:"spotify"`
-	Discord     Discord `json:"discord"`
+	return uris, nil
 }
@@ -2,19 +2,18 @@ package wavy
 
 import (
 	"context"
-	"os"
 	"testing"
 	"time"
 
-	"github.com/hashicorp/go-hclog"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func Test_userService_GetProfile(t *testing.T) {
 	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
 	defer cancel()
 
-	c := NewClient(ctx, hclog.NewNullLogger(), os.Getenv("CLIENT_ID"), os.Getenv("CLIENT_SECRET"))
+	c := newRecordedClient(t, ctx)
 
 	type args struct {
 		ctx     context.Context
@@ -66,6 +65,34 @@ func Test_userService_ParseUserURI(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "Missing value",
+			args: args{
+				uri: "wavyfm:user:username",
+			},
+			wantErr: true,
+		},
+		{
+			name: "Empty value",
+			args: args{
+				uri: "wavyfm:user:username:",
+			},
+			wantErr: true,
+		},
+		{
+			name: "Other scheme",
+			args: args{
+				uri: "spotify:user:username:User",
+			},
+			wantErr: true,
+		},
+		{
+			name: "Other kind",
+			args: args{
+				uri: "wavyfm:artist:id:uuid",
+			},
+			wantErr: true,
+		},
 		{
 			name: "Username",
 			args: args{
@@ -73,6 +100,13 @@ func Test_userService_ParseUserURI(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "Id with colons",
+			args: args{
+				uri: "wavyfm:user:id:a:b:c",
+			},
+			wantErr: false,
+		},
 		{
 			name: "Discord",
 			args: args{
@@ -107,3 +141,20 @@ func Test_userService_ParseUserURI(t *testing.T) {
 		})
 	}
 }
+
+func TestParseUserURIs(t *testing.T) {
+	uris, err := ParseUserURIs(" wavyfm:user:username:OGKevin,,wavyfm:user:id:1 ,")
+	require.NoError(t, err)
+	assert.Equal(t, []UserURI{{Username: "OGKevin"}, {UserID: "1"}}, uris)
+
+	uris, err = ParseUserURIs("")
+	require.NoError(t, err)
+	assert.Empty(t, uris)
+
+	uris, err = ParseUserURIs("wavyfm:user:discord:name:1234")
+	require.NoError(t, err)
+	assert.Equal(t, []UserURI{{DiscordID: "name:1234"}}, uris)
+
+	_, err = ParseUserURIs("wavyfm:user:username:OGKevin,OGKevin")
+	assert.EqualError(t, err, `failed to parse UserURI: "OGKevin"`)
+}
@@ -0,0 +1,105 @@
+package wavy
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const wavyAPIRoot = "https://wavy.fm/api"
+
+// APIVersion is a version of the wavy.fm api, used as the first segment of every api path.
+type APIVersion string
+
+const (
+	APIVersionV1Beta APIVersion = "v1beta"
+
+	// DefaultAPIVersion is the version targeted by clients, the version of the api description in the schema
+	// package. Clients target other versions once wavy.fm publishes them and the SDK describes them.
+	DefaultAPIVersion = APIVersionV1Beta
+)
+
+// baseURL returns the url all paths of v are relative to.
+func (v APIVersion) baseURL() string {
+	return fmt.Sprintf("%s/%s", wavyAPIRoot, v)
+}
+
+// Deprecation holds the deprecation notice wavy.fm sent for an endpoint, if any.
+type Deprecation struct {
+	// Deprecated is set when the Deprecation header was sent.
+	Deprecated bool
+	// Date is when the endpoint was or will be deprecated, it is zero when wavy.fm did not send a date.
+	Date time.Time
+	// Sunset is when the endpoint will stop working, from the Sunset header.
+	Sunset time.Time
+	// Link points to documentation about the deprecation or its successor, from the Link header.
+	Link string
+}
+
+func newDeprecation(header http.Header) Deprecation {
+	var d Deprecation
+
+	if value := header.Get("Deprecation"); value != "" {
+		d.Deprecated = value != "false"
+		if date, err := http.ParseTime(value); err == nil {
+			d.Date = date
+		} else if strings.HasPrefix(value, "@") {
+			var unix int64
+			if _, err := fmt.Sscanf(value, "@%d", &unix); err == nil {
+				d.Date = time.Unix(unix, 0)
+			}
+		}
+	}
+	if sunset, err := http.ParseTime(header.Get("Sunset")); err == nil {
+		d.Sunset = sunset
+	}
+	for _, link := range header.Values("Link") {
+		if strings.Contains(link, `rel="deprecation"`) || strings.Contains(link, `rel="successor-version"`) || strings.Contains(link, `rel="sunset"`) {
+			d.Link = strings.Trim(strings.SplitN(link, ";", 2)[0], "<> ")
+			break
+		}
+	}
+
+	return d
+}
+
+// deprecationWarner logs the deprecation notices sent by wavy.fm. Every notice is logged once per route, the path
+// template of the endpoint, so polling a deprecated endpoint does not flood the log and the notices of every user
+// share an entry.
+type deprecationWarner struct {
+	mu     sync.Mutex
+	warned map[string]bool
+}
+
+func (w *deprecationWarner) warn(logger Logger, route string, d Deprecation) {
+	if !d.Deprecated && d.Sunset.IsZero() {
+		return
+	}
+
+	key := fmt.Sprintf("%s %v %v", route, d.Date, d.Sunset)
+
+	w.mu.Lock()
+	if w.warned == nil {
+		w.warned = map[string]bool{}
+	}
+	if w.warned[key] {
+		w.mu.Unlock()
+		return
+	}
+	w.warned[key] = true
+	w.mu.Unlock()
+
+	args := []interface{}{"route", route}
+	if !d.Date.IsZero() {
+		args = append(args, "deprecated_at", d.Date)
+	}
+	if !d.Sunset.IsZero() {
+		args = append(args, "sunset", d.Sunset)
+	}
+	if d.Link != "" {
+		args = append(args, "link", d.Link)
+	}
+	logger.Warn("wavy.fm reported the endpoint as deprecated", args...)
+}
@@ -0,0 +1,97 @@
+package wavy
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/OGKevin/go-wavy/wavy/schema"
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefaultAPIVersion(t *testing.T) {
+	c := NewClient(context.Background(), NopLogger(), "", "").(*client)
+	assert.Equal(t, "https://wavy.fm/api/v1beta", c.baseURL)
+	assert.Equal(t, schema.V1Beta().Info.Version, string(DefaultAPIVersion))
+}
+
+func Test_newDeprecation(t *testing.T) {
+	sunset := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		header http.Header
+		want   Deprecation
+	}{
+		{name: "none", header: http.Header{}, want: Deprecation{}},
+		{name: "true", header: http.Header{"Deprecation": {"true"}}, want: Deprecation{Deprecated: true}},
+		{
+			name:   "date",
+			header: http.Header{"Deprecation": {"Sat, 01 Jan 2022 00:00:00 GMT"}},
+			want:   Deprecation{Deprecated: true, Date: sunset},
+		},
+		{
+			name:   "unix",
+			header: http.Header{"Deprecation": {"@1640995200"}},
+			want:   Deprecation{Deprecated: true, Date: time.Unix(1640995200, 0)},
+		},
+		{
+			name: "sunset and link",
+			header: http.Header{
+				"Sunset": {"Sat, 01 Jan 2022 00:00:00 GMT"},
+				"Link":   {`<https://wavy.fm/developers/docs/v1>; rel="successor-version"`},
+			},
+			want: Deprecation{Sunset: sunset, Link: "https://wavy.fm/developers/docs/v1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := newDeprecation(tt.header)
+			assert.Equal(t, tt.want.Deprecated, got.Deprecated)
+			assert.True(t, tt.want.Date.Equal(got.Date), got.Date)
+			assert.True(t, tt.want.Sunset.Equal(got.Sunset), got.Sunset)
+			assert.Equal(t, tt.want.Link, got.Link)
+		})
+	}
+}
+
+func TestDeprecationWarning(t *testing.T) {
+	var buf bytes.Buffer
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Deprecation", "true")
+		w.Header().Set("Sunset", "Sat, 01 Jan 2022 00:00:00 GMT")
+		if strings.Contains(r.URL.Path, "/users/") {
+			w.Write([]byte("{}"))
+			return
+		}
+		w.Write([]byte("42"))
+	}))
+	c.logger = NewHCLogLogger(hclog.New(&hclog.LoggerOptions{Output: &buf, Level: hclog.Warn}))
+
+	var res Response
+	m := newMetricsService(c, c.logger)
+	for i := 0; i < 3; i++ {
+		_, err := m.GetTotalListens(context.Background(), WithResponse(&res))
+		require.NoError(t, err)
+	}
+
+	assert.True(t, res.Deprecation.Deprecated)
+	assert.Equal(t, 1, strings.Count(buf.String(), "deprecated"), buf.String())
+	assert.Contains(t, buf.String(), "route=/metrics/total-listens")
+
+	// Notices are tracked per route, not per user.
+	buf.Reset()
+	u := newUserService(c, c.logger)
+	for _, name := range []string{"a", "b", "c"} {
+		_, err := u.HistroyService(UserURI{Username: name}).GetStats(context.Background())
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 1, strings.Count(buf.String(), "deprecated"), buf.String())
+	assert.Contains(t, buf.String(), "route=/users/{user_uri}/history/stats")
+	assert.Len(t, c.deprecations.warned, 2)
+}
@@ -0,0 +1,188 @@
+// Package wavytest provides an in memory wavy.Client for usage in tests.
+package wavytest
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"net/http"
+	"sync"
+
+	"github.com/OGKevin/go-wavy/wavy"
+)
+
+// Client implements wavy.Client by serving the responses stored in its fields.
+// User specific responses are keyed by UserURI.String(). Requests for unknown users fail with a 404 ApiError.
+//
+// Fields may be changed between calls, but not while calls are in flight. Call options are ignored.
+type Client struct {
+	Profiles map[string]*wavy.GetUserProfileResponse
+	Stats    map[string]*wavy.GetHistroyStatsResponse
+	Current  map[string]*wavy.GetCurrentResponse
+	Recent   map[string]*wavy.GetRecentResponse
+	// Avatars are keyed by avatar url, see wavy.Profile.AvatarURL. They are returned as stored, without resizing.
+	Avatars map[string]image.Image
+
+	TotalListens int64
+	TotalUsers   int64
+	Leaderboard  []wavy.LeaderboardEntry
+
+	// Err is returned by every call when set.
+	Err error
+
+	mu    sync.Mutex
+	calls map[string]int
+}
+
+// NewClient returns an empty Client.
+func NewClient() *Client {
+	return &Client{
+		Profiles: make(map[string]*wavy.GetUserProfileResponse),
+		Stats:    make(map[string]*wavy.GetHistroyStatsResponse),
+		Current:  make(map[string]*wavy.GetCurrentResponse),
+		Recent:   make(map[string]*wavy.GetRecentResponse),
+		Avatars:  make(map[string]image.Image),
+	}
+}
+
+// Calls returns how often the named method was called, e.g. "GetRecent".
+func (c *Client) Calls(method string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.calls[method]
+}
+
+func (c *Client) call(method string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.calls == nil {
+		c.calls = make(map[string]int)
+	}
+	c.calls[method]++
+
+	return c.Err
+}
+
+func notFound(uri wavy.UserURI) error {
+	return &wavy.ApiError{Status: http.StatusNotFound, Code: "user_not_found", Name: "Not Found", Detail: uri.String()}
+}
+
+func (c *Client) MetricsService() wavy.MetricsService {
+	return &metricsService{c: c}
+}
+
+func (c *Client) UserService() wavy.UserService {
+	return &userService{c: c}
+}
+
+type metricsService struct {
+	c *Client
+}
+
+func (m *metricsService) GetTotalListens(ctx context.Context, opts ...wavy.CallOption) (*wavy.GetTotalListensResponse, error) {
+	if err := m.c.call("GetTotalListens"); err != nil {
+		return nil, err
+	}
+	return &wavy.GetTotalListensResponse{TotalListens: m.c.TotalListens}, nil
+}
+
+func (m *metricsService) GetTotalUsers(ctx context.Context, opts ...wavy.CallOption) (*wavy.GetTotalUsersResponse, error) {
+	if err := m.c.call("GetTotalUsers"); err != nil {
+		return nil, err
+	}
+	return &wavy.GetTotalUsersResponse{TotalUsers: m.c.TotalUsers}, nil
+}
+
+// GetUserListensLeaderboard returns the Leaderboard field, ranking entries without a rank by their position like the client does.
+func (m *metricsService) GetUserListensLeaderboard(ctx context.Context, opts ...wavy.CallOption) (*wavy.UserListensLeaderboardResponse, error) {
+	if err := m.c.call("GetUserListensLeaderboard"); err != nil {
+		return nil, err
+	}
+
+	entries := make([]wavy.LeaderboardEntry, len(m.c.Leaderboard))
+	copy(entries, m.c.Leaderboard)
+	for i := range entries {
+		if entries[i].Rank == 0 {
+			entries[i].Rank = i + 1
+		}
+	}
+
+	return &wavy.UserListensLeaderboardResponse{Entries: entries}, nil
+}
+
+type userService struct {
+	c *Client
+}
+
+func (u *userService) GetProfile(ctx context.Context, uri wavy.UserURI, opts ...wavy.CallOption) (*wavy.GetUserProfileResponse, error) {
+	if err := u.c.call("GetProfile"); err != nil {
+		return nil, err
+	}
+	res, ok := u.c.Profiles[uri.String()]
+	if !ok {
+		return nil, notFound(uri)
+	}
+	return res, nil
+}
+
+func (u *userService) GetAvatar(ctx context.Context, profile wavy.Profile, size int, opts ...wavy.CallOption) (image.Image, error) {
+	if err := u.c.call("GetAvatar"); err != nil {
+		return nil, err
+	}
+	if size < 0 || size > wavy.MaxAvatarSize {
+		return nil, fmt.Errorf("size %d is not between 0 and %d", size, wavy.MaxAvatarSize)
+	}
+	avatarURL := profile.AvatarURL(size)
+	if avatarURL == "" {
+		return nil, wavy.ErrNoAvatar
+	}
+	img, ok := u.c.Avatars[avatarURL]
+	if !ok {
+		return nil, &wavy.ApiError{Status: http.StatusNotFound, Code: "avatar_not_found", Name: "Not Found", Detail: avatarURL}
+	}
+	return img, nil
+}
+
+func (u *userService) HistroyService(uri wavy.UserURI) wavy.UserHistoryService {
+	return &historyService{c: u.c, uri: uri}
+}
+
+type historyService struct {
+	c   *Client
+	uri wavy.UserURI
+}
+
+func (h *historyService) GetStats(ctx context.Context, opts ...wavy.CallOption) (*wavy.GetHistroyStatsResponse, error) {
+	if err := h.c.call("GetStats"); err != nil {
+		return nil, err
+	}
+	res, ok := h.c.Stats[h.uri.String()]
+	if !ok {
+		return nil, notFound(h.uri)
+	}
+	return res, nil
+}
+
+func (h *historyService) GetCurrent(ctx context.Context, opts ...wavy.CallOption) (*wavy.GetCurrentResponse, error) {
+	if err := h.c.call("GetCurrent"); err != nil {
+		return nil, err
+	}
+	res, ok := h.c.Current[h.uri.String()]
+	if !ok {
+		return nil, notFound(h.uri)
+	}
+	return res, nil
+}
+
+func (h *historyService) GetRecent(ctx context.Context, opts ...wavy.CallOption) (*wavy.GetRecentResponse, error) {
+	if err := h.c.call("GetRecent"); err != nil {
+		return nil, err
+	}
+	res, ok := h.c.Recent[h.uri.String()]
+	if !ok {
+		return nil, notFound(h.uri)
+	}
+	return res, nil
+}
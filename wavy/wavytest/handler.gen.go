@@ -0,0 +1,92 @@
+// Code generated by wavy-gen from openapi-v1beta.json. DO NOT EDIT.
+
+package wavytest
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/OGKevin/go-wavy/wavy"
+)
+
+// Operations answers the operations of the wavy.fm api for NewHandler.
+type Operations interface {
+	// GetTotalListens retrieves the total amount of listens recorded on wavy.fm.
+	GetTotalListens(ctx context.Context) (int64, error)
+	// GetTotalUsers retrieves the total amount of registered users on wavy.fm.
+	GetTotalUsers(ctx context.Context) (int64, error)
+	// GetUserListensLeaderboard retrieves the leaderboard of the top 10 users by listen count.
+	GetUserListensLeaderboard(ctx context.Context) ([]wavy.LeaderboardEntry, error)
+	// GetUserProfile retrieves the public profile of a user.
+	GetUserProfile(ctx context.Context, userURI wavy.UserURI) (*wavy.GetUserProfileResponse, error)
+	// GetCurrentListen retrieves the song, album, and artist(s) the user is currently listening to.
+	GetCurrentListen(ctx context.Context, userURI wavy.UserURI) (*wavy.GetCurrentResponse, error)
+	// GetRecentListens retrieves the most recent listens recorded by the user.
+	GetRecentListens(ctx context.Context, userURI wavy.UserURI) (*wavy.GetRecentResponse, error)
+	// GetHistoryStats retrieves some statistics about the user's history.
+	GetHistoryStats(ctx context.Context, userURI wavy.UserURI) (*wavy.GetHistroyStatsResponse, error)
+}
+
+// routes returns the routes of the api answered by ops.
+func routes(ops Operations) []route {
+	return []route{
+		{
+			template: "/metrics/total-listens",
+			serve: func(r *http.Request, params map[string]string) (interface{}, error) {
+				return ops.GetTotalListens(r.Context())
+			},
+		},
+		{
+			template: "/metrics/total-users",
+			serve: func(r *http.Request, params map[string]string) (interface{}, error) {
+				return ops.GetTotalUsers(r.Context())
+			},
+		},
+		{
+			template: "/metrics/user-listens-leaderboard",
+			serve: func(r *http.Request, params map[string]string) (interface{}, error) {
+				return ops.GetUserListensLeaderboard(r.Context())
+			},
+		},
+		{
+			template: "/users/{user_uri}",
+			serve: func(r *http.Request, params map[string]string) (interface{}, error) {
+				userURI, err := parseUserURI(params["user_uri"])
+				if err != nil {
+					return nil, err
+				}
+				return ops.GetUserProfile(r.Context(), userURI)
+			},
+		},
+		{
+			template: "/users/{user_uri}/history/current",
+			serve: func(r *http.Request, params map[string]string) (interface{}, error) {
+				userURI, err := parseUserURI(params["user_uri"])
+				if err != nil {
+					return nil, err
+				}
+				return ops.GetCurrentListen(r.Context(), userURI)
+			},
+		},
+		{
+			template: "/users/{user_uri}/history/recent",
+			serve: func(r *http.Request, params map[string]string) (interface{}, error) {
+				userURI, err := parseUserURI(params["user_uri"])
+				if err != nil {
+					return nil, err
+				}
+				return ops.GetRecentListens(r.Context(), userURI)
+			},
+		},
+		{
+			template: "/users/{user_uri}/history/stats",
+			serve: func(r *http.Request, params map[string]string) (interface{}, error) {
+				userURI, err := parseUserURI(params["user_uri"])
+				if err != nil {
+					return nil, err
+				}
+				return ops.GetHistoryStats(r.Context(), userURI)
+			},
+		},
+	}
+}
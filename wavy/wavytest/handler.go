@@ -0,0 +1,169 @@
+package wavytest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/OGKevin/go-wavy/wavy"
+)
+
+//go:generate go run ../../internal/cmd/wavy-gen -schema ../schema/openapi-v1beta.json -package wavytest -out handler.gen.go
+
+// Token is the access token issued by the token endpoint of NewHandler.
+const Token = "wavytest-token"
+
+// route serves the api path template, e.g. /users/{user_uri}. params holds the unescaped path parameters.
+type route struct {
+	template string
+	serve    func(r *http.Request, params map[string]string) (interface{}, error)
+}
+
+// match returns the path parameters of path when it matches the template of r.
+func (r route) match(path string) (map[string]string, bool) {
+	template := strings.Split(strings.Trim(r.template, "/"), "/")
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	if len(template) != len(segments) {
+		return nil, false
+	}
+
+	params := map[string]string{}
+	for i, t := range template {
+		if strings.HasPrefix(t, "{") && strings.HasSuffix(t, "}") {
+			value, err := url.PathUnescape(segments[i])
+			if err != nil || value == "" {
+				return nil, false
+			}
+			params[strings.Trim(t, "{}")] = value
+			continue
+		}
+		if t != segments[i] {
+			return nil, false
+		}
+	}
+	return params, true
+}
+
+type handler struct {
+	routes []route
+}
+
+// NewHandler returns a fake wavy.fm api answering requests with ops. Serve it with httptest and point a client at it
+// WithBaseURL. The token endpoint issues Token for any credentials, without checking them.
+//
+// Errors returned by ops are served as they are when they are a *wavy.ApiError, otherwise as internal server error.
+func NewHandler(ops Operations) http.Handler {
+	return &handler{routes: routes(ops)}
+}
+
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := r.URL.EscapedPath()
+
+	if path == "/token" {
+		if r.Method != http.MethodPost {
+			writeError(w, &wavy.ApiError{Status: http.StatusMethodNotAllowed, Name: "Method Not Allowed"})
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"access_token": Token, "token_type": "Bearer", "expires_in": 3600})
+		return
+	}
+
+	for _, route := range h.routes {
+		params, ok := route.match(path)
+		if !ok {
+			continue
+		}
+		if r.Method != http.MethodGet {
+			writeError(w, &wavy.ApiError{Status: http.StatusMethodNotAllowed, Name: "Method Not Allowed"})
+			return
+		}
+
+		v, err := route.serve(r, params)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, v)
+		return
+	}
+
+	writeError(w, &wavy.ApiError{Status: http.StatusNotFound, Code: "not_found", Name: "Not Found", Detail: path})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	var apiErr *wavy.ApiError
+	if !errors.As(err, &apiErr) {
+		apiErr = &wavy.ApiError{Status: http.StatusInternalServerError, Name: "Internal Server Error", Detail: err.Error()}
+	}
+	writeJSON(w, apiErr.Status, apiErr)
+}
+
+// parseUserURI parses a user uri path parameter, failing with a 400 ApiError.
+func parseUserURI(uri string) (wavy.UserURI, error) {
+	parsed, err := wavy.ParseUserURI(uri)
+	if err != nil {
+		return wavy.UserURI{}, &wavy.ApiError{Status: http.StatusBadRequest, Code: "invalid_user_uri", Name: "Bad Request", Detail: fmt.Sprintf("%s: %s", uri, err)}
+	}
+	return *parsed, nil
+}
+
+// Handler returns a fake wavy.fm api serving the responses of c, see NewHandler. Calls are counted like calls
+// made through c directly.
+func (c *Client) Handler() http.Handler {
+	return NewHandler(clientOperations{c: c})
+}
+
+// clientOperations answers the operations of the api with a Client.
+type clientOperations struct {
+	c *Client
+}
+
+func (o clientOperations) GetTotalListens(ctx context.Context) (int64, error) {
+	res, err := o.c.MetricsService().GetTotalListens(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return res.TotalListens, nil
+}
+
+func (o clientOperations) GetTotalUsers(ctx context.Context) (int64, error) {
+	res, err := o.c.MetricsService().GetTotalUsers(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return res.TotalUsers, nil
+}
+
+func (o clientOperations) GetUserListensLeaderboard(ctx context.Context) ([]wavy.LeaderboardEntry, error) {
+	res, err := o.c.MetricsService().GetUserListensLeaderboard(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return res.Entries, nil
+}
+
+func (o clientOperations) GetUserProfile(ctx context.Context, userURI wavy.UserURI) (*wavy.GetUserProfileResponse, error) {
+	return o.c.UserService().GetProfile(ctx, userURI)
+}
+
+func (o clientOperations) GetHistoryStats(ctx context.Context, userURI wavy.UserURI) (*wavy.GetHistroyStatsResponse, error) {
+	return o.c.UserService().HistroyService(userURI).GetStats(ctx)
+}
+
+func (o clientOperations) GetCurrentListen(ctx context.Context, userURI wavy.UserURI) (*wavy.GetCurrentResponse, error) {
+	return o.c.UserService().HistroyService(userURI).GetCurrent(ctx)
+}
+
+func (o clientOperations) GetRecentListens(ctx context.Context, userURI wavy.UserURI) (*wavy.GetRecentResponse, error) {
+	return o.c.UserService().HistroyService(userURI).GetRecent(ctx)
+}
@@ -0,0 +1,107 @@
+package wavytest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/OGKevin/go-wavy/wavy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_Handler(t *testing.T) {
+	uri := wavy.UserURI{Username: "OGKevin"}
+	item := wavy.CurrentPlayingItem{
+		Song:    wavy.Song{Source: "spotify", Name: "Never Gonna Give You Up"},
+		Artists: []wavy.Artists{{Source: "spotify", Name: "Rick Astley"}},
+	}
+
+	fake := NewClient()
+	fake.TotalListens = 42
+	fake.TotalUsers = 7
+	fake.Leaderboard = []wavy.LeaderboardEntry{{Count: 42, Username: "OGKevin", UserID: "1"}}
+	fake.Profiles[uri.String()] = &wavy.GetUserProfileResponse{URI: uri.String(), Username: "OGKevin", JoinTime: time.Date(2020, 11, 2, 0, 0, 0, 0, time.UTC)}
+	fake.Stats[uri.String()] = &wavy.GetHistroyStatsResponse{TotalListens: 42, TotalArtists: 3}
+	fake.Current[uri.String()] = &wavy.GetCurrentResponse{Item: item}
+	fake.Recent[uri.String()] = &wavy.GetRecentResponse{Items: []wavy.Item{{CurrentPlayingItem: item, PlayID: "1"}}}
+
+	server := httptest.NewServer(fake.Handler())
+	defer server.Close()
+
+	ctx := context.Background()
+	// Strict decoding checks that the fake serves bodies matching the api description.
+	c := wavy.NewClient(ctx, wavy.NopLogger(), "id", "secret",
+		wavy.WithBaseURL(server.URL), wavy.WithDefaultCallOptions(wavy.WithStrictDecoding()))
+
+	listens, err := c.MetricsService().GetTotalListens(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(42), listens.TotalListens)
+
+	users, err := c.MetricsService().GetTotalUsers(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), users.TotalUsers)
+
+	leaderboard, err := c.MetricsService().GetUserListensLeaderboard(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []wavy.LeaderboardEntry{{Rank: 1, Count: 42, Username: "OGKevin", UserID: "1"}}, leaderboard.Entries)
+
+	profile, err := c.UserService().GetProfile(ctx, uri)
+	require.NoError(t, err)
+	assert.Equal(t, fake.Profiles[uri.String()], profile)
+
+	history := c.UserService().HistroyService(uri)
+	stats, err := history.GetStats(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, fake.Stats[uri.String()], stats)
+
+	current, err := history.GetCurrent(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, fake.Current[uri.String()], current)
+
+	recent, err := history.GetRecent(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, fake.Recent[uri.String()], recent)
+
+	assert.Equal(t, 1, fake.Calls("GetRecent"))
+
+	_, err = c.UserService().GetProfile(ctx, wavy.UserURI{Username: "unknown"})
+	var apiErr *wavy.ApiError
+	require.True(t, errors.As(err, &apiErr), "got %v", err)
+	assert.Equal(t, http.StatusNotFound, apiErr.Status)
+}
+
+func TestNewHandler_errors(t *testing.T) {
+	fake := NewClient()
+	fake.Err = errors.New("boom")
+
+	server := httptest.NewServer(fake.Handler())
+	defer server.Close()
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		status int
+	}{
+		{name: "failing operation", method: http.MethodGet, path: "/metrics/total-users", status: http.StatusInternalServerError},
+		{name: "unknown path", method: http.MethodGet, path: "/metrics/unknown", status: http.StatusNotFound},
+		{name: "invalid user uri", method: http.MethodGet, path: "/users/OGKevin", status: http.StatusBadRequest},
+		{name: "wrong method", method: http.MethodPost, path: "/metrics/total-users", status: http.StatusMethodNotAllowed},
+		{name: "token", method: http.MethodPost, path: "/token", status: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(tt.method, server.URL+tt.path, nil)
+			require.NoError(t, err)
+
+			res, err := server.Client().Do(req)
+			require.NoError(t, err)
+			res.Body.Close()
+			assert.Equal(t, tt.status, res.StatusCode)
+		})
+	}
+}